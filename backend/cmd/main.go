@@ -1,540 +1,696 @@
-package main
-
-import (
-	"context"
-	"log"
-	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
-	"time"
-
-	"gotchu-backend/internal/config"
-	"gotchu-backend/internal/handlers"
-	"gotchu-backend/internal/middleware"
-	"gotchu-backend/pkg/auth"
-	"gotchu-backend/pkg/database"
-	"gotchu-backend/pkg/discord"
-	"gotchu-backend/pkg/discordbot"
-	"gotchu-backend/pkg/email"
-	"gotchu-backend/pkg/redis"
-	"gotchu-backend/pkg/storage"
-	"gotchu-backend/pkg/workers"
-
-	"github.com/gin-gonic/gin"
-)
-
-func main() {
-	// Load configuration
-	cfg := config.Load()
-
-	// Set Gin mode
-	gin.SetMode(cfg.GinMode)
-
-	// Initialize database
-	db, err := database.NewConnection(cfg.DatabaseURL)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-
-	// Run migrations by default (set MIGRATE=false to skip for faster development)
-	if os.Getenv("MIGRATE") != "false" {
-		log.Println("Running database migrations...")
-		if err := database.AutoMigrate(db); err != nil {
-			log.Fatalf("Failed to run migrations: %v", err)
-		}
-
-		if err := database.CreateTriggers(db); err != nil {
-			log.Printf("Warning: Failed to create triggers: %v", err)
-		}
-
-		if err := database.SeedData(db); err != nil {
-			log.Printf("Warning: Failed to seed data: %v", err)
-		}
-	} else {
-		log.Println("⚡ Skipping migrations for fast development startup")
-	}
-
-	// Initialize Redis - prefer Upstash if available, fallback to traditional Redis
-	var redisClient *redis.Client
-	
-	if cfg.UpstashRedisURL != "" && cfg.UpstashRedisToken != "" {
-		log.Println("🔗 Connecting to Upstash Redis...")
-		redisClient, err = redis.NewUpstashClient(cfg.UpstashRedisURL, cfg.UpstashRedisToken)
-		if err != nil {
-			log.Printf("Failed to connect to Upstash Redis: %v", err)
-			log.Println("📡 Falling back to traditional Redis...")
-		} else {
-			log.Println("✅ Connected to Upstash Redis successfully")
-		}
-	}
-	
-	// Fallback to traditional Redis if Upstash failed or not configured
-	if redisClient == nil {
-		if cfg.RedisHost != "" && cfg.RedisPort != "" {
-			log.Println("🔗 Connecting to traditional Redis...")
-			redisClient, err = redis.NewClient(
-				cfg.RedisHost,
-				cfg.RedisPort,
-				cfg.RedisPassword,
-				cfg.RedisUsername,
-				cfg.RedisDB,
-			)
-			if err != nil {
-				log.Fatalf("Failed to connect to Redis: %v", err)
-			}
-			log.Println("✅ Connected to traditional Redis successfully")
-		} else {
-			log.Fatalf("No Redis configuration found. Please configure either Upstash Redis (UPSTASH_REDIS_REST_URL, UPSTASH_REDIS_REST_TOKEN) or traditional Redis (REDIS_HOST, REDIS_PORT)")
-		}
-	}
-
-	// Initialize worker pool for background operations
-	workerPool := workers.NewWorkerPool(8, 1000) // 8 workers, 1000 job queue
-	workerPool.Start()
-	defer workerPool.Stop(5 * time.Second)
-
-	// Initialize auth service
-	authService := auth.NewService(cfg.JWTSecret, cfg.SessionExpiry)
-
-	// Initialize email service
-	var emailService *email.Service
-	if cfg.ResendAPIKey != "" {
-		emailService = email.NewService(cfg.ResendAPIKey, "Gotchu", cfg.EmailFrom)
-		log.Println("✉️ Email service initialized")
-	} else {
-		log.Println("⚠️ Warning: No Resend API key provided, email verification will be disabled")
-	}
-
-	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(authService, redisClient, db)
-	rateLimiter := middleware.NewRateLimiter(redisClient)
-	badgeMiddleware := middleware.NewBadgeMiddleware(db)
-
-	// Initialize Supabase storage
-	var supabaseStorage *storage.SupabaseStorage
-	if cfg.SupabaseURL != "" && cfg.SupabaseServiceRoleKey != "" {
-		supabaseStorage = storage.NewSupabaseStorage(cfg.SupabaseURL, cfg.SupabaseServiceRoleKey, cfg.SupabaseAnonKey)
-		log.Println("🗄️ Supabase storage initialized")
-	} else {
-		log.Println("⚠️ Warning: Supabase storage not configured")
-	}
-
-	// Initialize Discord service
-	var discordService *discord.Service
-	var discordHandler *handlers.DiscordHandler
-	if cfg.DiscordClientID != "" && cfg.DiscordClientSecret != "" {
-		discordService = discord.NewService(
-			cfg.DiscordClientID,
-			cfg.DiscordClientSecret,
-			cfg.DiscordRedirectURI,
-			cfg.DiscordBotToken,
-			cfg.DiscordGuildID,
-		)
-		discordHandler = handlers.NewDiscordHandler(db, redisClient, discordService)
-		log.Println("🤖 Discord integration initialized")
-	} else {
-		log.Println("⚠️ Warning: Discord integration not configured")
-	}
-
-	// Initialize Discord Bot service for real-time presence tracking
-	var discordBotService *discordbot.DiscordBotService
-	var discordBotHandler *handlers.DiscordBotHandler
-	if cfg.DiscordBotToken != "" && cfg.DiscordGuildID != "" {
-		discordBotService = discordbot.NewDiscordBotService(cfg.DiscordBotToken, cfg.DiscordGuildID, db)
-		discordBotHandler = handlers.NewDiscordBotHandler(discordBotService, discordService)
-		
-		// Start the bot service
-		go func() {
-			if err := discordBotService.Start(); err != nil {
-				log.Printf("❌ Failed to start Discord bot: %v", err)
-			}
-		}()
-		
-		log.Println("🤖 Discord Bot service initialized for presence tracking")
-	} else {
-		log.Println("⚠️ Warning: Discord Bot service not configured (missing bot token or guild ID)")
-	}
-
-	// Initialize OAuth configurations
-	handlers.InitOAuthConfig(
-		cfg.GoogleClientID,
-		cfg.GoogleClientSecret,
-		cfg.GoogleRedirectURI,
-		cfg.DiscordClientID,
-		cfg.DiscordClientSecret,
-		cfg.DiscordRedirectURI,
-	)
-	
-	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(db, authService, redisClient, authMiddleware, emailService, cfg.SiteURL, cfg)
-	dashboardHandler := handlers.NewDashboardHandler(db, redisClient, cfg, discordBotService, workerPool)
-	linkHandler := handlers.NewLinkHandler(db, redisClient)
-	templateHandler := handlers.NewTemplateHandler(db, redisClient, supabaseStorage)
-	badgesHandler := handlers.NewBadgesHandler(db)
-	paymentHandler := handlers.NewPaymentHandler(db, redisClient, cfg, workerPool)
-
-	// Setup router
-	router := setupRouter(cfg, authMiddleware, rateLimiter, badgeMiddleware, authHandler, dashboardHandler, linkHandler, templateHandler, badgesHandler, discordHandler, discordBotHandler, paymentHandler)
-
-	// Serve uploaded files
-	router.Static("/uploads", "./uploads")
-
-	// Payment success redirect (outside API group for direct access)
-	router.GET("/payment-success", paymentHandler.PaymentSuccess)
-
-	// Setup HTTP server
-	srv := &http.Server{
-		Addr:         ":" + cfg.Port,
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
-
-	// Start server in a goroutine
-	go func() {
-		log.Printf("🚀 Server starting on port %s", cfg.Port)
-		log.Printf("📍 Health check: http://localhost:%s/health", cfg.Port)
-		log.Printf("🔗 API Base: http://localhost:%s/api", cfg.Port)
-
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
-		}
-	}()
-
-	// Wait for interrupt signal to gracefully shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("🛑 Shutting down server...")
-
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Shutdown server
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown: %v", err)
-	}
-
-	// Stop Discord bot service
-	if discordBotService != nil {
-		log.Println("🤖 Stopping Discord Bot service...")
-		discordBotService.Stop()
-	}
-
-	// Close database connection
-	if err := database.Close(db); err != nil {
-		log.Printf("Failed to close database: %v", err)
-	}
-
-	// Close Redis connection
-	if err := redisClient.Close(); err != nil {
-		log.Printf("Failed to close Redis: %v", err)
-	}
-
-	log.Println("✅ Server exited")
-}
-
-func setupRouter(
-	cfg *config.Config,
-	authMiddleware *middleware.AuthMiddleware,
-	rateLimiter *middleware.RateLimiter,
-	badgeMiddleware *middleware.BadgeMiddleware,
-	authHandler *handlers.AuthHandler,
-	dashboardHandler *handlers.DashboardHandler,
-	linkHandler *handlers.LinkHandler,
-	templateHandler *handlers.TemplateHandler,
-	badgesHandler *handlers.BadgesHandler,
-	discordHandler *handlers.DiscordHandler,
-	discordBotHandler *handlers.DiscordBotHandler,
-	paymentHandler *handlers.PaymentHandler,
-) *gin.Engine {
-	router := gin.New()
-
-	// Recovery middleware
-	router.Use(gin.Recovery())
-
-	// Logging middleware temporarily disabled for debugging
-	// router.Use(middleware.LoggingMiddleware())
-
-	// CORS middleware
-	router.Use(middleware.SetupCORS(cfg.CORSOrigins))
-
-	// Validation middleware temporarily disabled for debugging
-	// router.Use(middleware.SQLInjectionProtection())
-
-	// Global rate limiting
-	router.Use(rateLimiter.GlobalRateLimit(cfg.RateLimitMax, cfg.RateLimitWindow))
-
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":    "ok",
-			"timestamp": time.Now().UTC(),
-			"version":   "1.0.0",
-			"service":   "gotchu-backend",
-		})
-	})
-
-	// API routes
-	api := router.Group("/api")
-	{
-		// Auth routes with rate limiting temporarily disabled
-		auth := api.Group("/auth")
-		// auth.Use(authMiddleware.RateLimitAuth(cfg.AuthRateLimitMax, cfg.RateLimitWindow))
-		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
-			auth.POST("/login/2fa", badgeMiddleware.CheckBadgesOnLogin(), authHandler.Login2FA)
-			auth.POST("/logout", authMiddleware.OptionalAuth(), authHandler.Logout)
-			auth.GET("/me", authMiddleware.RequireAuth(), authHandler.GetCurrentUser)
-			auth.POST("/refresh", authHandler.RefreshToken)
-			auth.GET("/check-username/:username", authHandler.CheckUsernameAvailability)
-			auth.GET("/check-username", authHandler.CheckUsernameAvailability)
-			// Email verification routes
-			auth.GET("/verify-email", authHandler.VerifyEmail)
-			auth.POST("/resend-verification", authHandler.ResendVerification)
-			
-			// OAuth routes
-			auth.GET("/oauth/:provider", authHandler.InitiateOAuth)
-			auth.GET("/oauth/:provider/callback", authHandler.HandleOAuthCallback)
-			auth.POST("/complete-oauth-setup", authMiddleware.RequireAuth(), authHandler.CompleteOAuthSetup)
-			
-			// 2FA routes (protected)
-			twofa := auth.Group("/2fa")
-			twofa.Use(authMiddleware.RequireAuth())
-			{
-				twofa.POST("/generate", authHandler.Generate2FA)
-				twofa.POST("/verify", authHandler.Verify2FA)
-				twofa.POST("/disable", authHandler.Disable2FA)
-			}
-
-			// Profile update routes (protected)
-			auth.POST("/update-username", authMiddleware.RequireAuth(), authHandler.UpdateUsername)
-			auth.POST("/update-display-name", authMiddleware.RequireAuth(), authHandler.UpdateDisplayName)
-			auth.POST("/update-alias", authMiddleware.RequireAuth(), authHandler.UpdateAlias)
-			auth.POST("/change-password", authMiddleware.RequireAuth(), authHandler.ChangePassword)
-		}
-
-		// Dashboard routes (protected)
-		dashboard := api.Group("/dashboard")
-		dashboard.Use(authMiddleware.RequireAuth())
-		{
-			dashboard.GET("", dashboardHandler.GetDashboard)
-			dashboard.GET("/analytics", dashboardHandler.GetAnalytics)
-			dashboard.POST("/settings", dashboardHandler.SaveSettings)
-		}
-
-		// Customization routes (protected)
-		customization := api.Group("/customization")
-		customization.Use(authMiddleware.RequireAuth())
-		{
-			customization.GET("/settings", dashboardHandler.GetCustomizationSettings)
-			customization.POST("/settings", dashboardHandler.SaveCustomizationSettings)
-		}
-
-		// Audio routes (protected)
-		audio := api.Group("/audio")
-		audio.Use(authMiddleware.RequireAuth())
-		{
-			audio.GET("/list", dashboardHandler.ListUserAudioFiles)
-		}
-
-		// Asset management routes (protected)
-		assets := api.Group("/assets")
-		assets.Use(authMiddleware.RequireAuth())
-		{
-			assets.DELETE("/delete", dashboardHandler.DeleteUserAsset)
-		}
-
-		// Upload routes (protected)
-		upload := api.Group("/upload")
-		upload.Use(authMiddleware.RequireAuth())
-		{
-			upload.POST("/asset", dashboardHandler.UploadAsset)
-		}
-
-		// User routes
-		users := api.Group("/users")
-		users.Use(authMiddleware.OptionalAuth())
-		{
-			users.GET("/:username", dashboardHandler.GetUserProfile)
-			users.GET("/:username/links", linkHandler.GetPublicUserLinks)
-			users.GET("/:username/badges", badgesHandler.GetUserBadges)
-			users.GET("/:username/badges/showcased", badgesHandler.GetShowcasedBadges)
-		}
-
-		// Link routes
-		links := api.Group("/links")
-		{
-			// Public routes for link clicks (no auth required)
-			links.POST("/:id/click", linkHandler.TrackClick)
-			
-			// Protected routes (authentication required)
-			linksProtected := links.Group("")
-			linksProtected.Use(authMiddleware.RequireAuth())
-			{
-				linksProtected.GET("", linkHandler.GetLinks)
-				linksProtected.POST("", badgeMiddleware.CheckBadgesAfterAction(), linkHandler.CreateLink)
-				linksProtected.GET("/:id", linkHandler.GetLink)
-				linksProtected.PUT("/:id", linkHandler.UpdateLink)
-				linksProtected.DELETE("/:id", linkHandler.DeleteLink)
-				linksProtected.PUT("/reorder", linkHandler.ReorderLinks)
-			}
-		}
-
-		// Template routes
-		templates := api.Group("/templates")
-		{
-			// Public routes (no auth required)
-			templates.GET("", templateHandler.GetTemplates)
-			templates.GET("/categories", templateHandler.GetTemplateCategories)
-			
-			// Protected routes (authentication required)
-			templatesProtected := templates.Group("")
-			templatesProtected.Use(authMiddleware.RequireAuth())
-			{
-				templatesProtected.POST("/create", badgeMiddleware.CheckBadgesAfterAction(), templateHandler.CreateTemplate)
-				templatesProtected.GET("/my-templates", templateHandler.GetUserTemplates)
-				templatesProtected.GET("/liked", templateHandler.GetUserLikedTemplates)
-				templatesProtected.POST("/:id/apply", templateHandler.ApplyTemplate)
-				templatesProtected.POST("/:id/like", templateHandler.LikeTemplate)
-			}
-			
-			// ID-based routes must come last to avoid conflicts
-			templates.GET("/:id", templateHandler.GetTemplate)
-		}
-
-		// Badges routes
-		badges := api.Group("/badges")
-		{
-			// Public routes (no auth required)
-			badges.GET("", badgesHandler.GetAllBadges)
-			
-			// Protected routes (authentication required)
-			badgesProtected := badges.Group("")
-			badgesProtected.Use(authMiddleware.RequireAuth())
-			{
-				badgesProtected.PUT("/order", badgesHandler.UpdateBadgeOrder)
-				badgesProtected.POST("/check", badgesHandler.CheckBadges)
-				badgesProtected.POST("/claim/:badgeId", badgesHandler.ClaimBadge)
-				badgesProtected.POST("/award", badgesHandler.AwardBadgeManually) // Admin only - add admin middleware later
-			}
-		}
-
-		// Protected API routes
-		protected := api.Group("")
-		protected.Use(authMiddleware.RequireAuth())
-		protected.Use(rateLimiter.APIRateLimit(200, 5*time.Minute)) // Higher limit for authenticated users
-		{
-			// Add more protected routes here as needed
-			protected.GET("/profile", func(c *gin.Context) {
-				user, _ := middleware.GetCurrentUser(c)
-				c.JSON(http.StatusOK, gin.H{
-					"success": true,
-					"data":    gin.H{"user": user},
-				})
-			})
-		}
-
-		// Admin routes
-		admin := api.Group("/admin")
-		admin.Use(authMiddleware.RequireAuth())
-		admin.Use(authMiddleware.RequireAdmin())
-		{
-			admin.GET("/stats", func(c *gin.Context) {
-				// TODO: Implement admin stats
-				c.JSON(http.StatusOK, gin.H{
-					"success": true,
-					"message": "Admin stats endpoint",
-				})
-			})
-		}
-
-		// Discord routes
-		if discordHandler != nil {
-			discord := api.Group("/discord")
-			{
-				// Public callback route (no auth required)
-				discord.GET("/callback", discordHandler.DiscordCallback)
-				
-				// Protected Discord routes
-				discordProtected := discord.Group("")
-				discordProtected.Use(authMiddleware.RequireAuth())
-				{
-					discordProtected.POST("/auth", discordHandler.InitiateDiscordAuth)
-					discordProtected.GET("/status", discordHandler.GetDiscordStatus)
-					discordProtected.POST("/disconnect", discordHandler.DisconnectDiscord)
-					discordProtected.POST("/refresh", discordHandler.RefreshDiscordData)
-				}
-			}
-		}
-
-		// Discord Bot routes
-		if discordBotHandler != nil {
-			discordBot := api.Group("/discord-bot")
-			{
-				// Public presence viewing endpoints (no auth required)
-				discordBot.GET("/presence/:userID", discordBotHandler.GetUserPresence)
-				discordBot.GET("/badges/:userID", discordBotHandler.GetDiscordBadges)
-				discordBot.GET("/user/:userID", discordBotHandler.GetDiscordUser)
-				
-				// Protected endpoints (authentication required)
-				discordBotProtected := discordBot.Group("")
-				discordBotProtected.Use(authMiddleware.RequireAuth())
-				{
-					discordBotProtected.GET("/presences", discordBotHandler.GetAllPresences)
-					discordBotProtected.GET("/status", discordBotHandler.GetBotStatus)
-					discordBotProtected.POST("/start", discordBotHandler.StartBot)
-					discordBotProtected.POST("/stop", discordBotHandler.StopBot)
-				}
-			}
-		}
-
-		// Payment routes
-		payments := api.Group("/payments")
-		{
-			// Public routes (no auth required)
-			payments.GET("/plans", paymentHandler.GetPricingPlans)
-			payments.GET("/currencies", paymentHandler.GetAvailableCurrencies)
-			payments.POST("/webhook", paymentHandler.ProcessWebhook) // OxaPay webhook
-			
-			// Protected routes (authentication required)
-			paymentsProtected := payments.Group("")
-			paymentsProtected.Use(authMiddleware.RequireAuth())
-			{
-				paymentsProtected.POST("/create", paymentHandler.CreatePayment)
-				paymentsProtected.GET("/:id/status", paymentHandler.GetPaymentStatus)
-				paymentsProtected.GET("/history", paymentHandler.GetUserPayments)
-				paymentsProtected.GET("/subscription", paymentHandler.GetCurrentSubscription)
-			}
-		}
-
-		// Premium routes
-		premium := api.Group("/premium")
-		premium.Use(authMiddleware.RequireAuth())
-		premium.Use(authMiddleware.RequirePremium())
-		{
-			premium.GET("/features", func(c *gin.Context) {
-				// TODO: Implement premium features
-				c.JSON(http.StatusOK, gin.H{
-					"success": true,
-					"message": "Premium features endpoint",
-				})
-			})
-		}
-	}
-
-	// 404 handler
-	router.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Route not found",
-			"code":  "NOT_FOUND",
-			"path":  c.Request.URL.Path,
-		})
-	})
-
-	return router
-}
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gotchu-backend/internal/config"
+	"gotchu-backend/internal/handlers"
+	"gotchu-backend/internal/middleware"
+	"gotchu-backend/pkg/auth"
+	"gotchu-backend/pkg/database"
+	"gotchu-backend/pkg/discord"
+	"gotchu-backend/pkg/analytics"
+	"gotchu-backend/pkg/discordbot"
+	"gotchu-backend/pkg/email"
+	"gotchu-backend/pkg/ingest"
+	"gotchu-backend/pkg/ipprivacy"
+	"gotchu-backend/pkg/live"
+	"gotchu-backend/pkg/linkhealth"
+	"gotchu-backend/pkg/linkschedule"
+	"gotchu-backend/pkg/redis"
+	"gotchu-backend/pkg/rollup"
+	"gotchu-backend/pkg/storage"
+	"gotchu-backend/pkg/urlpolicy"
+	"gotchu-backend/pkg/workers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	// Load configuration
+	cfg := config.Load()
+
+	// Set Gin mode
+	gin.SetMode(cfg.GinMode)
+
+	// Initialize database
+	db, err := database.NewConnection(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Run migrations by default (set MIGRATE=false to skip for faster development)
+	if os.Getenv("MIGRATE") != "false" {
+		log.Println("Running database migrations...")
+		if err := database.AutoMigrate(db); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+
+		if err := database.CreateTriggers(db); err != nil {
+			log.Printf("Warning: Failed to create triggers: %v", err)
+		}
+
+		if err := database.SeedData(db); err != nil {
+			log.Printf("Warning: Failed to seed data: %v", err)
+		}
+	} else {
+		log.Println("⚡ Skipping migrations for fast development startup")
+	}
+
+	// Initialize Redis - prefer Upstash if available, fallback to traditional Redis
+	var redisClient *redis.Client
+	
+	if cfg.UpstashRedisURL != "" && cfg.UpstashRedisToken != "" {
+		log.Println("🔗 Connecting to Upstash Redis...")
+		redisClient, err = redis.NewUpstashClient(cfg.UpstashRedisURL, cfg.UpstashRedisToken)
+		if err != nil {
+			log.Printf("Failed to connect to Upstash Redis: %v", err)
+			log.Println("📡 Falling back to traditional Redis...")
+		} else {
+			log.Println("✅ Connected to Upstash Redis successfully")
+		}
+	}
+	
+	// Fallback to traditional Redis if Upstash failed or not configured
+	if redisClient == nil {
+		if cfg.RedisHost != "" && cfg.RedisPort != "" {
+			log.Println("🔗 Connecting to traditional Redis...")
+			redisClient, err = redis.NewClient(
+				cfg.RedisHost,
+				cfg.RedisPort,
+				cfg.RedisPassword,
+				cfg.RedisUsername,
+				cfg.RedisDB,
+			)
+			if err != nil {
+				log.Fatalf("Failed to connect to Redis: %v", err)
+			}
+			log.Println("✅ Connected to traditional Redis successfully")
+		} else {
+			log.Fatalf("No Redis configuration found. Please configure either Upstash Redis (UPSTASH_REDIS_REST_URL, UPSTASH_REDIS_REST_TOKEN) or traditional Redis (REDIS_HOST, REDIS_PORT)")
+		}
+	}
+
+	// Initialize worker pool for background operations
+	workerPool := workers.NewWorkerPool(8, 1000) // 8 workers, 1000 job queue
+	workerPool.Start()
+	defer workerPool.Stop(5 * time.Second)
+
+	// Initialize auth service
+	authService := auth.NewService(cfg.JWTSecret, cfg.SessionExpiry)
+
+	// Initialize email service
+	var emailService *email.Service
+	if cfg.ResendAPIKey != "" {
+		emailService = email.NewService(cfg.ResendAPIKey, "Gotchu", cfg.EmailFrom)
+		log.Println("✉️ Email service initialized")
+	} else {
+		log.Println("⚠️ Warning: No Resend API key provided, email verification will be disabled")
+	}
+
+	// Initialize middleware
+	authMiddleware := middleware.NewAuthMiddleware(authService, redisClient, db)
+	rateLimiter := middleware.NewRateLimiter(redisClient)
+	badgeMiddleware := middleware.NewBadgeMiddleware(db)
+
+	// Initialize Supabase storage
+	var supabaseStorage *storage.SupabaseStorage
+	if cfg.SupabaseURL != "" && cfg.SupabaseServiceRoleKey != "" {
+		supabaseStorage = storage.NewSupabaseStorage(cfg.SupabaseURL, cfg.SupabaseServiceRoleKey, cfg.SupabaseAnonKey)
+		log.Println("🗄️ Supabase storage initialized")
+	} else {
+		log.Println("⚠️ Warning: Supabase storage not configured")
+	}
+
+	// Initialize Discord service
+	var discordService *discord.Service
+	var discordHandler *handlers.DiscordHandler
+	if cfg.DiscordClientID != "" && cfg.DiscordClientSecret != "" {
+		discordService = discord.NewService(
+			cfg.DiscordClientID,
+			cfg.DiscordClientSecret,
+			cfg.DiscordRedirectURI,
+			cfg.DiscordBotToken,
+			cfg.DiscordGuildID,
+		)
+		discordHandler = handlers.NewDiscordHandler(db, redisClient, discordService)
+		log.Println("🤖 Discord integration initialized")
+	} else {
+		log.Println("⚠️ Warning: Discord integration not configured")
+	}
+
+	// Initialize Discord Bot service for real-time presence tracking
+	var discordBotService *discordbot.DiscordBotService
+	var discordBotHandler *handlers.DiscordBotHandler
+	if cfg.DiscordBotToken != "" && cfg.DiscordGuildID != "" {
+		discordBotService = discordbot.NewDiscordBotService(cfg.DiscordBotToken, cfg.DiscordGuildID, db)
+		discordBotHandler = handlers.NewDiscordBotHandler(discordBotService, discordService)
+		
+		// Start the bot service
+		go func() {
+			if err := discordBotService.Start(); err != nil {
+				log.Printf("❌ Failed to start Discord bot: %v", err)
+			}
+		}()
+		
+		log.Println("🤖 Discord Bot service initialized for presence tracking")
+	} else {
+		log.Println("⚠️ Warning: Discord Bot service not configured (missing bot token or guild ID)")
+	}
+
+	// Initialize OAuth configurations
+	handlers.InitOAuthConfig(
+		cfg.GoogleClientID,
+		cfg.GoogleClientSecret,
+		cfg.GoogleRedirectURI,
+		cfg.DiscordClientID,
+		cfg.DiscordClientSecret,
+		cfg.DiscordRedirectURI,
+	)
+	
+	// Initialize handlers
+	authHandler := handlers.NewAuthHandler(db, authService, redisClient, authMiddleware, emailService, cfg.SiteURL, cfg)
+	profileAccess := handlers.NewProfileAccess(db, cfg.JWTSecret)
+
+	// Resolve visitor locations from the local GeoIP database
+	geoConfig := analytics.DefaultGeoConfig()
+	geoConfig.DatabasePath = cfg.GeoIPDatabasePath
+	geoConfig.CacheSize = cfg.GeoIPCacheSize
+	geoConfig.APIFallback = cfg.GeoIPAPIFallback
+	geoService := analytics.NewGeoLocationService(geoConfig)
+
+	// Store visitor IPs raw or as a prefix and daily salted hash, and anonymize
+	// the raw addresses already stored once private mode is turned on
+	ipAnonymizer := ipprivacy.NewAnonymizer(cfg.IPStorageMode, redisClient)
+	ipMigrator := ipprivacy.NewMigrator(db, ipAnonymizer, ipprivacy.DefaultMigrationConfig())
+	ipMigrator.Start()
+
+	// Push views and clicks to live dashboards on every instance
+	liveHub := live.NewHub(redisClient, live.DefaultConfig())
+	liveHub.Start()
+
+	// Buffer profile views and link clicks and write them in batches
+	ingestConfig := ingest.DefaultConfig()
+	ingestConfig.QueueSize = cfg.AnalyticsQueueSize
+	ingestConfig.BatchSize = cfg.AnalyticsBatchSize
+	ingestConfig.FlushInterval = cfg.AnalyticsFlushInterval
+	ingestPipeline := ingest.NewPipeline(db, redisClient, geoService, liveHub, ipAnonymizer, ingestConfig)
+	ingestPipeline.Start()
+
+	// Keep the daily analytics rollups current and purge old raw events
+	rollupConfig := rollup.DefaultConfig()
+	rollupConfig.Interval = cfg.AnalyticsRollupInterval
+	rollupConfig.Retention = time.Duration(cfg.AnalyticsRawRetentionDays) * 24 * time.Hour
+	analyticsRoller := rollup.NewRoller(db, workerPool, rollupConfig)
+	analyticsRoller.Start()
+
+	// Screen link targets against the scheme allowlist, lookalike domains and the blocklist
+	urlPolicy := urlpolicy.New(nil)
+	urlPolicyRescanner := urlpolicy.NewRescanner(urlPolicy, db, redisClient)
+	blocklistAdded := 0
+	if cfg.URLBlocklistFile != "" {
+		added, err := urlpolicy.ImportFile(db, cfg.URLBlocklistFile)
+		if err != nil {
+			log.Printf("Warning: Failed to load URL blocklist: %v", err)
+		} else if added > 0 {
+			log.Printf("🛡️ Loaded %d new blocked domains from %s", added, cfg.URLBlocklistFile)
+			blocklistAdded = added
+		}
+	}
+	// The rescan checks links against the policy's blocklist, so it only
+	// runs once the new domains have been loaded into it
+	if err := urlPolicy.Reload(db); err != nil {
+		log.Printf("Warning: %v", err)
+	} else if blocklistAdded > 0 {
+		workerPool.Submit(workers.Job{ID: "url-policy-rescan", Handler: urlPolicyRescanner.Run, Timeout: time.Hour})
+	}
+
+	dashboardHandler := handlers.NewDashboardHandler(db, redisClient, cfg, discordBotService, workerPool, profileAccess, ingestPipeline, liveHub, ipAnonymizer, geoService, urlPolicy)
+	linkScheduler := linkschedule.NewScheduler(db, redisClient)
+	linkScheduler.Start()
+	linkHandler := handlers.NewLinkHandler(db, redisClient, profileAccess, workerPool, cfg, linkScheduler, supabaseStorage, urlPolicy, ingestPipeline, geoService)
+	urlPolicyHandler := handlers.NewURLPolicyHandler(db, urlPolicy, urlPolicyRescanner, workerPool)
+
+	// Periodically check link targets and notify owners of broken ones
+	healthConfig := linkhealth.DefaultConfig()
+	healthConfig.Interval = cfg.LinkHealthInterval
+	healthConfig.FailureThreshold = cfg.LinkHealthFailureThreshold
+	linkHealthChecker := linkhealth.NewChecker(db, workerPool, nil, linkhealth.NewOwnerNotifier(db, emailService, cfg.FrontendURL), healthConfig)
+	linkHealthChecker.Start()
+	templateHandler := handlers.NewTemplateHandler(db, redisClient, supabaseStorage)
+	badgesHandler := handlers.NewBadgesHandler(db, profileAccess)
+	paymentHandler := handlers.NewPaymentHandler(db, redisClient, cfg, workerPool)
+	visibilityHandler := handlers.NewVisibilityHandler(db, redisClient, authService, profileAccess, cfg)
+	qrHandler := handlers.NewQRHandler(db, redisClient, cfg, profileAccess)
+	discoverHandler := handlers.NewDiscoverHandler(db, redisClient)
+
+	// Setup router
+	router := setupRouter(cfg, authMiddleware, rateLimiter, badgeMiddleware, authHandler, dashboardHandler, linkHandler, templateHandler, badgesHandler, discordHandler, discordBotHandler, paymentHandler, visibilityHandler, qrHandler, discoverHandler, urlPolicyHandler)
+
+	// Serve uploaded files
+	router.Static("/uploads", "./uploads")
+
+	// Payment success redirect (outside API group for direct access)
+	router.GET("/payment-success", paymentHandler.PaymentSuccess)
+
+	// Setup HTTP server
+	srv := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      router,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+	// Live analytics streams never go idle, so end them when shutdown starts
+	srv.RegisterOnShutdown(liveHub.Stop)
+
+	// Start server in a goroutine
+	go func() {
+		log.Printf("🚀 Server starting on port %s", cfg.Port)
+		log.Printf("📍 Health check: http://localhost:%s/health", cfg.Port)
+		log.Printf("🔗 API Base: http://localhost:%s/api", cfg.Port)
+
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("🛑 Shutting down server...")
+
+	// Graceful shutdown with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Shutdown server
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+
+	// Stop Discord bot service
+	if discordBotService != nil {
+		log.Println("🤖 Stopping Discord Bot service...")
+		discordBotService.Stop()
+	}
+
+	// Stop link schedule watcher, health checker, analytics rollups and IP anonymization
+	linkScheduler.Stop()
+	linkHealthChecker.Stop()
+	analyticsRoller.Stop()
+	ipMigrator.Stop()
+
+	// Write out buffered analytics before the database closes
+	ingestPipeline.Stop(10 * time.Second)
+	geoService.Close()
+
+	// Close database connection
+	if err := database.Close(db); err != nil {
+		log.Printf("Failed to close database: %v", err)
+	}
+
+	// Close Redis connection
+	if err := redisClient.Close(); err != nil {
+		log.Printf("Failed to close Redis: %v", err)
+	}
+
+	log.Println("✅ Server exited")
+}
+
+func setupRouter(
+	cfg *config.Config,
+	authMiddleware *middleware.AuthMiddleware,
+	rateLimiter *middleware.RateLimiter,
+	badgeMiddleware *middleware.BadgeMiddleware,
+	authHandler *handlers.AuthHandler,
+	dashboardHandler *handlers.DashboardHandler,
+	linkHandler *handlers.LinkHandler,
+	templateHandler *handlers.TemplateHandler,
+	badgesHandler *handlers.BadgesHandler,
+	discordHandler *handlers.DiscordHandler,
+	discordBotHandler *handlers.DiscordBotHandler,
+	paymentHandler *handlers.PaymentHandler,
+	visibilityHandler *handlers.VisibilityHandler,
+	qrHandler *handlers.QRHandler,
+	discoverHandler *handlers.DiscoverHandler,
+	urlPolicyHandler *handlers.URLPolicyHandler,
+) *gin.Engine {
+	router := gin.New()
+
+	// Recovery middleware
+	router.Use(gin.Recovery())
+
+	// Logging middleware temporarily disabled for debugging
+	// router.Use(middleware.LoggingMiddleware())
+
+	// CORS middleware
+	router.Use(middleware.SetupCORS(cfg.CORSOrigins))
+
+	// Validation middleware temporarily disabled for debugging
+	// router.Use(middleware.SQLInjectionProtection())
+
+	// Global rate limiting
+	router.Use(rateLimiter.GlobalRateLimit(cfg.RateLimitMax, cfg.RateLimitWindow))
+
+	// Health check endpoint
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":    "ok",
+			"timestamp": time.Now().UTC(),
+			"version":   "1.0.0",
+			"service":   "gotchu-backend",
+		})
+	})
+
+	// API routes
+	api := router.Group("/api")
+	{
+		// Auth routes with rate limiting temporarily disabled
+		auth := api.Group("/auth")
+		// auth.Use(authMiddleware.RateLimitAuth(cfg.AuthRateLimitMax, cfg.RateLimitWindow))
+		{
+			auth.POST("/register", authHandler.Register)
+			auth.POST("/login", authHandler.Login)
+			auth.POST("/login/2fa", badgeMiddleware.CheckBadgesOnLogin(), authHandler.Login2FA)
+			auth.POST("/logout", authMiddleware.OptionalAuth(), authHandler.Logout)
+			auth.GET("/me", authMiddleware.RequireAuth(), authHandler.GetCurrentUser)
+			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.GET("/check-username/:username", authHandler.CheckUsernameAvailability)
+			auth.GET("/check-username", authHandler.CheckUsernameAvailability)
+			// Email verification routes
+			auth.GET("/verify-email", authHandler.VerifyEmail)
+			auth.POST("/resend-verification", authHandler.ResendVerification)
+			
+			// OAuth routes
+			auth.GET("/oauth/:provider", authHandler.InitiateOAuth)
+			auth.GET("/oauth/:provider/callback", authHandler.HandleOAuthCallback)
+			auth.POST("/complete-oauth-setup", authMiddleware.RequireAuth(), authHandler.CompleteOAuthSetup)
+			
+			// 2FA routes (protected)
+			twofa := auth.Group("/2fa")
+			twofa.Use(authMiddleware.RequireAuth())
+			{
+				twofa.POST("/generate", authHandler.Generate2FA)
+				twofa.POST("/verify", authHandler.Verify2FA)
+				twofa.POST("/disable", authHandler.Disable2FA)
+			}
+
+			// Profile update routes (protected)
+			auth.POST("/update-username", authMiddleware.RequireAuth(), authHandler.UpdateUsername)
+			auth.POST("/update-display-name", authMiddleware.RequireAuth(), authHandler.UpdateDisplayName)
+			auth.POST("/update-alias", authMiddleware.RequireAuth(), authHandler.UpdateAlias)
+			auth.POST("/change-password", authMiddleware.RequireAuth(), authHandler.ChangePassword)
+		}
+
+		// Dashboard routes (protected)
+		dashboard := api.Group("/dashboard")
+		dashboard.Use(authMiddleware.RequireAuth())
+		{
+			dashboard.GET("", dashboardHandler.GetDashboard)
+			dashboard.GET("/analytics", dashboardHandler.GetAnalytics)
+			dashboard.GET("/analytics/links", dashboardHandler.GetLinkAnalytics)
+			dashboard.GET("/analytics/live", dashboardHandler.StreamLiveAnalytics)
+			dashboard.GET("/analytics/export", dashboardHandler.ExportAnalytics)
+			dashboard.GET("/analytics/exports/:id", dashboardHandler.GetAnalyticsExport)
+			dashboard.POST("/settings", dashboardHandler.SaveSettings)
+		}
+
+		// Customization routes (protected)
+		customization := api.Group("/customization")
+		customization.Use(authMiddleware.RequireAuth())
+		{
+			customization.GET("/settings", dashboardHandler.GetCustomizationSettings)
+			customization.POST("/settings", dashboardHandler.SaveCustomizationSettings)
+		}
+
+		// Profile portability and visibility routes (protected)
+		profile := api.Group("/profile")
+		profile.Use(authMiddleware.RequireAuth())
+		{
+			profile.GET("/export", dashboardHandler.ExportProfile)
+			profile.POST("/import/preview", dashboardHandler.PreviewProfileImport)
+			profile.POST("/import", dashboardHandler.ImportProfile)
+
+			// Visibility and share links
+			profile.GET("/visibility", visibilityHandler.GetVisibility)
+			profile.PUT("/visibility", visibilityHandler.UpdateVisibility)
+			profile.GET("/share-links", visibilityHandler.ListShareLinks)
+			profile.POST("/share-links", visibilityHandler.CreateShareLink)
+			profile.DELETE("/share-links/:id", visibilityHandler.RevokeShareLink)
+			profile.PUT("/discovery", discoverHandler.UpdateDiscoveryOptOut)
+		}
+
+		// Audio routes (protected)
+		audio := api.Group("/audio")
+		audio.Use(authMiddleware.RequireAuth())
+		{
+			audio.GET("/list", dashboardHandler.ListUserAudioFiles)
+		}
+
+		// Asset management routes (protected)
+		assets := api.Group("/assets")
+		assets.Use(authMiddleware.RequireAuth())
+		{
+			assets.DELETE("/delete", dashboardHandler.DeleteUserAsset)
+		}
+
+		// Upload routes (protected)
+		upload := api.Group("/upload")
+		upload.Use(authMiddleware.RequireAuth())
+		{
+			upload.POST("/asset", dashboardHandler.UploadAsset)
+		}
+
+		// User routes
+		users := api.Group("/users")
+		users.Use(authMiddleware.OptionalAuth())
+		{
+			users.GET("/:username", dashboardHandler.GetUserProfile)
+			users.GET("/:username/links", linkHandler.GetPublicUserLinks)
+			users.GET("/:username/badges", badgesHandler.GetUserBadges)
+			users.GET("/:username/badges/showcased", badgesHandler.GetShowcasedBadges)
+			users.POST("/:username/unlock", visibilityHandler.UnlockProfile)
+			users.GET("/:username/qr", qrHandler.GetProfileQR)
+		}
+
+		// oEmbed provider for profile URLs
+		api.GET("/oembed", dashboardHandler.OEmbed)
+
+		// Public profile directory
+		api.GET("/discover", discoverHandler.DiscoverProfiles)
+
+		// Link routes
+		links := api.Group("/links")
+		{
+			// Public routes for link clicks (no auth required)
+			links.POST("/:id/click", linkHandler.TrackClick)
+			
+			// Protected routes (authentication required)
+			linksProtected := links.Group("")
+			linksProtected.Use(authMiddleware.RequireAuth())
+			{
+				linksProtected.GET("", linkHandler.GetLinks)
+				linksProtected.POST("", badgeMiddleware.CheckBadgesAfterAction(), linkHandler.CreateLink)
+				linksProtected.GET("/:id", linkHandler.GetLink)
+				linksProtected.PUT("/:id", linkHandler.UpdateLink)
+				linksProtected.DELETE("/:id", linkHandler.DeleteLink)
+				linksProtected.PUT("/reorder", linkHandler.ReorderLinks)
+				linksProtected.POST("/import", linkHandler.ImportLinks)
+				linksProtected.GET("/export", linkHandler.ExportLinks)
+
+				// A/B testing
+				linksProtected.GET("/:id/variants", linkHandler.GetLinkVariants)
+				linksProtected.POST("/:id/variants", linkHandler.CreateLinkVariant)
+				linksProtected.PUT("/:id/variants/:variantId", linkHandler.UpdateLinkVariant)
+				linksProtected.DELETE("/:id/variants/:variantId", linkHandler.DeleteLinkVariant)
+				linksProtected.POST("/:id/variants/:variantId/promote", linkHandler.PromoteLinkVariant)
+			}
+		}
+
+		// Template routes
+		templates := api.Group("/templates")
+		{
+			// Public routes (no auth required)
+			templates.GET("", templateHandler.GetTemplates)
+			templates.GET("/categories", templateHandler.GetTemplateCategories)
+			
+			// Protected routes (authentication required)
+			templatesProtected := templates.Group("")
+			templatesProtected.Use(authMiddleware.RequireAuth())
+			{
+				templatesProtected.POST("/create", badgeMiddleware.CheckBadgesAfterAction(), templateHandler.CreateTemplate)
+				templatesProtected.GET("/my-templates", templateHandler.GetUserTemplates)
+				templatesProtected.GET("/liked", templateHandler.GetUserLikedTemplates)
+				templatesProtected.POST("/:id/apply", templateHandler.ApplyTemplate)
+				templatesProtected.POST("/:id/like", templateHandler.LikeTemplate)
+			}
+			
+			// ID-based routes must come last to avoid conflicts
+			templates.GET("/:id", templateHandler.GetTemplate)
+		}
+
+		// Badges routes
+		badges := api.Group("/badges")
+		{
+			// Public routes (no auth required)
+			badges.GET("", badgesHandler.GetAllBadges)
+			
+			// Protected routes (authentication required)
+			badgesProtected := badges.Group("")
+			badgesProtected.Use(authMiddleware.RequireAuth())
+			{
+				badgesProtected.PUT("/order", badgesHandler.UpdateBadgeOrder)
+				badgesProtected.POST("/check", badgesHandler.CheckBadges)
+				badgesProtected.POST("/claim/:badgeId", badgesHandler.ClaimBadge)
+				badgesProtected.POST("/award", badgesHandler.AwardBadgeManually) // Admin only - add admin middleware later
+			}
+		}
+
+		// Protected API routes
+		protected := api.Group("")
+		protected.Use(authMiddleware.RequireAuth())
+		protected.Use(rateLimiter.APIRateLimit(200, 5*time.Minute)) // Higher limit for authenticated users
+		{
+			// Add more protected routes here as needed
+			protected.GET("/profile", func(c *gin.Context) {
+				user, _ := middleware.GetCurrentUser(c)
+				c.JSON(http.StatusOK, gin.H{
+					"success": true,
+					"data":    gin.H{"user": user},
+				})
+			})
+		}
+
+		// Admin routes
+		admin := api.Group("/admin")
+		admin.Use(authMiddleware.RequireAuth())
+		admin.Use(authMiddleware.RequireAdmin())
+		{
+			admin.GET("/stats", func(c *gin.Context) {
+				// TODO: Implement admin stats
+				c.JSON(http.StatusOK, gin.H{
+					"success": true,
+					"message": "Admin stats endpoint",
+				})
+			})
+
+			// URL policy
+			admin.GET("/blocked-domains", urlPolicyHandler.ListBlockedDomains)
+			admin.POST("/blocked-domains", urlPolicyHandler.AddBlockedDomain)
+			admin.POST("/blocked-domains/import", urlPolicyHandler.ImportBlockedDomains)
+			admin.DELETE("/blocked-domains/:id", urlPolicyHandler.RemoveBlockedDomain)
+			admin.POST("/blocked-domains/rescan", urlPolicyHandler.RescanLinks)
+			admin.POST("/url-policy/check", urlPolicyHandler.CheckURL)
+			admin.PUT("/links/:id/sensitivity", linkHandler.ForceLinkSensitivity)
+
+			// Analytics ingestion
+			admin.GET("/analytics/ingest", dashboardHandler.GetIngestStats)
+		}
+
+		// Discord routes
+		if discordHandler != nil {
+			discord := api.Group("/discord")
+			{
+				// Public callback route (no auth required)
+				discord.GET("/callback", discordHandler.DiscordCallback)
+				
+				// Protected Discord routes
+				discordProtected := discord.Group("")
+				discordProtected.Use(authMiddleware.RequireAuth())
+				{
+					discordProtected.POST("/auth", discordHandler.InitiateDiscordAuth)
+					discordProtected.GET("/status", discordHandler.GetDiscordStatus)
+					discordProtected.POST("/disconnect", discordHandler.DisconnectDiscord)
+					discordProtected.POST("/refresh", discordHandler.RefreshDiscordData)
+				}
+			}
+		}
+
+		// Discord Bot routes
+		if discordBotHandler != nil {
+			discordBot := api.Group("/discord-bot")
+			{
+				// Public presence viewing endpoints (no auth required)
+				discordBot.GET("/presence/:userID", discordBotHandler.GetUserPresence)
+				discordBot.GET("/badges/:userID", discordBotHandler.GetDiscordBadges)
+				discordBot.GET("/user/:userID", discordBotHandler.GetDiscordUser)
+				
+				// Protected endpoints (authentication required)
+				discordBotProtected := discordBot.Group("")
+				discordBotProtected.Use(authMiddleware.RequireAuth())
+				{
+					discordBotProtected.GET("/presences", discordBotHandler.GetAllPresences)
+					discordBotProtected.GET("/status", discordBotHandler.GetBotStatus)
+					discordBotProtected.POST("/start", discordBotHandler.StartBot)
+					discordBotProtected.POST("/stop", discordBotHandler.StopBot)
+				}
+			}
+		}
+
+		// Payment routes
+		payments := api.Group("/payments")
+		{
+			// Public routes (no auth required)
+			payments.GET("/plans", paymentHandler.GetPricingPlans)
+			payments.GET("/currencies", paymentHandler.GetAvailableCurrencies)
+			payments.POST("/webhook", paymentHandler.ProcessWebhook) // OxaPay webhook
+			
+			// Protected routes (authentication required)
+			paymentsProtected := payments.Group("")
+			paymentsProtected.Use(authMiddleware.RequireAuth())
+			{
+				paymentsProtected.POST("/create", paymentHandler.CreatePayment)
+				paymentsProtected.GET("/:id/status", paymentHandler.GetPaymentStatus)
+				paymentsProtected.GET("/history", paymentHandler.GetUserPayments)
+				paymentsProtected.GET("/subscription", paymentHandler.GetCurrentSubscription)
+			}
+		}
+
+		// Premium routes
+		premium := api.Group("/premium")
+		premium.Use(authMiddleware.RequireAuth())
+		premium.Use(authMiddleware.RequirePremium())
+		{
+			premium.GET("/features", func(c *gin.Context) {
+				// TODO: Implement premium features
+				c.JSON(http.StatusOK, gin.H{
+					"success": true,
+					"message": "Premium features endpoint",
+				})
+			})
+		}
+	}
+
+	// Short-link redirects (served outside the API so they work without JavaScript)
+	router.GET("/l/:code", authMiddleware.OptionalAuth(), linkHandler.RedirectShortLink)
+	// Sensitive links post their consent interstitial back here
+	router.POST("/l/:code", authMiddleware.OptionalAuth(), linkHandler.RedirectShortLink)
+
+	// Embeddable profile widget (served outside the API for iframes)
+	embed := router.Group("/embed")
+	embed.Use(authMiddleware.OptionalAuth())
+	{
+		embed.GET("/:username", dashboardHandler.ProfileWidget)
+		embed.GET("/:username/links/:id", dashboardHandler.WidgetLinkRedirect)
+		embed.POST("/:username/links/:id", dashboardHandler.WidgetLinkRedirect)
+	}
+
+	// 404 handler
+	router.NoRoute(func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Route not found",
+			"code":  "NOT_FOUND",
+			"path":  c.Request.URL.Path,
+		})
+	})
+
+	return router
+}
@@ -1,197 +1,307 @@
-package config
-
-import (
-	"log"
-	"os"
-	"strconv"
-	"time"
-
-	"github.com/joho/godotenv"
-)
-
-// Config holds all configuration for the application
-type Config struct {
-	// Server
-	Port    string
-	GinMode string
-
-	// Database
-	DatabaseURL string
-	DirectURL   string
-
-	// Redis - Traditional
-	RedisHost     string
-	RedisPort     string
-	RedisPassword string
-	RedisUsername string
-	RedisDB       int
-	
-	// Redis - Upstash (alternative to traditional Redis)
-	UpstashRedisURL   string
-	UpstashRedisToken string
-
-	// JWT
-	JWTSecret string
-
-	// CORS
-	CORSOrigins string
-
-	// Rate Limiting
-	RateLimitWindow    time.Duration
-	RateLimitMax       int
-	AuthRateLimitMax   int
-	SessionExpiry      time.Duration
-
-	// Supabase (optional)
-	SupabaseURL            string
-	SupabaseAnonKey        string
-	SupabaseServiceRoleKey string
-
-	// Discord (optional)
-	DiscordBotToken     string
-	DiscordClientID     string
-	DiscordClientSecret string
-	DiscordRedirectURI  string
-	DiscordGuildID      string
-
-	// Google OAuth (optional)
-	GoogleClientID     string
-	GoogleClientSecret string
-	GoogleRedirectURI  string
-
-	// Email (optional)
-	ResendAPIKey string
-	EmailFrom    string
-
-	// Cloudflare (optional)
-	CloudflareTurnstileSiteKey   string
-	CloudflareTurnstileSecretKey string
-
-	// Site
-	SiteURL string
-
-	// OxaPay Payment Gateway
-	OxaPayMerchantKey string
-	OxaPayAPIKey      string
-	
-	// URLs
-	BaseURL     string
-	FrontendURL string
-}
-
-// Load loads configuration from environment variables
-func Load() *Config {
-	// Load .env file if it exists
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
-	}
-
-	config := &Config{
-		// Server
-		Port:    getEnv("PORT", "8080"),
-		GinMode: getEnv("GIN_MODE", "debug"),
-
-		// Database
-		DatabaseURL: getEnvRequired("DATABASE_URL"),
-		DirectURL:   getEnv("DIRECT_URL", ""),
-
-		// Redis - Traditional (fallback if Upstash not available)
-		RedisHost:     getEnv("REDIS_HOST", ""),
-		RedisPort:     getEnv("REDIS_PORT", ""),
-		RedisPassword: getEnv("REDIS_PASSWORD", ""),
-		RedisUsername: getEnv("REDIS_USERNAME", "default"),
-		RedisDB:       getEnvAsInt("REDIS_DB", 0),
-		
-		// Redis - Upstash (preferred)
-		UpstashRedisURL:   getEnv("UPSTASH_REDIS_REST_URL", ""),
-		UpstashRedisToken: getEnv("UPSTASH_REDIS_REST_TOKEN", ""),
-
-		// JWT
-		JWTSecret: getEnvRequired("JWT_SECRET"),
-
-		// CORS
-		CORSOrigins: getEnv("CORS_ORIGINS", "http://localhost:3000,http://localhost:5173"),
-
-		// Rate Limiting
-		RateLimitWindow:  time.Duration(getEnvAsInt("RATE_LIMIT_WINDOW", 300)) * time.Second,
-		RateLimitMax:     getEnvAsInt("RATE_LIMIT_MAX", 100),
-		AuthRateLimitMax: getEnvAsInt("AUTH_RATE_LIMIT_MAX", 5),
-		SessionExpiry:    time.Duration(getEnvAsInt("SESSION_EXPIRY", 86400)) * time.Second,
-
-		// Supabase
-		SupabaseURL:            getEnv("NEXT_PUBLIC_SUPABASE_URL", ""),
-		SupabaseAnonKey:        getEnv("NEXT_PUBLIC_SUPABASE_ANON_KEY", ""),
-		SupabaseServiceRoleKey: getEnv("SUPABASE_SERVICE_ROLE_KEY", ""),
-
-		// Discord
-		DiscordBotToken:     getEnv("DISCORD_BOT_TOKEN", ""),
-		DiscordClientID:     getEnv("DISCORD_CLIENT_ID", ""),
-		DiscordClientSecret: getEnv("DISCORD_CLIENT_SECRET", ""),
-		DiscordRedirectURI:  getEnv("DISCORD_REDIRECT_URI", ""),
-		DiscordGuildID:      getEnv("DISCORD_GUILD_ID", ""),
-
-		// Google OAuth
-		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
-		GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
-		GoogleRedirectURI:  getEnv("GOOGLE_REDIRECT_URI", ""),
-
-		// Email
-		ResendAPIKey: getEnv("RESEND_API_KEY", ""),
-		EmailFrom:    getEnv("EMAIL_FROM", "noreply@gotchu.lol"),
-
-		// Cloudflare
-		CloudflareTurnstileSiteKey:   getEnv("NEXT_PUBLIC_CLOUDFLARE_TURNSTILE_SITE_KEY", ""),
-		CloudflareTurnstileSecretKey: getEnv("CLOUDFLARE_TURNSTILE_SECRET_KEY", ""),
-
-		// Site
-		SiteURL: getEnv("SITE_URL", "http://localhost:5173"),
-
-		// OxaPay Payment Gateway
-		OxaPayMerchantKey: getEnv("OXAPAY_MERCHANT_KEY", ""),
-		OxaPayAPIKey:      getEnv("OXAPAY_API_KEY", ""),
-		
-		// URLs
-		BaseURL:     getEnv("BASE_URL", "http://localhost:8080"),
-		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:5173"),
-	}
-
-	return config
-}
-
-// getEnv gets an environment variable with a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-// getEnvRequired gets a required environment variable
-func getEnvRequired(key string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		log.Fatalf("Required environment variable %s is not set", key)
-	}
-	return value
-}
-
-// getEnvAsInt gets an environment variable as integer with default
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
-		log.Printf("Invalid integer value for %s: %s, using default: %d", key, value, defaultValue)
-	}
-	return defaultValue
-}
-
-// IsDevelopment returns true if running in development mode
-func (c *Config) IsDevelopment() bool {
-	return c.GinMode == "debug"
-}
-
-// IsProduction returns true if running in production mode
-func (c *Config) IsProduction() bool {
-	return c.GinMode == "release"
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Config holds all configuration for the application
+type Config struct {
+	// Server
+	Port    string
+	GinMode string
+
+	// Database
+	DatabaseURL string
+	DirectURL   string
+
+	// Redis - Traditional
+	RedisHost     string
+	RedisPort     string
+	RedisPassword string
+	RedisUsername string
+	RedisDB       int
+	
+	// Redis - Upstash (alternative to traditional Redis)
+	UpstashRedisURL   string
+	UpstashRedisToken string
+
+	// JWT
+	JWTSecret string
+
+	// CORS
+	CORSOrigins string
+
+	// Rate Limiting
+	RateLimitWindow    time.Duration
+	RateLimitMax       int
+	AuthRateLimitMax   int
+	SessionExpiry      time.Duration
+
+	// Supabase (optional)
+	SupabaseURL            string
+	SupabaseAnonKey        string
+	SupabaseServiceRoleKey string
+
+	// Discord (optional)
+	DiscordBotToken     string
+	DiscordClientID     string
+	DiscordClientSecret string
+	DiscordRedirectURI  string
+	DiscordGuildID      string
+
+	// Google OAuth (optional)
+	GoogleClientID     string
+	GoogleClientSecret string
+	GoogleRedirectURI  string
+
+	// Email (optional)
+	ResendAPIKey string
+	EmailFrom    string
+
+	// Cloudflare (optional)
+	CloudflareTurnstileSiteKey   string
+	CloudflareTurnstileSecretKey string
+
+	// Site
+	SiteURL string
+
+	// OxaPay Payment Gateway
+	OxaPayMerchantKey string
+	OxaPayAPIKey      string
+	
+	// URLs
+	BaseURL     string
+	FrontendURL string
+
+	// Link health checks
+	LinkHealthInterval         time.Duration
+	LinkHealthFailureThreshold int
+
+	// URL policy
+	URLBlocklistFile string
+
+	// Link limits per plan, enforced on imports; 0 means unlimited
+	FreeLinkLimit    int
+	PremiumLinkLimit int
+
+	// Analytics ingestion
+	AnalyticsQueueSize     int
+	AnalyticsBatchSize     int
+	AnalyticsFlushInterval time.Duration
+
+	// Analytics rollups
+	AnalyticsRollupInterval   time.Duration
+	AnalyticsRawRetentionDays int
+
+	// Analytics exports
+	AnalyticsExportSyncDays int
+	AnalyticsExportBucket   string
+
+	// IP geolocation
+	GeoIPDatabasePath string
+	GeoIPCacheSize    int
+	GeoIPAPIFallback  bool
+
+	// Unique visitor fingerprints
+	VisitorSalt string
+
+	// Bot filtering
+	BotRateLimit int
+
+	// Visitor IP storage
+	IPStorageMode string
+
+	// Cloudflare
+	BehindCloudflare bool
+}
+
+// Load loads configuration from environment variables
+func Load() *Config {
+	// Load .env file if it exists
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	config := &Config{
+		// Server
+		Port:    getEnv("PORT", "8080"),
+		GinMode: getEnv("GIN_MODE", "debug"),
+
+		// Database
+		DatabaseURL: getEnvRequired("DATABASE_URL"),
+		DirectURL:   getEnv("DIRECT_URL", ""),
+
+		// Redis - Traditional (fallback if Upstash not available)
+		RedisHost:     getEnv("REDIS_HOST", ""),
+		RedisPort:     getEnv("REDIS_PORT", ""),
+		RedisPassword: getEnv("REDIS_PASSWORD", ""),
+		RedisUsername: getEnv("REDIS_USERNAME", "default"),
+		RedisDB:       getEnvAsInt("REDIS_DB", 0),
+		
+		// Redis - Upstash (preferred)
+		UpstashRedisURL:   getEnv("UPSTASH_REDIS_REST_URL", ""),
+		UpstashRedisToken: getEnv("UPSTASH_REDIS_REST_TOKEN", ""),
+
+		// JWT
+		JWTSecret: getEnvRequired("JWT_SECRET"),
+
+		// CORS
+		CORSOrigins: getEnv("CORS_ORIGINS", "http://localhost:3000,http://localhost:5173"),
+
+		// Rate Limiting
+		RateLimitWindow:  time.Duration(getEnvAsInt("RATE_LIMIT_WINDOW", 300)) * time.Second,
+		RateLimitMax:     getEnvAsInt("RATE_LIMIT_MAX", 100),
+		AuthRateLimitMax: getEnvAsInt("AUTH_RATE_LIMIT_MAX", 5),
+		SessionExpiry:    time.Duration(getEnvAsInt("SESSION_EXPIRY", 86400)) * time.Second,
+
+		// Supabase
+		SupabaseURL:            getEnv("NEXT_PUBLIC_SUPABASE_URL", ""),
+		SupabaseAnonKey:        getEnv("NEXT_PUBLIC_SUPABASE_ANON_KEY", ""),
+		SupabaseServiceRoleKey: getEnv("SUPABASE_SERVICE_ROLE_KEY", ""),
+
+		// Discord
+		DiscordBotToken:     getEnv("DISCORD_BOT_TOKEN", ""),
+		DiscordClientID:     getEnv("DISCORD_CLIENT_ID", ""),
+		DiscordClientSecret: getEnv("DISCORD_CLIENT_SECRET", ""),
+		DiscordRedirectURI:  getEnv("DISCORD_REDIRECT_URI", ""),
+		DiscordGuildID:      getEnv("DISCORD_GUILD_ID", ""),
+
+		// Google OAuth
+		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
+		GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
+		GoogleRedirectURI:  getEnv("GOOGLE_REDIRECT_URI", ""),
+
+		// Email
+		ResendAPIKey: getEnv("RESEND_API_KEY", ""),
+		EmailFrom:    getEnv("EMAIL_FROM", "noreply@gotchu.lol"),
+
+		// Cloudflare
+		CloudflareTurnstileSiteKey:   getEnv("NEXT_PUBLIC_CLOUDFLARE_TURNSTILE_SITE_KEY", ""),
+		CloudflareTurnstileSecretKey: getEnv("CLOUDFLARE_TURNSTILE_SECRET_KEY", ""),
+
+		// Site
+		SiteURL: getEnv("SITE_URL", "http://localhost:5173"),
+
+		// OxaPay Payment Gateway
+		OxaPayMerchantKey: getEnv("OXAPAY_MERCHANT_KEY", ""),
+		OxaPayAPIKey:      getEnv("OXAPAY_API_KEY", ""),
+		
+		// URLs
+		BaseURL:     getEnv("BASE_URL", "http://localhost:8080"),
+		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:5173"),
+
+		// Link health checks
+		LinkHealthInterval:         time.Duration(getEnvAsInt("LINK_HEALTH_INTERVAL", 900)) * time.Second,
+		LinkHealthFailureThreshold: getEnvAsInt("LINK_HEALTH_FAILURE_THRESHOLD", 3),
+
+		// URL policy
+		URLBlocklistFile: getEnv("URL_BLOCKLIST_FILE", ""),
+
+		// Link limits per plan; unlimited until limits are set
+		FreeLinkLimit:    getEnvAsInt("FREE_LINK_LIMIT", 0),
+		PremiumLinkLimit: getEnvAsInt("PREMIUM_LINK_LIMIT", 0),
+
+		// Analytics ingestion
+		AnalyticsQueueSize:     getEnvAsInt("ANALYTICS_QUEUE_SIZE", 10000),
+		AnalyticsBatchSize:     getEnvAsInt("ANALYTICS_BATCH_SIZE", 500),
+		AnalyticsFlushInterval: time.Duration(getEnvAsInt("ANALYTICS_FLUSH_INTERVAL_MS", 2000)) * time.Millisecond,
+
+		// Analytics rollups; a retention of 0 keeps raw views and clicks forever
+		AnalyticsRollupInterval:   time.Duration(getEnvAsInt("ANALYTICS_ROLLUP_INTERVAL", 300)) * time.Second,
+		AnalyticsRawRetentionDays: getEnvAsInt("ANALYTICS_RAW_RETENTION_DAYS", 90),
+
+		// Analytics exports: longer ranges are built in the background and
+		// uploaded to a private storage bucket
+		AnalyticsExportSyncDays: getEnvAsInt("ANALYTICS_EXPORT_SYNC_DAYS", 31),
+		AnalyticsExportBucket:   getEnv("ANALYTICS_EXPORT_BUCKET", "analytics-exports"),
+
+		// IP geolocation: a local MMDB file, with ip-api.com for what it cannot answer
+		GeoIPDatabasePath: getEnv("GEOIP_DB_PATH", ""),
+		GeoIPCacheSize:    getEnvAsInt("GEOIP_CACHE_SIZE", 10000),
+		GeoIPAPIFallback:  getEnvAsBool("GEOIP_API_FALLBACK", true),
+
+		// Unique visitor fingerprints; changing the salt restarts unique counts
+		VisitorSalt: getEnv("VISITOR_SALT", ""),
+
+		// Bot filtering: views and clicks one address can make per minute
+		// before the rest are counted as automated
+		BotRateLimit: getEnvAsInt("BOT_RATE_LIMIT", 60),
+
+		// Visitor IP storage: "raw" keeps addresses as they are, "private"
+		// stores a network prefix and a daily salted hash, and anonymizes
+		// the addresses already stored
+		IPStorageMode: getEnv("IP_STORAGE_MODE", "raw"),
+
+		// Cloudflare: only when every request comes through Cloudflare can
+		// the country header it adds be trusted over a geo lookup
+		BehindCloudflare: getEnvAsBool("BEHIND_CLOUDFLARE", false),
+	}
+	if config.VisitorSalt == "" {
+		config.VisitorSalt = config.JWTSecret
+	}
+
+	return config
+}
+
+// getEnv gets an environment variable with a default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvRequired gets a required environment variable
+func getEnvRequired(key string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		log.Fatalf("Required environment variable %s is not set", key)
+	}
+	return value
+}
+
+// getEnvAsInt gets an environment variable as integer with default
+func getEnvAsInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+		log.Printf("Invalid integer value for %s: %s, using default: %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvAsBool gets an environment variable as boolean with default
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+		log.Printf("Invalid boolean value for %s: %s, using default: %t", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// IsDevelopment returns true if running in development mode
+func (c *Config) IsDevelopment() bool {
+	return c.GinMode == "debug"
+}
+
+// LinkLimit returns how many links a user on the given plan may have, or 0
+// for no limit
+func (c *Config) LinkLimit(plan string) int {
+	if plan == "premium" {
+		return c.PremiumLinkLimit
+	}
+	return c.FreeLinkLimit
+}
+
+// IsProduction returns true if running in production mode
+func (c *Config) IsProduction() bool {
+	return c.GinMode == "release"
 }
\ No newline at end of file
@@ -0,0 +1,528 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gotchu-backend/internal/middleware"
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/analytics"
+	"gotchu-backend/pkg/tabular"
+	"gotchu-backend/pkg/workers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxAnalyticsExportDays caps the date range of one export
+	maxAnalyticsExportDays = 731
+	// analyticsExportTTL is how long a background export and its download link last
+	analyticsExportTTL = 24 * time.Hour
+	// analyticsExportsPerHour limits how many exports a user can start
+	analyticsExportsPerHour = 20
+	// analyticsExportWriteTimeout replaces the server's write timeout for streamed exports
+	analyticsExportWriteTimeout = 10 * time.Minute
+)
+
+// Analytics export job states
+const (
+	ExportQueued    = "queued"
+	ExportRunning   = "running"
+	ExportCompleted = "completed"
+	ExportFailed    = "failed"
+)
+
+// analyticsExport describes what to export. Dates are UTC days, both inclusive.
+type analyticsExport struct {
+	UserID      uint
+	Username    string
+	Dataset     string // "views" or "clicks"
+	Granularity string // "raw" events or "daily" rollups
+	Format      tabular.Format
+	From        time.Time
+	To          time.Time
+}
+
+// AnalyticsExportJob is the state of an export built in the background
+type AnalyticsExportJob struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Dataset     string     `json:"dataset"`
+	Granularity string     `json:"granularity"`
+	Format      string     `json:"format"`
+	From        string     `json:"from"`
+	To          string     `json:"to"`
+	Rows        int64      `json:"rows"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+}
+
+// ExportAnalytics exports the user's profile views (?dataset=views) or link
+// clicks (?dataset=clicks) between ?from= and ?to= (UTC dates, inclusive) as
+// CSV, NDJSON or Parquet. ?granularity=raw exports one row per event, back to
+// the raw event retention window; ?granularity=daily exports the daily
+// rollups, one row per day and dimension value. Events carry no IP address,
+// user agent, session or full referrer URL, only the referring domain.
+//
+// Ranges of up to ANALYTICS_EXPORT_SYNC_DAYS days are streamed in the
+// response. Longer ranges, or any range with ?async=true, are built by a
+// background job; the response is 202 with the job, whose status and
+// download link are then read from GetAnalyticsExport.
+func (h *DashboardHandler) ExportAnalytics(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	export, err := parseAnalyticsExport(c, user)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if h.redisClient != nil {
+		result, err := h.redisClient.CheckRateLimit(fmt.Sprintf("analytics_export:%d", user.ID), analyticsExportsPerHour, time.Hour)
+		if err == nil && result.Exceeded {
+			c.JSON(http.StatusTooManyRequests, DashboardResponse{
+				Success: false,
+				Message: "Too many exports. Please try again later.",
+			})
+			return
+		}
+	}
+
+	days := int(export.To.Sub(export.From).Hours()/24) + 1
+	if days > h.config.AnalyticsExportSyncDays || c.Query("async") == "true" {
+		h.startAnalyticsExport(c, export)
+		return
+	}
+
+	// A streamed export can take longer than the server's write timeout allows
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(analyticsExportWriteTimeout)); err != nil {
+		fmt.Printf("Failed to extend write deadline for analytics export: %v\n", err)
+	}
+
+	c.Header("Content-Type", export.Format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", analyticsExportFileName(export)))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure can only cut the file short
+	if _, err := writeAnalyticsExport(h.db, export, c.Writer); err != nil {
+		fmt.Printf("Failed to export analytics for user %d: %v\n", user.ID, err)
+		c.Abort()
+	}
+}
+
+// GetAnalyticsExport returns the state of a background export, with its
+// download link once it has completed
+func (h *DashboardHandler) GetAnalyticsExport(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	var job AnalyticsExportJob
+	if h.redisClient != nil {
+		if err := h.redisClient.Get(analyticsExportKey(user.ID, c.Param("id")), &job); err != nil {
+			c.JSON(http.StatusInternalServerError, DashboardResponse{
+				Success: false,
+				Message: "Failed to retrieve export",
+			})
+			return
+		}
+	}
+	if job.ID == "" {
+		c.JSON(http.StatusNotFound, DashboardResponse{
+			Success: false,
+			Message: "Export not found or expired",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Export retrieved successfully",
+		Data:    job,
+	})
+}
+
+// parseAnalyticsExport reads and validates the export query parameters
+func parseAnalyticsExport(c *gin.Context, user *models.User) (analyticsExport, error) {
+	export := analyticsExport{
+		UserID:      user.ID,
+		Username:    user.Username,
+		Dataset:     strings.ToLower(c.DefaultQuery("dataset", "views")),
+		Granularity: strings.ToLower(c.DefaultQuery("granularity", "raw")),
+	}
+	if export.Dataset != "views" && export.Dataset != "clicks" {
+		return export, fmt.Errorf("unsupported dataset %q; use views or clicks", export.Dataset)
+	}
+	if export.Granularity != "raw" && export.Granularity != "daily" {
+		return export, fmt.Errorf("unsupported granularity %q; use raw or daily", export.Granularity)
+	}
+
+	format, err := tabular.ParseFormat(c.DefaultQuery("format", string(tabular.FormatCSV)))
+	if err != nil {
+		return export, err
+	}
+	export.Format = format
+
+	// The last 30 days by default
+	now := time.Now().UTC()
+	export.To = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	export.From = export.To.AddDate(0, 0, -29)
+	if value := c.Query("to"); value != "" {
+		if export.To, err = time.Parse("2006-01-02", value); err != nil {
+			return export, fmt.Errorf("invalid to date %q; use YYYY-MM-DD", value)
+		}
+		if c.Query("from") == "" {
+			export.From = export.To.AddDate(0, 0, -29)
+		}
+	}
+	if value := c.Query("from"); value != "" {
+		if export.From, err = time.Parse("2006-01-02", value); err != nil {
+			return export, fmt.Errorf("invalid from date %q; use YYYY-MM-DD", value)
+		}
+	}
+
+	if export.From.After(export.To) {
+		return export, fmt.Errorf("from date must not be after to date")
+	}
+	if export.To.Sub(export.From) >= maxAnalyticsExportDays*24*time.Hour {
+		return export, fmt.Errorf("date range can span at most %d days", maxAnalyticsExportDays)
+	}
+	return export, nil
+}
+
+// startAnalyticsExport queues a background export and responds with the job
+func (h *DashboardHandler) startAnalyticsExport(c *gin.Context, export analyticsExport) {
+	if h.redisClient == nil || h.config.SupabaseURL == "" {
+		c.JSON(http.StatusServiceUnavailable, DashboardResponse{
+			Success: false,
+			Message: fmt.Sprintf("Background exports are unavailable; export at most %d days at a time", h.config.AnalyticsExportSyncDays),
+		})
+		return
+	}
+
+	now := time.Now()
+	job := AnalyticsExportJob{
+		ID:          uuid.New().String(),
+		Status:      ExportQueued,
+		Dataset:     export.Dataset,
+		Granularity: export.Granularity,
+		Format:      string(export.Format),
+		From:        export.From.Format("2006-01-02"),
+		To:          export.To.Format("2006-01-02"),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(analyticsExportTTL),
+	}
+	key := analyticsExportKey(export.UserID, job.ID)
+	if err := h.redisClient.Set(key, job, analyticsExportTTL); err != nil {
+		fmt.Printf("Failed to save analytics export job for user %d: %v\n", export.UserID, err)
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to start export",
+		})
+		return
+	}
+
+	queued := h.workerPool.Submit(workers.Job{
+		ID:      "analytics-export-" + job.ID,
+		Handler: func() error { return h.runAnalyticsExport(export, job) },
+		Timeout: time.Hour,
+	})
+	if !queued {
+		h.redisClient.Delete(key)
+		c.JSON(http.StatusServiceUnavailable, DashboardResponse{
+			Success: false,
+			Message: "Too many exports in progress. Please try again later.",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, DashboardResponse{
+		Success: true,
+		Message: "Export started",
+		Data:    job,
+	})
+}
+
+// runAnalyticsExport builds a background export in a temporary file, uploads
+// it to the private export bucket and records a signed download link
+func (h *DashboardHandler) runAnalyticsExport(export analyticsExport, job AnalyticsExportJob) error {
+	key := analyticsExportKey(export.UserID, job.ID)
+	save := func() {
+		if err := h.redisClient.Set(key, job, time.Until(job.ExpiresAt)); err != nil {
+			fmt.Printf("Failed to save analytics export job %s: %v\n", job.ID, err)
+		}
+	}
+	fail := func(err error) error {
+		now := time.Now()
+		job.Status = ExportFailed
+		job.Error = "Export failed"
+		job.CompletedAt = &now
+		save()
+		return fmt.Errorf("analytics export %s for user %d: %w", job.ID, export.UserID, err)
+	}
+
+	job.Status = ExportRunning
+	save()
+
+	file, err := os.CreateTemp("", "analytics-export-*")
+	if err != nil {
+		return fail(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	rows, err := writeAnalyticsExport(h.db, export, file)
+	if err != nil {
+		return fail(err)
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fail(err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fail(err)
+	}
+
+	h.deleteExpiredAnalyticsExports(export.UserID)
+
+	bucket := h.config.AnalyticsExportBucket
+	path := fmt.Sprintf("%d/%s.%s", export.UserID, job.ID, export.Format)
+	if err := h.storage.UploadReader(bucket, path, file, size, export.Format.ContentType()); err != nil {
+		return fail(err)
+	}
+	downloadURL, err := h.storage.CreateSignedURL(bucket, path, time.Until(job.ExpiresAt))
+	if err != nil {
+		return fail(err)
+	}
+	// Supabase serves the file under its storage name unless asked otherwise
+	downloadURL += "&download=" + url.QueryEscape(analyticsExportFileName(export))
+
+	now := time.Now()
+	job.Status = ExportCompleted
+	job.Rows = rows
+	job.DownloadURL = downloadURL
+	job.CompletedAt = &now
+	save()
+	return nil
+}
+
+// deleteExpiredAnalyticsExports removes a user's export files whose download
+// links have expired
+func (h *DashboardHandler) deleteExpiredAnalyticsExports(userID uint) {
+	folder := fmt.Sprintf("%d", userID)
+	files, err := h.storage.ListFiles(h.config.AnalyticsExportBucket, folder)
+	if err != nil {
+		fmt.Printf("Failed to list analytics exports of user %d: %v\n", userID, err)
+		return
+	}
+	for _, file := range files {
+		if file.ID != "" && time.Since(file.CreatedAt) > analyticsExportTTL {
+			h.storage.DeleteFile(h.config.AnalyticsExportBucket, folder+"/"+file.Name)
+		}
+	}
+}
+
+func analyticsExportKey(userID uint, jobID string) string {
+	return fmt.Sprintf("analytics_export:%d:%s", userID, jobID)
+}
+
+func analyticsExportFileName(export analyticsExport) string {
+	return fmt.Sprintf("gotchu-%s-%s-%s-%s-to-%s.%s", export.Username, export.Dataset, export.Granularity,
+		export.From.Format("2006-01-02"), export.To.Format("2006-01-02"), export.Format)
+}
+
+// Exported rows; only these columns ever leave the database
+type (
+	exportedView struct {
+		CreatedAt time.Time
+		Country   *string
+		Device    *string
+		Browser   *string
+		Referer   *string
+		Source    *string
+	}
+	exportedClick struct {
+		CreatedAt time.Time
+		LinkID    uint
+		LinkTitle *string
+		Variant   *string
+		Country   *string
+		Device    *string
+		Browser   *string
+		Referer   *string
+		Source    *string
+	}
+	exportedDailyViews struct {
+		Day       time.Time
+		Dimension string
+		Value     string
+		Views     int64
+	}
+	exportedDailyClicks struct {
+		Day       time.Time
+		LinkID    uint
+		LinkTitle *string
+		Dimension string
+		Value     string
+		Clicks    int64
+	}
+)
+
+// writeAnalyticsExport streams an export to w, reading rows from the
+// database as they are written, and returns the number of rows written
+func writeAnalyticsExport(db *gorm.DB, export analyticsExport, w io.Writer) (int64, error) {
+	fromDay := export.From.Format("2006-01-02")
+	toDay := export.To.Format("2006-01-02")
+	end := export.To.AddDate(0, 0, 1)
+
+	var columns []tabular.Column
+	var query *gorm.DB
+	var scan func(rows *sql.Rows) ([]interface{}, error)
+	switch {
+	case export.Dataset == "views" && export.Granularity == "raw":
+		columns = []tabular.Column{
+			{Name: "viewed_at", Type: tabular.Timestamp},
+			{Name: "country", Type: tabular.String},
+			{Name: "device", Type: tabular.String},
+			{Name: "browser", Type: tabular.String},
+			{Name: "referrer", Type: tabular.String},
+		}
+		query = db.Model(&models.ProfileView{}).
+			Select("created_at, country, device, browser, referer, source").
+			Where("user_id = ? AND created_at >= ? AND created_at < ?", export.UserID, export.From, end).
+			Order("created_at ASC, id ASC")
+		scan = func(rows *sql.Rows) ([]interface{}, error) {
+			var view exportedView
+			err := db.ScanRows(rows, &view)
+			return []interface{}{view.CreatedAt, view.Country, view.Device, view.Browser, exportReferrer(view.Referer, view.Source)}, err
+		}
+	case export.Dataset == "clicks" && export.Granularity == "raw":
+		columns = []tabular.Column{
+			{Name: "clicked_at", Type: tabular.Timestamp},
+			{Name: "link_id", Type: tabular.Int64},
+			{Name: "link_title", Type: tabular.String},
+			{Name: "variant", Type: tabular.String},
+			{Name: "country", Type: tabular.String},
+			{Name: "device", Type: tabular.String},
+			{Name: "browser", Type: tabular.String},
+			{Name: "referrer", Type: tabular.String},
+		}
+		query = db.Table("link_clicks").
+			Select("link_clicks.created_at, link_clicks.link_id, links.title AS link_title, link_clicks.variant, "+
+				"link_clicks.country, link_clicks.device, link_clicks.browser, link_clicks.referer, link_clicks.source").
+			Joins("JOIN links ON links.id = link_clicks.link_id").
+			Where("links.user_id = ? AND link_clicks.created_at >= ? AND link_clicks.created_at < ?", export.UserID, export.From, end).
+			Order("link_clicks.created_at ASC, link_clicks.id ASC")
+		scan = func(rows *sql.Rows) ([]interface{}, error) {
+			var click exportedClick
+			err := db.ScanRows(rows, &click)
+			return []interface{}{click.CreatedAt, click.LinkID, click.LinkTitle, click.Variant,
+				click.Country, click.Device, click.Browser, exportReferrer(click.Referer, click.Source)}, err
+		}
+	case export.Dataset == "views":
+		columns = []tabular.Column{
+			{Name: "day", Type: tabular.Date},
+			{Name: "dimension", Type: tabular.String},
+			{Name: "value", Type: tabular.String},
+			{Name: "views", Type: tabular.Int64},
+		}
+		query = db.Model(&models.DailyProfileStat{}).
+			Select("day, dimension, value, views").
+			Where("user_id = ? AND day BETWEEN ? AND ?", export.UserID, fromDay, toDay).
+			Order("day ASC, dimension ASC, views DESC, value ASC")
+		scan = func(rows *sql.Rows) ([]interface{}, error) {
+			var stat exportedDailyViews
+			err := db.ScanRows(rows, &stat)
+			return []interface{}{stat.Day, stat.Dimension, stat.Value, stat.Views}, err
+		}
+	default:
+		columns = []tabular.Column{
+			{Name: "day", Type: tabular.Date},
+			{Name: "link_id", Type: tabular.Int64},
+			{Name: "link_title", Type: tabular.String},
+			{Name: "dimension", Type: tabular.String},
+			{Name: "value", Type: tabular.String},
+			{Name: "clicks", Type: tabular.Int64},
+		}
+		query = db.Table("daily_link_stats").
+			Select("daily_link_stats.day, daily_link_stats.link_id, links.title AS link_title, "+
+				"daily_link_stats.dimension, daily_link_stats.value, daily_link_stats.clicks").
+			Joins("LEFT JOIN links ON links.id = daily_link_stats.link_id").
+			Where("daily_link_stats.user_id = ? AND daily_link_stats.day BETWEEN ? AND ?", export.UserID, fromDay, toDay).
+			Order("daily_link_stats.day ASC, daily_link_stats.link_id ASC, daily_link_stats.dimension ASC, daily_link_stats.clicks DESC")
+		scan = func(rows *sql.Rows) ([]interface{}, error) {
+			var stat exportedDailyClicks
+			err := db.ScanRows(rows, &stat)
+			return []interface{}{stat.Day, stat.LinkID, stat.LinkTitle, stat.Dimension, stat.Value, stat.Clicks}, err
+		}
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	writer, err := tabular.NewWriter(export.Format, w, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for rows.Next() {
+		row, err := scan(rows)
+		if err != nil {
+			return count, err
+		}
+		if err := writer.Write(row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
+
+// exportReferrer reduces a referrer to what the rollups keep: the traffic
+// source tag of tagged visits, or else the referring domain without "www.".
+// Full referrer URLs can carry search terms and tokens, so they are not exported.
+func exportReferrer(referer, source *string) *string {
+	if source != nil && *source != "" {
+		return source
+	}
+	if referer == nil {
+		return nil
+	}
+	if domain := analytics.ReferrerHost(*referer); domain != "" {
+		return &domain
+	}
+	return nil
+}
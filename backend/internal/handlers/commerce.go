@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotchu-backend/internal/models"
+)
+
+const (
+	// maxCommerceImages limits the gallery of a commerce link
+	maxCommerceImages = 10
+	// maxCommerceAmountDigits bounds the whole part of a price
+	maxCommerceAmountDigits = 12
+)
+
+// commerceCurrencies maps the accepted ISO 4217 codes to their decimal places
+var commerceCurrencies = map[string]int{
+	"AED": 2, "ARS": 2, "AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2,
+	"CLP": 0, "CNY": 2, "COP": 2, "CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2,
+	"HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "JPY": 0, "KRW": 0, "KWD": 3, "MXN": 2,
+	"MYR": 2, "NGN": 2, "NOK": 2, "NZD": 2, "PHP": 2, "PLN": 2, "RON": 2, "SAR": 2,
+	"SEK": 2, "SGD": 2, "THB": 2, "TRY": 2, "TWD": 2, "UAH": 2, "USD": 2, "VND": 0,
+	"ZAR": 2,
+}
+
+// ProductConversion reports how often a commerce link is clicked per profile view
+type ProductConversion struct {
+	LinkID         uint                 `json:"link_id"`
+	Title          string               `json:"title"`
+	Type           models.LinkType      `json:"type"`
+	Commerce       *models.LinkCommerce `json:"commerce,omitempty"`
+	Clicks         int                  `json:"clicks"`
+	ConversionRate float64              `json:"conversion_rate"` // clicks per 100 profile views
+}
+
+// normalizeLinkCommerce validates commerce details for a link type and
+// returns them in canonical form, or nil when none are set
+func normalizeLinkCommerce(linkType models.LinkType, commerce *models.LinkCommerce) (*models.LinkCommerce, error) {
+	if commerce.IsEmpty() {
+		return nil, nil
+	}
+	availability, ok := models.CommerceAvailability[linkType]
+	if !ok {
+		return nil, fmt.Errorf("Commerce details are only allowed on PRODUCT, SERVICE and MARKETPLACE links")
+	}
+
+	normalized := &models.LinkCommerce{}
+
+	if commerce.Price != nil {
+		currency := strings.ToUpper(strings.TrimSpace(commerce.Currency))
+		decimals, ok := commerceCurrencies[currency]
+		if !ok {
+			return nil, fmt.Errorf("A price needs a supported ISO 4217 currency code like USD")
+		}
+		normalized.Currency = currency
+
+		price, priceMinor, err := normalizeCommerceAmount(*commerce.Price, decimals)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid price: %v", err)
+		}
+		normalized.Price = &price
+
+		if commerce.CompareAtPrice != nil {
+			compareAt, compareAtMinor, err := normalizeCommerceAmount(*commerce.CompareAtPrice, decimals)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid compare_at_price: %v", err)
+			}
+			if compareAtMinor <= priceMinor {
+				return nil, fmt.Errorf("compare_at_price must be higher than price")
+			}
+			normalized.CompareAtPrice = &compareAt
+		}
+	} else if commerce.CompareAtPrice != nil || commerce.Currency != "" {
+		return nil, fmt.Errorf("compare_at_price and currency require a price")
+	}
+
+	if commerce.Availability != "" {
+		value := strings.ToLower(strings.TrimSpace(commerce.Availability))
+		if !contains(availability, value) {
+			return nil, fmt.Errorf("Invalid availability %q for %s links. Use one of %s", commerce.Availability, linkType, strings.Join(availability, ", "))
+		}
+		normalized.Availability = value
+	}
+
+	if len(commerce.Images) > maxCommerceImages {
+		return nil, fmt.Errorf("A gallery can have at most %d images", maxCommerceImages)
+	}
+	for _, image := range commerce.Images {
+		image = strings.TrimSpace(image)
+		if image == "" || contains(normalized.Images, image) {
+			continue
+		}
+		parsed, err := url.Parse(image)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || len(image) > 500 {
+			return nil, fmt.Errorf("Gallery images must be http or https URLs under 500 characters")
+		}
+		normalized.Images = append(normalized.Images, image)
+	}
+
+	if normalized.IsEmpty() {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+// normalizeCommerceAmount checks a non-negative decimal amount against the
+// currency's decimal places and returns it padded to them, along with its
+// value in minor units for comparisons
+func normalizeCommerceAmount(amount json.Number, decimals int) (json.Number, int64, error) {
+	whole, fraction, _ := strings.Cut(strings.TrimSpace(amount.String()), ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if whole == "" || !isDigits(whole) || !isDigits(fraction) {
+		return "", 0, fmt.Errorf("%q is not a plain non-negative number", amount)
+	}
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" {
+		whole = "0"
+	}
+	if len(whole) > maxCommerceAmountDigits {
+		return "", 0, fmt.Errorf("%q is too large", amount)
+	}
+	if len(fraction) > decimals {
+		return "", 0, fmt.Errorf("%q has more than %d decimal places", amount, decimals)
+	}
+
+	fraction += strings.Repeat("0", decimals-len(fraction))
+	minor, _ := strconv.ParseInt(whole+fraction, 10, 64)
+	if fraction == "" {
+		return json.Number(whole), minor, nil
+	}
+	return json.Number(whole + "." + fraction), minor, nil
+}
+
+// getProductConversions reports clicks and conversion for each of the user's
+// commerce links between startTime and endTime; allTime uses the lifetime
+// click counters instead
+func (h *DashboardHandler) getProductConversions(userID uint, startTime, endTime time.Time, allTime bool, profileViews int) []ProductConversion {
+	var links []models.Link
+	h.db.Select("id, title, type, commerce, clicks").
+		Where("user_id = ? AND type IN ?", userID, []models.LinkType{models.LinkTypeProduct, models.LinkTypeService, models.LinkTypeMarketplace}).
+		Order("\"order\" ASC, created_at ASC").
+		Find(&links)
+
+	conversions := make([]ProductConversion, 0, len(links))
+	if len(links) == 0 {
+		return conversions
+	}
+
+	clicks := make(map[uint]int, len(links))
+	if allTime {
+		for _, link := range links {
+			clicks[link.ID] = link.Clicks
+		}
+	} else {
+		ids := make([]uint, len(links))
+		for i, link := range links {
+			ids[i] = link.ID
+		}
+		var rows []struct {
+			LinkID uint
+			Clicks int
+		}
+		fromDay, toDay := rollupDays(startTime, endTime)
+		h.db.Model(&models.DailyLinkStat{}).
+			Select("link_id, SUM(clicks) AS clicks").
+			Where("link_id IN ? AND dimension = ? AND day BETWEEN ? AND ?", ids, models.RollupTotal, fromDay, toDay).
+			Group("link_id").
+			Scan(&rows)
+		for _, row := range rows {
+			clicks[row.LinkID] = row.Clicks
+		}
+	}
+
+	for _, link := range links {
+		conversion := ProductConversion{
+			LinkID:   link.ID,
+			Title:    link.Title,
+			Type:     link.Type,
+			Commerce: link.Commerce,
+			Clicks:   clicks[link.ID],
+		}
+		if profileViews > 0 {
+			conversion.ConversionRate = float64(conversion.Clicks) / float64(profileViews) * 100
+		}
+		conversions = append(conversions, conversion)
+	}
+	return conversions
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
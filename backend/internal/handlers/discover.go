@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotchu-backend/internal/middleware"
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/redis"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// discoverCacheTTL is how long a discovery page is cached
+	discoverCacheTTL = 2 * time.Minute
+	// discoverMaxLimit caps the page size
+	discoverMaxLimit = 50
+	// trendingHalfLife is the half-life of a profile view's weight in the trending score
+	trendingHalfLife = 48 * time.Hour
+	// trendingWindow bounds how far back views count towards trending
+	trendingWindow = 14 * 24 * time.Hour
+)
+
+// Discovery sort options
+const (
+	DiscoverSortTrending  = "trending"
+	DiscoverSortNewest    = "newest"
+	DiscoverSortFollowers = "followers"
+	DiscoverSortRelevance = "relevance"
+)
+
+// DiscoverHandler handles the public profile directory
+type DiscoverHandler struct {
+	db          *gorm.DB
+	redisClient *redis.Client
+}
+
+// NewDiscoverHandler creates a new discover handler
+func NewDiscoverHandler(db *gorm.DB, redisClient *redis.Client) *DiscoverHandler {
+	return &DiscoverHandler{
+		db:          db,
+		redisClient: redisClient,
+	}
+}
+
+// DiscoverProfile is a profile summary returned by the directory
+type DiscoverProfile struct {
+	ID            uint      `json:"id"`
+	Username      string    `json:"username"`
+	Alias         *string   `json:"alias,omitempty"`
+	DisplayName   *string   `json:"display_name,omitempty"`
+	AvatarURL     *string   `json:"avatar_url,omitempty"`
+	Bio           *string   `json:"bio,omitempty"`
+	Location      *string   `json:"location,omitempty"`
+	IsVerified    bool      `json:"is_verified"`
+	Plan          string    `json:"plan"`
+	AccentColor   string    `json:"accent_color"`
+	ProfileViews  int       `json:"profile_views"`
+	FollowerCount int       `json:"follower_count"`
+	TrendingScore float64   `json:"trending_score"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// discoverQuery holds the normalized discovery parameters
+type discoverQuery struct {
+	Search   string
+	Badges   []string
+	Plans    []string
+	Verified *bool
+	Sort     string
+	Page     int
+	Limit    int
+}
+
+// DiscoverProfiles searches and ranks listed profiles
+func (h *DiscoverHandler) DiscoverProfiles(c *gin.Context) {
+	query, err := parseDiscoverQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	cacheKey := query.cacheKey()
+	if h.redisClient != nil {
+		var cached gin.H
+		if err := h.redisClient.Get(cacheKey, &cached); err == nil && cached != nil {
+			c.JSON(http.StatusOK, DashboardResponse{
+				Success: true,
+				Message: "Profiles retrieved successfully",
+				Data:    cached,
+			})
+			return
+		}
+	}
+
+	base := h.listedProfiles(query)
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		fmt.Printf("Failed to count discovery results: %v\n", err)
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to search profiles",
+		})
+		return
+	}
+
+	decay := trendingHalfLife.Seconds() / math.Ln2
+	profiles := []DiscoverProfile{}
+	err = base.Session(&gorm.Session{}).
+		Select(`users.id, users.username, users.alias, users.display_name, users.avatar_url, users.bio, users.location,
+			users.is_verified, users.plan, users.accent_color, users.profile_views, users.created_at,
+			COALESCE(f.follower_count, 0) AS follower_count, COALESCE(t.score, 0) AS trending_score`).
+		Joins("LEFT JOIN (SELECT following_id, COUNT(*) AS follower_count FROM follows GROUP BY following_id) f ON f.following_id = users.id").
+		Joins(`LEFT JOIN (
+			SELECT user_id, SUM(EXP(-EXTRACT(EPOCH FROM (NOW() - created_at)) / ?)) AS score
+			FROM profile_views WHERE created_at > ? GROUP BY user_id
+		) t ON t.user_id = users.id`, decay, time.Now().Add(-trendingWindow)).
+		Clauses(query.orderBy()).
+		Offset((query.Page - 1) * query.Limit).
+		Limit(query.Limit).
+		Scan(&profiles).Error
+	if err != nil {
+		fmt.Printf("Failed to search profiles: %v\n", err)
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to search profiles",
+		})
+		return
+	}
+
+	totalPages := int((total + int64(query.Limit) - 1) / int64(query.Limit))
+	data := gin.H{
+		"profiles": profiles,
+		"sort":     query.Sort,
+		"pagination": gin.H{
+			"page":        query.Page,
+			"limit":       query.Limit,
+			"total":       total,
+			"total_pages": totalPages,
+			"has_next":    query.Page < totalPages,
+			"has_prev":    query.Page > 1,
+		},
+	}
+
+	if h.redisClient != nil {
+		h.redisClient.Set(cacheKey, data, discoverCacheTTL)
+	}
+
+	c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Profiles retrieved successfully",
+		Data:    data,
+	})
+}
+
+// UpdateDiscoveryOptOut lets the authenticated user hide their profile from the directory
+func (h *DiscoverHandler) UpdateDiscoveryOptOut(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	var req struct {
+		OptOut *bool `json:"opt_out" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.db.Model(&models.User{}).Where("id = ?", user.ID).Update("discovery_opt_out", *req.OptOut).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to update discovery settings",
+		})
+		return
+	}
+
+	if h.redisClient != nil {
+		h.redisClient.InvalidateUserCache(user.ID)
+	}
+
+	c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Discovery settings updated successfully",
+		Data: gin.H{
+			"discovery_opt_out": *req.OptOut,
+		},
+	})
+}
+
+// listedProfiles builds the filtered base query; only public, listed, opted-in profiles are included
+func (h *DiscoverHandler) listedProfiles(query *discoverQuery) *gorm.DB {
+	db := h.db.Table("users").
+		Where("users.is_active = ? AND users.is_public = ? AND users.discovery_opt_out = ?", true, true, false).
+		Where("users.visibility = ? OR users.visibility IS NULL OR users.visibility = ''", models.ProfileVisibilityPublic)
+
+	if query.Search != "" {
+		pattern := "%" + escapeLike(query.Search) + "%"
+		db = db.Where(`users.username ILIKE ? OR users.alias ILIKE ? OR users.display_name ILIKE ?
+			OR users.bio ILIKE ? OR users.location ILIKE ?`, pattern, pattern, pattern, pattern, pattern)
+	}
+
+	if len(query.Badges) > 0 {
+		// Profiles must hold every requested badge
+		db = db.Where(`users.id IN (
+			SELECT user_id FROM user_badges
+			WHERE badge_id IN ? AND is_earned = ? AND is_visible = ?
+			GROUP BY user_id HAVING COUNT(DISTINCT badge_id) = ?
+		)`, query.Badges, true, true, len(query.Badges))
+	}
+
+	if len(query.Plans) > 0 {
+		db = db.Where("users.plan IN ?", query.Plans)
+	}
+
+	if query.Verified != nil {
+		db = db.Where("users.is_verified = ?", *query.Verified)
+	}
+
+	return db
+}
+
+// parseDiscoverQuery reads and validates the discovery query parameters
+func parseDiscoverQuery(c *gin.Context) (*discoverQuery, error) {
+	query := &discoverQuery{
+		Search: strings.TrimSpace(c.Query("q")),
+		Badges: splitList(c.Query("badges")),
+		Plans:  splitList(strings.ToLower(c.Query("plan"))),
+		Sort:   c.Query("sort"),
+		Page:   1,
+		Limit:  20,
+	}
+
+	if len(query.Search) > 100 {
+		return nil, fmt.Errorf("Search query is too long")
+	}
+
+	if query.Sort == "" {
+		if query.Search != "" {
+			query.Sort = DiscoverSortRelevance
+		} else {
+			query.Sort = DiscoverSortTrending
+		}
+	}
+	switch query.Sort {
+	case DiscoverSortTrending, DiscoverSortNewest, DiscoverSortFollowers:
+	case DiscoverSortRelevance:
+		if query.Search == "" {
+			query.Sort = DiscoverSortTrending
+		}
+	default:
+		return nil, fmt.Errorf("Sort must be one of trending, newest, followers, relevance")
+	}
+
+	if verified := c.Query("verified"); verified != "" {
+		value, err := strconv.ParseBool(verified)
+		if err != nil {
+			return nil, fmt.Errorf("Verified must be true or false")
+		}
+		query.Verified = &value
+	}
+
+	if page, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && page > 0 {
+		query.Page = page
+	}
+	if limit, err := strconv.Atoi(c.DefaultQuery("limit", "20")); err == nil && limit > 0 {
+		query.Limit = limit
+		if query.Limit > discoverMaxLimit {
+			query.Limit = discoverMaxLimit
+		}
+	}
+
+	return query, nil
+}
+
+// orderBy returns the ORDER BY clause for the selected sort
+func (q *discoverQuery) orderBy() clause.OrderBy {
+	var expr clause.Expr
+	switch q.Sort {
+	case DiscoverSortNewest:
+		expr = clause.Expr{SQL: "users.created_at DESC, users.id DESC"}
+	case DiscoverSortFollowers:
+		expr = clause.Expr{SQL: "follower_count DESC, trending_score DESC, users.id DESC"}
+	case DiscoverSortRelevance:
+		expr = clause.Expr{
+			SQL: `GREATEST(similarity(users.username, ?), similarity(COALESCE(users.alias, ''), ?),
+				similarity(COALESCE(users.display_name, ''), ?)) DESC, trending_score DESC, users.id DESC`,
+			Vars: []interface{}{q.Search, q.Search, q.Search},
+		}
+	default:
+		expr = clause.Expr{SQL: "trending_score DESC, users.profile_views DESC, users.id DESC"}
+	}
+	expr.WithoutParentheses = true
+	return clause.OrderBy{Expression: expr}
+}
+
+// cacheKey identifies a discovery result page
+func (q *discoverQuery) cacheKey() string {
+	badges := append([]string(nil), q.Badges...)
+	plans := append([]string(nil), q.Plans...)
+	sort.Strings(badges)
+	sort.Strings(plans)
+
+	verified := ""
+	if q.Verified != nil {
+		verified = strconv.FormatBool(*q.Verified)
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%s|%d|%d",
+		strings.ToLower(q.Search), strings.Join(badges, ","), strings.Join(plans, ","), verified, q.Sort, q.Page, q.Limit)))
+	return "discover:" + hex.EncodeToString(sum[:12])
+}
+
+// splitList splits a comma-separated query value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// escapeLike escapes LIKE wildcards in user input
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
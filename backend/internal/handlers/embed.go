@@ -0,0 +1,452 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotchu-backend/internal/middleware"
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/qrcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Widget dimensions used for the oEmbed iframe
+	widgetDefaultWidth = 360
+	widgetMinWidth     = 240
+	widgetBaseHeight   = 150
+	widgetLinkHeight   = 48
+	widgetBadgeHeight  = 36
+
+	// Widget content limits
+	widgetDefaultLinks = 5
+	widgetMaxLinks     = 10
+	widgetMaxBadges    = 8
+)
+
+// OEmbedResponse represents an oEmbed 1.0 "rich" response
+type OEmbedResponse struct {
+	Version         string `json:"version"`
+	Type            string `json:"type"`
+	ProviderName    string `json:"provider_name"`
+	ProviderURL     string `json:"provider_url"`
+	Title           string `json:"title"`
+	AuthorName      string `json:"author_name"`
+	AuthorURL       string `json:"author_url"`
+	HTML            string `json:"html"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+	CacheAge        int    `json:"cache_age"`
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int    `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight int    `json:"thumbnail_height,omitempty"`
+}
+
+// widgetOptions holds the theme parameters accepted by the widget
+type widgetOptions struct {
+	Theme  string
+	Accent string
+	Links  int
+	Badges bool
+}
+
+// widgetBadge is a showcased badge prepared for the widget template
+type widgetBadge struct {
+	Name     string
+	Emoji    string
+	ImageURL string
+}
+
+// widgetLink is a link prepared for the widget template
+type widgetLink struct {
+	Title string
+	Href  string
+}
+
+// widgetData is passed to the widget template
+type widgetData struct {
+	Options     widgetOptions
+	Name        string
+	Username    string
+	AvatarURL   string
+	IsVerified  bool
+	ProfileURL  string
+	Badges      []widgetBadge
+	Links       []widgetLink
+	Unavailable string
+}
+
+// OEmbed implements the oEmbed provider endpoint for profile URLs
+func (h *DashboardHandler) OEmbed(c *gin.Context) {
+	if format := c.DefaultQuery("format", "json"); format != "json" {
+		c.JSON(http.StatusNotImplemented, DashboardResponse{
+			Success: false,
+			Message: "Only the json format is supported",
+		})
+		return
+	}
+
+	profileURL, err := url.Parse(c.Query("url"))
+	if err != nil || !h.isProfileHost(profileURL.Host) {
+		c.JSON(http.StatusNotFound, DashboardResponse{
+			Success: false,
+			Message: "URL is not a gotchu profile",
+		})
+		return
+	}
+
+	username := strings.Trim(profileURL.Path, "/")
+	if username == "" || strings.Contains(username, "/") {
+		c.JSON(http.StatusNotFound, DashboardResponse{
+			Success: false,
+			Message: "URL is not a gotchu profile",
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.db.Where("(username = ? OR alias = ?) AND is_active = ?", username, username, true).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, DashboardResponse{
+			Success: false,
+			Message: "User not found",
+		})
+		return
+	}
+
+	// Only public and unlisted profiles can be embedded by third parties
+	if visibility := profileVisibility(&user); visibility != models.ProfileVisibilityPublic && visibility != models.ProfileVisibilityUnlisted {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Profile cannot be embedded",
+		})
+		return
+	}
+
+	// Theme parameters may be given on the oEmbed request or the profile URL
+	params := profileURL.Query()
+	for _, key := range []string{"theme", "accent", "links", "badges"} {
+		if value := c.Query(key); value != "" {
+			params.Set(key, value)
+		}
+	}
+	opts := parseWidgetOptions(params, &user)
+
+	width := widgetDefaultWidth
+	if maxWidth, err := strconv.Atoi(c.Query("maxwidth")); err == nil && maxWidth > 0 && maxWidth < width {
+		width = maxWidth
+	}
+	if width < widgetMinWidth {
+		width = widgetMinWidth
+	}
+
+	now := time.Now()
+	var linkCount int64
+	h.db.Model(&models.Link{}).
+		Where("user_id = ? AND is_active = ? AND url IS NOT NULL AND type <> ?", user.ID, true, models.LinkTypeHeader).
+		Where(liveLinkCondition, now, now).
+		Count(&linkCount)
+	if linkCount > int64(opts.Links) {
+		linkCount = int64(opts.Links)
+	}
+	height := widgetBaseHeight + int(linkCount)*widgetLinkHeight
+	if opts.Badges {
+		height += widgetBadgeHeight
+	}
+	if maxHeight, err := strconv.Atoi(c.Query("maxheight")); err == nil && maxHeight > 0 && maxHeight < height {
+		height = maxHeight
+	}
+
+	embedURL := fmt.Sprintf("%s/embed/%s?%s", strings.TrimRight(h.config.BaseURL, "/"), url.PathEscape(user.Username), widgetQuery(opts).Encode())
+	name := user.Username
+	if user.DisplayName != nil && *user.DisplayName != "" {
+		name = *user.DisplayName
+	}
+
+	response := OEmbedResponse{
+		Version:      "1.0",
+		Type:         "rich",
+		ProviderName: "gotchu",
+		ProviderURL:  h.config.FrontendURL,
+		Title:        name,
+		AuthorName:   name,
+		AuthorURL:    fmt.Sprintf("%s/%s", strings.TrimRight(h.config.FrontendURL, "/"), user.Username),
+		HTML: fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" style="border:0;border-radius:16px;overflow:hidden" loading="lazy" title="%s on gotchu"></iframe>`,
+			template.HTMLEscapeString(embedURL), width, height, template.HTMLEscapeString(name)),
+		Width:    width,
+		Height:   height,
+		CacheAge: 3600,
+	}
+	if user.AvatarURL != nil && *user.AvatarURL != "" {
+		response.ThumbnailURL = *user.AvatarURL
+		response.ThumbnailWidth = 128
+		response.ThumbnailHeight = 128
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, response)
+}
+
+// ProfileWidget renders the compact embeddable profile card
+func (h *DashboardHandler) ProfileWidget(c *gin.Context) {
+	username := c.Param("username")
+
+	// The widget is meant to be framed by third-party sites
+	c.Header("Content-Security-Policy", "default-src 'none'; img-src https: data:; style-src 'unsafe-inline'; frame-ancestors *")
+	c.Header("X-Robots-Tag", "noindex")
+
+	var user models.User
+	if err := h.db.Where("(username = ? OR alias = ?) AND is_active = ?", username, username, true).First(&user).Error; err != nil {
+		h.renderWidget(c, http.StatusNotFound, widgetData{Options: widgetOptions{Theme: "dark"}, Unavailable: "Profile not found"})
+		return
+	}
+
+	opts := parseWidgetOptions(c.Request.URL.Query(), &user)
+	data := widgetData{
+		Options:    opts,
+		Name:       user.Username,
+		Username:   user.Username,
+		AvatarURL:  getStringValue(user.AvatarURL),
+		IsVerified: user.IsVerified,
+		ProfileURL: fmt.Sprintf("%s/%s", strings.TrimRight(h.config.FrontendURL, "/"), url.PathEscape(user.Username)),
+	}
+	if user.DisplayName != nil && *user.DisplayName != "" {
+		data.Name = *user.DisplayName
+	}
+
+	if err := h.profileAccess.Check(c, &user); err != nil {
+		_, message, _ := profileAccessError(err)
+		data.AvatarURL = ""
+		data.Unavailable = message
+		h.renderWidget(c, http.StatusOK, data)
+		return
+	}
+
+	if opts.Badges {
+		var userBadges []models.UserBadge
+		h.db.Preload("Badge").
+			Where("user_id = ? AND is_earned = ? AND is_showcased = ? AND is_visible = ?", user.ID, true, true, true).
+			Order("showcase_order ASC, earned_at DESC").
+			Limit(widgetMaxBadges).
+			Find(&userBadges)
+
+		for _, ub := range userBadges {
+			badge := widgetBadge{Name: ub.Badge.Name}
+			switch ub.Badge.IconType {
+			case models.BadgeIconTypeEmoji:
+				badge.Emoji = ub.Badge.IconValue
+			case models.BadgeIconTypeCustomImage:
+				badge.ImageURL = ub.Badge.IconValue
+			default:
+				badge.Emoji = "🏅"
+			}
+			data.Badges = append(data.Badges, badge)
+		}
+	}
+
+	// Headers are loaded to put sectioned links in display order, then dropped
+	var links []models.Link
+	now := time.Now()
+	h.db.Preload("Variants", orderVariants).
+		Where("user_id = ? AND is_active = ? AND (url IS NOT NULL OR type = ?)", user.ID, true, models.LinkTypeHeader).
+		Where(liveLinkCondition, now, now).
+		Order("\"order\" ASC, created_at ASC").
+		Find(&links)
+
+	// Targeting is applied before the limit so hidden links don't use up slots
+	serveLinkVariants(links, linkVisitorKey(c))
+	links = targetLinks(links, linkAudience(c, h.geoService, h.redisClient, h.config.BehindCloudflare, linksNeedCountry(links)))
+	sectioned := flattenLinkSections(links, false)
+	links = links[:0]
+	for _, link := range sectioned {
+		if link.Type != models.LinkTypeHeader && link.URL != nil {
+			links = append(links, link)
+		}
+	}
+	if len(links) > opts.Links {
+		links = links[:opts.Links]
+	}
+
+	base := strings.TrimRight(h.config.BaseURL, "/")
+	for _, link := range links {
+		data.Links = append(data.Links, widgetLink{
+			Title: link.Title,
+			Href:  fmt.Sprintf("%s/embed/%s/links/%d", base, url.PathEscape(user.Username), link.ID),
+		})
+	}
+
+	// Record the view with the widget source (non-blocking)
+	if currentUser, ok := middleware.GetCurrentUser(c); !ok || currentUser.ID != user.ID {
+		source := models.TrafficSourceWidget
+		view := h.viewEventFromRequest(c, user.ID, &source)
+		recordVariantImpressions(h.db, h.redisClient, h.workerPool, links, linkVisitorKey(c), view.BotReason)
+		h.workerPool.SubmitFunc(fmt.Sprintf("track-widget-view-%d", user.ID), func() error {
+			h.trackProfileView(view)
+			return nil
+		})
+	}
+
+	c.Header("Cache-Control", "no-store")
+	h.renderWidget(c, http.StatusOK, data)
+}
+
+// WidgetLinkRedirect records a widget click and redirects to the link target
+func (h *DashboardHandler) WidgetLinkRedirect(c *gin.Context) {
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid link")
+		return
+	}
+
+	username := c.Param("username")
+	var user models.User
+	if err := h.db.Where("(username = ? OR alias = ?) AND is_active = ?", username, username, true).First(&user).Error; err != nil {
+		c.String(http.StatusNotFound, "Link not found")
+		return
+	}
+	profileURL := fmt.Sprintf("%s/%s", strings.TrimRight(h.config.FrontendURL, "/"), url.PathEscape(user.Username))
+
+	// Profiles that need an unlock or share link are handled by the profile page
+	if err := h.profileAccess.Check(c, &user); err != nil {
+		c.Redirect(http.StatusFound, profileURL)
+		return
+	}
+
+	var link models.Link
+	err = h.db.Preload("Variants", orderVariants).
+		Where("id = ? AND user_id = ? AND is_active = ?", linkID, user.ID, true).
+		First(&link).Error
+	if err != nil || !link.IsLiveAt(time.Now()) {
+		c.String(http.StatusNotFound, "Link not found")
+		return
+	}
+
+	abVariant := serveLinkVariant(&link, linkVisitorKey(c))
+	visible, target, variant := link.TargetFor(linkAudience(c, h.geoService, h.redisClient, h.config.BehindCloudflare, link.Targeting.NeedsCountry()))
+	if !visible || target == nil || !isRedirectableURL(*target) {
+		c.String(http.StatusNotFound, "Link not found")
+		return
+	}
+	if !passLinkGate(c, h.config.JWTSecret, &link, profileURL) {
+		return
+	}
+
+	source := models.TrafficSourceWidget
+	event := clickEventFromRequest(c, &link, &source, h.bots)
+	event.Variant = &variant
+	if abVariant != nil {
+		event.VariantID = &abVariant.ID
+	}
+	if !h.ingest.Enqueue(event) {
+		fmt.Printf("Analytics queue full, dropped widget click for link %d\n", link.ID)
+	}
+
+	c.Header("Referrer-Policy", "origin")
+	c.Redirect(http.StatusFound, *target)
+}
+
+// isProfileHost reports whether host serves gotchu profile pages
+func (h *DashboardHandler) isProfileHost(host string) bool {
+	for _, base := range []string{h.config.FrontendURL, h.config.SiteURL} {
+		if parsed, err := url.Parse(base); err == nil && parsed.Host != "" && strings.EqualFold(parsed.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWidgetOptions reads widget theme parameters, falling back to the user's accent color
+func parseWidgetOptions(params url.Values, user *models.User) widgetOptions {
+	opts := widgetOptions{
+		Theme:  "dark",
+		Accent: "#1bbd9a",
+		Links:  widgetDefaultLinks,
+		Badges: params.Get("badges") != "false",
+	}
+
+	if params.Get("theme") == "light" {
+		opts.Theme = "light"
+	}
+	if accent, err := qrcode.ParseColor(user.AccentColor); err == nil {
+		opts.Accent = qrcode.Hex(accent)
+	}
+	if accent, err := qrcode.ParseColor(params.Get("accent")); err == nil {
+		opts.Accent = qrcode.Hex(accent)
+	}
+	if links, err := strconv.Atoi(params.Get("links")); err == nil && links >= 0 {
+		opts.Links = links
+		if opts.Links > widgetMaxLinks {
+			opts.Links = widgetMaxLinks
+		}
+	}
+	return opts
+}
+
+// widgetQuery encodes widget options as query parameters
+func widgetQuery(opts widgetOptions) url.Values {
+	query := url.Values{}
+	query.Set("theme", opts.Theme)
+	query.Set("accent", strings.TrimPrefix(opts.Accent, "#"))
+	query.Set("links", strconv.Itoa(opts.Links))
+	query.Set("badges", strconv.FormatBool(opts.Badges))
+	return query
+}
+
+// renderWidget executes the widget template
+func (h *DashboardHandler) renderWidget(c *gin.Context, status int, data widgetData) {
+	var buf bytes.Buffer
+	if err := widgetTemplate.Execute(&buf, data); err != nil {
+		fmt.Printf("Failed to render widget: %v\n", err)
+		c.String(http.StatusInternalServerError, "Failed to render widget")
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+var widgetTemplate = template.Must(template.New("widget").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Name}} on gotchu</title>
+<style>
+*{box-sizing:border-box;margin:0;padding:0}
+body{font-family:system-ui,-apple-system,"Segoe UI",Roboto,sans-serif;{{if eq .Options.Theme "light"}}background:#ffffff;color:#111827{{else}}background:#0f0f12;color:#f3f4f6{{end}}}
+.card{padding:16px;display:flex;flex-direction:column;gap:12px}
+.head{display:flex;align-items:center;gap:12px;text-decoration:none;color:inherit}
+.avatar{width:56px;height:56px;border-radius:50%;object-fit:cover;border:2px solid {{.Options.Accent}}}
+.name{font-weight:600;font-size:16px}
+.user{opacity:.6;font-size:13px}
+.verified{color:{{.Options.Accent}};margin-left:4px}
+.badges{display:flex;flex-wrap:wrap;gap:6px}
+.badge{font-size:18px;line-height:24px}
+.badge img{width:24px;height:24px;vertical-align:middle}
+.links{display:flex;flex-direction:column;gap:8px}
+.link{display:block;padding:10px 12px;border-radius:10px;text-align:center;text-decoration:none;font-size:14px;font-weight:500;color:inherit;border:1px solid {{.Options.Accent}};white-space:nowrap;overflow:hidden;text-overflow:ellipsis}
+.link:hover{background:{{.Options.Accent}};color:#fff}
+.muted{opacity:.6;font-size:13px;text-align:center}
+.footer{font-size:11px;opacity:.5;text-align:center}
+.footer a{color:inherit}
+</style>
+</head>
+<body>
+<div class="card">
+{{if .Username}}<a class="head" href="{{.ProfileURL}}" target="_blank" rel="noopener">
+{{if .AvatarURL}}<img class="avatar" src="{{.AvatarURL}}" alt="">{{end}}
+<div><div class="name">{{.Name}}{{if .IsVerified}}<span class="verified" title="Verified">✔</span>{{end}}</div><div class="user">@{{.Username}}</div></div>
+</a>{{end}}
+{{if .Unavailable}}<p class="muted">{{.Unavailable}}</p>{{else}}
+{{if .Badges}}<div class="badges">{{range .Badges}}<span class="badge" title="{{.Name}}">{{if .ImageURL}}<img src="{{.ImageURL}}" alt="{{.Name}}">{{else}}{{.Emoji}}{{end}}</span>{{end}}</div>{{end}}
+{{if .Links}}<div class="links">{{range .Links}}<a class="link" href="{{.Href}}" target="_blank" rel="noopener">{{.Title}}</a>{{end}}</div>{{end}}
+{{end}}
+<div class="footer"><a href="{{.ProfileURL}}" target="_blank" rel="noopener">gotchu</a></div>
+</div>
+</body>
+</html>
+`))
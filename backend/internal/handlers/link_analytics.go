@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"gotchu-backend/internal/middleware"
+	"gotchu-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBreakdownItems caps each per-link breakdown list
+const maxBreakdownItems = 10
+
+// LinkBreakdown splits one link's clicks by visitor attributes
+type LinkBreakdown struct {
+	LinkID    uint            `json:"link_id"`
+	Title     string          `json:"title"`
+	Type      models.LinkType `json:"type"`
+	Clicks    int             `json:"clicks"`
+	Countries []BreakdownItem `json:"countries"`
+	Devices   []BreakdownItem `json:"devices"`
+	Browsers  []BreakdownItem `json:"browsers"`
+	Referrers []BreakdownItem `json:"referrers"` // referring domains and traffic source tags
+}
+
+// BreakdownItem is one value of a breakdown
+type BreakdownItem struct {
+	Name       string  `json:"name"`
+	Code       string  `json:"code,omitempty"` // country code, for countries
+	Clicks     int     `json:"clicks"`
+	Percentage float64 `json:"percentage"` // share of the link's clicks
+}
+
+// GetLinkAnalytics breaks the clicks of each of the user's links down by
+// country, device, browser and referrer. It takes the same ?days= and
+// ?offset= parameters as GetAnalytics; ?link_id= limits it to one link.
+func (h *DashboardHandler) GetLinkAnalytics(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	linksQuery := h.db.Select("id, title, type").Where("user_id = ? AND type <> ?", user.ID, models.LinkTypeHeader)
+	if value := c.Query("link_id"); value != "" {
+		linkID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, DashboardResponse{
+				Success: false,
+				Message: "Invalid link ID",
+			})
+			return
+		}
+		linksQuery = linksQuery.Where("id = ?", linkID)
+	}
+
+	var links []models.Link
+	if err := linksQuery.Order("\"order\" ASC, created_at ASC").Find(&links).Error; err != nil {
+		fmt.Printf("Failed to load links for analytics of user %d: %v\n", user.ID, err)
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to retrieve link analytics",
+		})
+		return
+	}
+	if len(links) == 0 && c.Query("link_id") != "" {
+		c.JSON(http.StatusNotFound, DashboardResponse{
+			Success: false,
+			Message: "Link not found",
+		})
+		return
+	}
+
+	days, _, startTime, endTime := analyticsTimeRange(c, user)
+	ids := make([]uint, len(links))
+	for i, link := range links {
+		ids[i] = link.ID
+	}
+
+	var rows []struct {
+		LinkID    uint
+		Dimension string
+		Value     string
+		Clicks    int
+	}
+	if len(ids) > 0 {
+		fromDay, toDay := rollupDays(startTime, endTime)
+		err := h.db.Model(&models.DailyLinkStat{}).
+			Select("link_id, dimension, value, SUM(clicks) AS clicks").
+			Where("user_id = ? AND link_id IN ? AND day BETWEEN ? AND ?", user.ID, ids, fromDay, toDay).
+			Group("link_id, dimension, value").
+			Scan(&rows).Error
+		if err != nil {
+			fmt.Printf("Failed to get link analytics for user %d: %v\n", user.ID, err)
+			c.JSON(http.StatusInternalServerError, DashboardResponse{
+				Success: false,
+				Message: "Failed to retrieve link analytics",
+			})
+			return
+		}
+	}
+
+	// Collect the rows per link and dimension
+	type dimensionCounts map[string]map[string]int
+	counts := make(map[uint]dimensionCounts, len(links))
+	for _, row := range rows {
+		if counts[row.LinkID] == nil {
+			counts[row.LinkID] = make(dimensionCounts)
+		}
+		if counts[row.LinkID][row.Dimension] == nil {
+			counts[row.LinkID][row.Dimension] = make(map[string]int)
+		}
+		counts[row.LinkID][row.Dimension][row.Value] += row.Clicks
+	}
+
+	breakdowns := make([]LinkBreakdown, 0, len(links))
+	for _, link := range links {
+		linkCounts := counts[link.ID]
+		total := linkCounts[models.RollupTotal][""]
+		breakdown := LinkBreakdown{
+			LinkID:    link.ID,
+			Title:     link.Title,
+			Type:      link.Type,
+			Clicks:    total,
+			Countries: breakdownItems(linkCounts[models.RollupCountry], total, "unknown"),
+			Devices:   breakdownItems(linkCounts[models.RollupDevice], total, "unknown"),
+			Browsers:  breakdownItems(linkCounts[models.RollupBrowser], total, "unknown"),
+			Referrers: breakdownItems(linkCounts[models.RollupReferrer], total, "direct"),
+		}
+		for i := range breakdown.Countries {
+			if breakdown.Countries[i].Name != "unknown" {
+				breakdown.Countries[i].Code = getCountryCode(breakdown.Countries[i].Name)
+			}
+		}
+		breakdowns = append(breakdowns, breakdown)
+	}
+
+	// Most clicked links first
+	sort.SliceStable(breakdowns, func(i, j int) bool {
+		return breakdowns[i].Clicks > breakdowns[j].Clicks
+	})
+
+	c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Link analytics retrieved successfully",
+		Data: gin.H{
+			"links":      breakdowns,
+			"days":       days,
+			"start_time": startTime,
+			"end_time":   endTime,
+		},
+	})
+}
+
+// breakdownItems turns the click counts of a dimension into its most clicked
+// values; the empty value is reported as emptyName
+func breakdownItems(counts map[string]int, total int, emptyName string) []BreakdownItem {
+	items := make([]BreakdownItem, 0, len(counts))
+	for value, clicks := range counts {
+		if clicks == 0 {
+			continue
+		}
+		name := value
+		if name == "" {
+			name = emptyName
+		}
+		item := BreakdownItem{Name: name, Clicks: clicks}
+		if total > 0 {
+			item.Percentage = float64(clicks) / float64(total) * 100
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Clicks != items[j].Clicks {
+			return items[i].Clicks > items[j].Clicks
+		}
+		return items[i].Name < items[j].Name
+	})
+	if len(items) > maxBreakdownItems {
+		items = items[:maxBreakdownItems]
+	}
+	return items
+}
@@ -0,0 +1,529 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"gotchu-backend/internal/middleware"
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/linkio"
+	"gotchu-backend/pkg/urlpolicy"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxLinkImportSize limits the size of an uploaded link list (2MB)
+const maxLinkImportSize = 2 << 20
+
+// errLinkLimitExceeded is returned when new links would take a user over their plan's limit
+var errLinkLimitExceeded = errors.New("link limit exceeded")
+
+// LinkImportSkip is a row left out of an import
+type LinkImportSkip struct {
+	Row    int    `json:"row"`
+	Title  string `json:"title,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// LinkImportResult describes what an import added, or would add on a dry run
+type LinkImportResult struct {
+	Format        linkio.Format    `json:"format"`
+	DryRun        bool             `json:"dry_run"`
+	Total         int              `json:"total"`
+	Links         []models.Link    `json:"links"`
+	Duplicates    []LinkImportSkip `json:"duplicates"`
+	Invalid       []LinkImportSkip `json:"invalid"`
+	ExistingLinks int              `json:"existing_links"`
+	LinkLimit     int              `json:"link_limit"` // 0 means unlimited
+	WithinLimit   bool             `json:"within_limit"`
+
+	sections map[int]int // index in Links of each sectioned link's header
+}
+
+// ImportLinks appends links from a CSV, JSON or HTML bookmarks file. The file
+// is the request body or a multipart "file" field; ?format= overrides format
+// detection and ?dry_run=true previews the result without saving anything.
+func (h *LinkHandler) ImportLinks(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, LinkResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   "UNAUTHORIZED",
+		})
+		return
+	}
+
+	body, format, err := readLinkImport(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: err.Error(),
+			Error:   "INVALID_IMPORT",
+		})
+		return
+	}
+
+	records, err := linkio.Read(format, bytes.NewReader(body))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: err.Error(),
+			Error:   "INVALID_IMPORT",
+		})
+		return
+	}
+	if len(records) == 0 {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "No links found in the file",
+			Error:   "INVALID_IMPORT",
+		})
+		return
+	}
+
+	// URLs are screened up front since the policy may call out to lookup
+	// services, which must not happen while the user row is locked
+	rejected := screenLinkImport(c.Request.Context(), h.urlPolicy, records)
+
+	dryRun := c.Query("dry_run") == "true" || c.Query("dry_run") == "1"
+	if dryRun {
+		result, err := planLinkImport(h.db, user, h.config.LinkLimit(user.Plan), records, rejected)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, LinkResponse{
+				Success: false,
+				Message: "Failed to retrieve links",
+				Error:   "DATABASE_ERROR",
+			})
+			return
+		}
+		result.Format = format
+		result.DryRun = true
+
+		c.JSON(http.StatusOK, LinkResponse{
+			Success: true,
+			Message: "Link import preview generated",
+			Data:    result,
+		})
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to start transaction",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := lockUserLinks(tx, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to import links",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	result, err := planLinkImport(tx, user, h.config.LinkLimit(user.Plan), records, rejected)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to retrieve links",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+	result.Format = format
+
+	if !result.WithinLimit {
+		c.JSON(http.StatusForbidden, LinkResponse{
+			Success: false,
+			Message: fmt.Sprintf("Importing %d links would exceed your plan's limit of %d links", len(result.Links), result.LinkLimit),
+			Data:    result,
+			Error:   "LINK_LIMIT_EXCEEDED",
+		})
+		return
+	}
+
+	if len(result.Links) > 0 {
+		err := tx.CreateInBatches(&result.Links, 100).Error
+		if err == nil {
+			err = assignImportSections(tx, result)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, LinkResponse{
+				Success: false,
+				Message: "Failed to import links",
+				Error:   "DATABASE_ERROR",
+			})
+			return
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to commit changes",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	h.clearUserLinksCache(user.ID)
+	if h.scheduler != nil {
+		for i := range result.Links {
+			h.scheduler.ScheduleLink(&result.Links[i])
+		}
+	}
+
+	c.JSON(http.StatusCreated, LinkResponse{
+		Success: true,
+		Message: fmt.Sprintf("Imported %d links", len(result.Links)),
+		Data:    result,
+	})
+}
+
+// ExportLinks downloads the authenticated user's links as CSV, JSON or HTML bookmarks
+func (h *LinkHandler) ExportLinks(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, LinkResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   "UNAUTHORIZED",
+		})
+		return
+	}
+
+	format, err := linkio.ParseFormat(c.DefaultQuery("format", string(linkio.FormatCSV)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: err.Error(),
+			Error:   "INVALID_FORMAT",
+		})
+		return
+	}
+
+	var links []models.Link
+	if err := h.db.Where("user_id = ?", user.ID).Order("\"order\" ASC, created_at ASC").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to retrieve links",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+	// Headers are written before their section's links, which is how an
+	// import puts them back in the section
+	links = flattenLinkSections(links, true)
+
+	records := make([]linkio.Record, 0, len(links))
+	for _, link := range links {
+		records = append(records, linkRecord(link))
+	}
+
+	var buf bytes.Buffer
+	if err := linkio.Write(format, &buf, records); err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to export links",
+			Error:   "EXPORT_ERROR",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"gotchu-%s-links.%s\"", user.Username, format))
+	c.Data(http.StatusOK, format.ContentType(), buf.Bytes())
+}
+
+// readLinkImport reads the uploaded file and works out its format
+func readLinkImport(c *gin.Context) ([]byte, linkio.Format, error) {
+	reader := io.Reader(c.Request.Body)
+	contentType := c.ContentType()
+	fileName := ""
+
+	if strings.HasPrefix(contentType, "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", fmt.Errorf("missing file upload")
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read the uploaded file")
+		}
+		defer file.Close()
+		reader = file
+		contentType = fileHeader.Header.Get("Content-Type")
+		fileName = fileHeader.Filename
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, maxLinkImportSize+1))
+	if err != nil || len(body) == 0 {
+		return nil, "", fmt.Errorf("import file is missing")
+	}
+	if len(body) > maxLinkImportSize {
+		return nil, "", fmt.Errorf("import file is larger than %d MB", maxLinkImportSize>>20)
+	}
+
+	if name := c.Query("format"); name != "" {
+		format, err := linkio.ParseFormat(name)
+		return body, format, err
+	}
+	format, ok := linkio.DetectFormat(contentType, fileName)
+	if !ok {
+		return nil, "", fmt.Errorf("could not detect the file format; pass ?format=csv, json or html")
+	}
+	return body, format, nil
+}
+
+// screenLinkImport checks the URLs of valid records against the URL policy
+// and returns why each rejected record was rejected, by index
+func screenLinkImport(ctx context.Context, policy *urlpolicy.Policy, records []linkio.Record) map[int]string {
+	rejected := make(map[int]string)
+	for i, record := range records {
+		link, reason := linkFromRecord(record)
+		if reason != "" || link.URL == nil {
+			continue
+		}
+		if err := policy.Check(ctx, *link.URL); err != nil {
+			rejected[i] = err.Error()
+		}
+	}
+	return rejected
+}
+
+// planLinkImport validates records, skips the ones screenLinkImport rejected,
+// drops ones whose URL the user already has and numbers the rest after the
+// user's last link, keeping file order. Links that follow a header in the
+// file go into that header's section. linkLimit is the user's plan limit, 0
+// for none.
+func planLinkImport(db *gorm.DB, user *models.User, linkLimit int, records []linkio.Record, rejected map[int]string) (*LinkImportResult, error) {
+	var existing []models.Link
+	if err := db.Select("id, url, \"order\", section_id").Where("user_id = ?", user.ID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(existing))
+	maxOrder := 0
+	for _, link := range existing {
+		if link.URL != nil && *link.URL != "" {
+			seen[linkio.NormalizeURL(*link.URL)] = true
+		}
+		if link.SectionID == nil && link.Order > maxOrder {
+			maxOrder = link.Order
+		}
+	}
+
+	result := &LinkImportResult{
+		Total:         len(records),
+		Links:         []models.Link{},
+		Duplicates:    []LinkImportSkip{},
+		Invalid:       []LinkImportSkip{},
+		ExistingLinks: len(existing),
+		LinkLimit:     linkLimit,
+		sections:      make(map[int]int),
+	}
+
+	header, sectionOrder := -1, 0
+	for i, record := range records {
+		if models.LinkType(record.Type) == models.LinkTypeHeader {
+			header = -1
+		}
+
+		link, reason := linkFromRecord(record)
+		if reason == "" {
+			reason = rejected[i]
+		}
+		if reason != "" {
+			result.Invalid = append(result.Invalid, LinkImportSkip{Row: record.Row, Title: record.Title, URL: record.URL, Reason: reason})
+			continue
+		}
+
+		if link.URL != nil {
+			key := linkio.NormalizeURL(*link.URL)
+			if seen[key] {
+				result.Duplicates = append(result.Duplicates, LinkImportSkip{Row: record.Row, Title: link.Title, URL: *link.URL, Reason: "You already have a link to this URL"})
+				continue
+			}
+			seen[key] = true
+		}
+
+		link.UserID = user.ID
+		switch {
+		case link.Type == models.LinkTypeHeader:
+			maxOrder++
+			link.Order = maxOrder
+			header, sectionOrder = len(result.Links), 0
+		case header >= 0:
+			sectionOrder++
+			link.Order = sectionOrder
+			result.sections[len(result.Links)] = header
+		default:
+			maxOrder++
+			link.Order = maxOrder
+		}
+		result.Links = append(result.Links, link)
+	}
+
+	result.WithinLimit = withinLinkLimit(linkLimit, result.ExistingLinks, len(result.Links))
+	return result, nil
+}
+
+// withinLinkLimit reports whether a user with existing links may add more
+// under a limit of 0 (none) or more links
+func withinLinkLimit(limit, existing, adding int) bool {
+	return limit == 0 || existing+adding <= limit
+}
+
+// lockUserLinks locks the user row for the rest of tx so concurrent requests
+// creating links cannot both pass the link limit
+func lockUserLinks(tx *gorm.DB, userID uint) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", userID).First(&models.User{}).Error
+}
+
+// reserveLinks checks, inside the transaction creating them, that adding
+// links keeps a user within a limit of 0 (none) or more links. It returns
+// errLinkLimitExceeded if it would not.
+func reserveLinks(tx *gorm.DB, userID uint, limit, adding int) error {
+	if limit == 0 {
+		return nil
+	}
+	if err := lockUserLinks(tx, userID); err != nil {
+		return err
+	}
+	var existing int64
+	if err := tx.Model(&models.Link{}).Where("user_id = ?", userID).Count(&existing).Error; err != nil {
+		return err
+	}
+	if !withinLinkLimit(limit, int(existing), adding) {
+		return errLinkLimitExceeded
+	}
+	return nil
+}
+
+// assignImportSections points imported links at their headers once the
+// headers have IDs
+func assignImportSections(tx *gorm.DB, result *LinkImportResult) error {
+	members := make(map[uint][]uint)
+	for index, header := range result.sections {
+		headerID := result.Links[header].ID
+		members[headerID] = append(members[headerID], result.Links[index].ID)
+		result.Links[index].SectionID = &headerID
+	}
+	for headerID, ids := range members {
+		if err := tx.Model(&models.Link{}).Where("id IN ?", ids).UpdateColumn("section_id", headerID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkFromRecord converts an imported record to a link, or explains why it is invalid.
+// Optional fields that fail validation are dropped rather than rejecting the row.
+func linkFromRecord(record linkio.Record) (models.Link, string) {
+	linkType := models.LinkType(record.Type)
+	switch linkType {
+	case "":
+		linkType = models.LinkTypeDefault
+	case models.LinkTypeDefault, models.LinkTypeHeader, models.LinkTypeProduct, models.LinkTypeService, models.LinkTypeMarketplace:
+	default:
+		return models.Link{}, fmt.Sprintf("Unknown link type %q", record.Type)
+	}
+
+	link := models.Link{
+		Title:    truncateRunes(strings.TrimSpace(record.Title), 255),
+		Type:     linkType,
+		IsActive: record.IsActive == nil || *record.IsActive,
+	}
+
+	if linkType != models.LinkTypeHeader {
+		target := strings.TrimSpace(record.URL)
+		if target == "" {
+			return models.Link{}, "URL is required"
+		}
+		if len(target) > 1000 || !isRedirectableURL(target) {
+			return models.Link{}, "URL must be an http, https, mailto or tel link under 1000 characters"
+		}
+		parsed, _ := url.Parse(target)
+		if (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Hostname() == "" {
+			return models.Link{}, "URL has no host"
+		}
+		link.URL = &target
+
+		if link.Title == "" {
+			link.Title = strings.TrimPrefix(parsed.Hostname(), "www.")
+			if link.Title == "" {
+				link.Title = parsed.Opaque
+			}
+		}
+	}
+	if link.Title == "" {
+		return models.Link{}, "Title is required"
+	}
+
+	if description := strings.TrimSpace(record.Description); description != "" {
+		description = truncateRunes(description, 1000)
+		link.Description = &description
+	}
+	if icon := strings.TrimSpace(record.Icon); icon != "" && len(icon) <= 500 {
+		link.Icon = &icon
+	}
+	if imageURL := strings.TrimSpace(record.ImageURL); imageURL != "" && len(imageURL) <= 500 {
+		if parsed, err := url.Parse(imageURL); err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") {
+			link.ImageURL = &imageURL
+		}
+	}
+	if color := strings.TrimSpace(record.Color); isValidHexColor(color) {
+		link.Color = &color
+	}
+	return link, ""
+}
+
+// linkRecord converts a link to an export record
+func linkRecord(link models.Link) linkio.Record {
+	isActive := link.IsActive
+	record := linkio.Record{
+		Title:    link.Title,
+		Type:     string(link.Type),
+		IsActive: &isActive,
+	}
+	if link.URL != nil {
+		record.URL = *link.URL
+	}
+	if link.Description != nil {
+		record.Description = *link.Description
+	}
+	if link.Icon != nil {
+		record.Icon = *link.Icon
+	}
+	if link.ImageURL != nil {
+		record.ImageURL = *link.ImageURL
+	}
+	if link.Color != nil {
+		record.Color = *link.Color
+	}
+	return record
+}
+
+// truncateRunes shortens s to at most max characters
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
@@ -1,678 +1,1317 @@
-package handlers
-
-import (
-	"fmt"
-	"net/http"
-	"strconv"
-	"time"
-
-	"gotchu-backend/internal/middleware"
-	"gotchu-backend/internal/models"
-	"gotchu-backend/pkg/redis"
-
-	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
-)
-
-// LinkHandler handles link-related endpoints
-type LinkHandler struct {
-	db          *gorm.DB
-	redisClient *redis.Client
-}
-
-// NewLinkHandler creates a new link handler
-func NewLinkHandler(db *gorm.DB, redisClient *redis.Client) *LinkHandler {
-	return &LinkHandler{
-		db:          db,
-		redisClient: redisClient,
-	}
-}
-
-// LinkResponse represents standard link response format
-type LinkResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-}
-
-// CreateLinkRequest represents the request payload for creating a link
-type CreateLinkRequest struct {
-	Title       string                `json:"title" binding:"required,min=1,max=255"`
-	URL         *string               `json:"url" binding:"omitempty,url"`
-	Description *string               `json:"description" binding:"omitempty,max=1000"`
-	Type        models.LinkType       `json:"type" binding:"omitempty,oneof=DEFAULT HEADER PRODUCT SERVICE MARKETPLACE"`
-	Icon        *string               `json:"icon" binding:"omitempty,max=100"`
-	ImageURL    *string               `json:"image_url" binding:"omitempty,url"`
-	Color       *string               `json:"color" binding:"omitempty,len=7"`
-	IsActive    *bool                 `json:"is_active"`
-}
-
-// UpdateLinkRequest represents the request payload for updating a link
-type UpdateLinkRequest struct {
-	Title       *string               `json:"title" binding:"omitempty,min=1,max=255"`
-	URL         *string               `json:"url" binding:"omitempty,url"`
-	Description *string               `json:"description" binding:"omitempty,max=1000"`
-	Type        *models.LinkType      `json:"type" binding:"omitempty,oneof=DEFAULT HEADER PRODUCT SERVICE MARKETPLACE"`
-	Icon        *string               `json:"icon" binding:"omitempty,max=100"`
-	ImageURL    *string               `json:"image_url" binding:"omitempty,url"`
-	Color       *string               `json:"color" binding:"omitempty,len=7"`
-	IsActive    *bool                 `json:"is_active"`
-	Order       *int                  `json:"order"`
-}
-
-// GetLinks retrieves all links for the authenticated user
-func (h *LinkHandler) GetLinks(c *gin.Context) {
-	user, exists := middleware.GetCurrentUser(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, LinkResponse{
-			Success: false,
-			Message: "Authentication required",
-			Error:   "UNAUTHORIZED",
-		})
-		return
-	}
-
-	// Fetch from database directly (no caching for links)
-	var links []models.Link
-	err := h.db.Where("user_id = ?", user.ID).
-		Order("\"order\" ASC, created_at ASC").
-		Find(&links).Error
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to retrieve links",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	// Ensure links is never nil
-	if links == nil {
-		links = []models.Link{}
-	}
-
-	c.JSON(http.StatusOK, LinkResponse{
-		Success: true,
-		Message: "Links retrieved successfully",
-		Data: gin.H{
-			"links": links,
-		},
-	})
-}
-
-// GetLink retrieves a specific link by ID
-func (h *LinkHandler) GetLink(c *gin.Context) {
-	user, exists := middleware.GetCurrentUser(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, LinkResponse{
-			Success: false,
-			Message: "Authentication required",
-			Error:   "UNAUTHORIZED",
-		})
-		return
-	}
-
-	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, LinkResponse{
-			Success: false,
-			Message: "Invalid link ID",
-			Error:   "INVALID_ID",
-		})
-		return
-	}
-
-	var link models.Link
-	err = h.db.Where("id = ? AND user_id = ?", linkID, user.ID).First(&link).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, LinkResponse{
-				Success: false,
-				Message: "Link not found",
-				Error:   "NOT_FOUND",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to retrieve link",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, LinkResponse{
-		Success: true,
-		Message: "Link retrieved successfully",
-		Data: gin.H{
-			"link": link,
-		},
-	})
-}
-
-// CreateLink creates a new link for the authenticated user
-func (h *LinkHandler) CreateLink(c *gin.Context) {
-	user, exists := middleware.GetCurrentUser(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, LinkResponse{
-			Success: false,
-			Message: "Authentication required",
-			Error:   "UNAUTHORIZED",
-		})
-		return
-	}
-
-	var req CreateLinkRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, LinkResponse{
-			Success: false,
-			Message: "Invalid request data: " + err.Error(),
-			Error:   "VALIDATION_ERROR",
-		})
-		return
-	}
-
-	// Validate color format if provided
-	if req.Color != nil && !isValidHexColor(*req.Color) {
-		c.JSON(http.StatusBadRequest, LinkResponse{
-			Success: false,
-			Message: "Invalid color format. Use hex format like #FF0000",
-			Error:   "INVALID_COLOR",
-		})
-		return
-	}
-
-	// Check for duplicate platform (except Custom URL which can have multiple)
-	if req.Icon != nil && *req.Icon != "link" {
-		var existingLink models.Link
-		err := h.db.Where("user_id = ? AND icon = ? AND is_active = ?", user.ID, *req.Icon, true).First(&existingLink).Error
-		if err == nil {
-			c.JSON(http.StatusBadRequest, LinkResponse{
-				Success: false,
-				Message: "You already have a link for this platform. Each platform can only be added once.",
-				Error:   "DUPLICATE_PLATFORM",
-			})
-			return
-		}
-	}
-
-	// Get the next order number
-	var maxOrder int
-	h.db.Model(&models.Link{}).
-		Where("user_id = ?", user.ID).
-		Select("COALESCE(MAX(\"order\"), 0)").
-		Scan(&maxOrder)
-
-	// Set default values
-	linkType := models.LinkTypeDefault
-	if req.Type != "" {
-		linkType = req.Type
-	}
-
-	isActive := true
-	if req.IsActive != nil {
-		isActive = *req.IsActive
-	}
-
-	// Create the link
-	link := models.Link{
-		Title:       req.Title,
-		URL:         req.URL,
-		Description: req.Description,
-		Type:        linkType,
-		Icon:        req.Icon,
-		ImageURL:    req.ImageURL,
-		Color:       req.Color,
-		IsActive:    isActive,
-		Order:       maxOrder + 1,
-		UserID:      user.ID,
-		Clicks:      0,
-	}
-
-	if err := h.db.Create(&link).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to create link",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	// No cache to clear since we removed caching
-
-	c.JSON(http.StatusCreated, LinkResponse{
-		Success: true,
-		Message: "Link created successfully",
-		Data: gin.H{
-			"link": link,
-		},
-	})
-}
-
-// UpdateLink updates an existing link
-func (h *LinkHandler) UpdateLink(c *gin.Context) {
-	user, exists := middleware.GetCurrentUser(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, LinkResponse{
-			Success: false,
-			Message: "Authentication required",
-			Error:   "UNAUTHORIZED",
-		})
-		return
-	}
-
-	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, LinkResponse{
-			Success: false,
-			Message: "Invalid link ID",
-			Error:   "INVALID_ID",
-		})
-		return
-	}
-
-	var req UpdateLinkRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, LinkResponse{
-			Success: false,
-			Message: "Invalid request data: " + err.Error(),
-			Error:   "VALIDATION_ERROR",
-		})
-		return
-	}
-
-	// Validate color format if provided
-	if req.Color != nil && !isValidHexColor(*req.Color) {
-		c.JSON(http.StatusBadRequest, LinkResponse{
-			Success: false,
-			Message: "Invalid color format. Use hex format like #FF0000",
-			Error:   "INVALID_COLOR",
-		})
-		return
-	}
-
-	// Find the link
-	var link models.Link
-	err = h.db.Where("id = ? AND user_id = ?", linkID, user.ID).First(&link).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, LinkResponse{
-				Success: false,
-				Message: "Link not found",
-				Error:   "NOT_FOUND",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to find link",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	// Prepare updates
-	updates := make(map[string]interface{})
-	if req.Title != nil {
-		updates["title"] = *req.Title
-	}
-	if req.URL != nil {
-		updates["url"] = *req.URL
-	}
-	if req.Description != nil {
-		updates["description"] = *req.Description
-	}
-	if req.Type != nil {
-		updates["type"] = *req.Type
-	}
-	if req.Icon != nil {
-		updates["icon"] = *req.Icon
-	}
-	if req.ImageURL != nil {
-		updates["image_url"] = *req.ImageURL
-	}
-	if req.Color != nil {
-		updates["color"] = *req.Color
-	}
-	if req.IsActive != nil {
-		updates["is_active"] = *req.IsActive
-	}
-	if req.Order != nil {
-		updates["\"order\""] = *req.Order
-	}
-	updates["updated_at"] = time.Now()
-
-	// Update the link
-	err = h.db.Model(&link).Updates(updates).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to update link",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	// Fetch updated link
-	err = h.db.Where("id = ?", linkID).First(&link).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to retrieve updated link",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	// No cache to clear since we removed caching
-
-	c.JSON(http.StatusOK, LinkResponse{
-		Success: true,
-		Message: "Link updated successfully",
-		Data: gin.H{
-			"link": link,
-		},
-	})
-}
-
-// DeleteLink deletes a link
-func (h *LinkHandler) DeleteLink(c *gin.Context) {
-	user, exists := middleware.GetCurrentUser(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, LinkResponse{
-			Success: false,
-			Message: "Authentication required",
-			Error:   "UNAUTHORIZED",
-		})
-		return
-	}
-
-	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, LinkResponse{
-			Success: false,
-			Message: "Invalid link ID",
-			Error:   "INVALID_ID",
-		})
-		return
-	}
-
-	// Check if link exists and belongs to user
-	var link models.Link
-	err = h.db.Where("id = ? AND user_id = ?", linkID, user.ID).First(&link).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, LinkResponse{
-				Success: false,
-				Message: "Link not found",
-				Error:   "NOT_FOUND",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to find link",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	// Start transaction for safe deletion
-	tx := h.db.Begin()
-	if tx.Error != nil {
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to start transaction",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-	defer tx.Rollback()
-
-	// First delete all related link clicks
-	err = tx.Where("link_id = ?", linkID).Delete(&models.LinkClick{}).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to delete link analytics",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	// Then delete the link
-	err = tx.Delete(&link).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to delete link",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to commit deletion",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	// No cache to clear since we removed caching
-
-	c.JSON(http.StatusOK, LinkResponse{
-		Success: true,
-		Message: "Link deleted successfully",
-	})
-}
-
-// TrackClick records a click on a link and increments the click counter
-func (h *LinkHandler) TrackClick(c *gin.Context) {
-	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, LinkResponse{
-			Success: false,
-			Message: "Invalid link ID",
-			Error:   "INVALID_ID",
-		})
-		return
-	}
-
-	// Find the link
-	var link models.Link
-	err = h.db.Where("id = ? AND is_active = ?", linkID, true).First(&link).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, LinkResponse{
-				Success: false,
-				Message: "Link not found or inactive",
-				Error:   "NOT_FOUND",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to find link",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	// Extract analytics data from request
-	ipAddress := c.ClientIP()
-	userAgent := c.GetHeader("User-Agent")
-	referer := c.GetHeader("Referer")
-	sessionID := c.GetHeader("X-Session-ID")
-
-	// Create click record
-	linkClick := models.LinkClick{
-		LinkID:    uint(linkID),
-		IPAddress: &ipAddress,
-		UserAgent: &userAgent,
-		Referer:   &referer,
-		SessionID: &sessionID,
-		// TODO: Add geolocation data (Country, City) using IP service
-		// TODO: Add device/browser detection
-	}
-
-	// Save click record (database triggers will automatically update counters)
-	err = h.db.Create(&linkClick).Error
-	if err != nil {
-		// Log error but don't fail the request
-		fmt.Printf("Failed to save click analytics for link %d: %v\n", linkID, err)
-	}
-
-	// No cache to clear since we removed caching
-
-	c.JSON(http.StatusOK, LinkResponse{
-		Success: true,
-		Message: "Click tracked successfully",
-		Data: gin.H{
-			"link_id": linkID,
-		},
-	})
-}
-
-// ReorderLinks updates the order of multiple links
-func (h *LinkHandler) ReorderLinks(c *gin.Context) {
-	user, exists := middleware.GetCurrentUser(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, LinkResponse{
-			Success: false,
-			Message: "Authentication required",
-			Error:   "UNAUTHORIZED",
-		})
-		return
-	}
-
-	type ReorderRequest struct {
-		Links []struct {
-			ID    uint `json:"id" binding:"required"`
-			Order int  `json:"order" binding:"required"`
-		} `json:"links" binding:"required"`
-	}
-
-	var req ReorderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, LinkResponse{
-			Success: false,
-			Message: "Invalid request data: " + err.Error(),
-			Error:   "VALIDATION_ERROR",
-		})
-		return
-	}
-
-	// Start transaction
-	tx := h.db.Begin()
-	if tx.Error != nil {
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to start transaction",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-	defer tx.Rollback()
-
-	// Update each link's order
-	for _, linkOrder := range req.Links {
-		err := tx.Model(&models.Link{}).
-			Where("id = ? AND user_id = ?", linkOrder.ID, user.ID).
-			Update("\"order\"", linkOrder.Order).Error
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, LinkResponse{
-				Success: false,
-				Message: "Failed to update link order",
-				Error:   "DATABASE_ERROR",
-			})
-			return
-		}
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to commit changes",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	// No cache to clear since we removed caching
-
-	c.JSON(http.StatusOK, LinkResponse{
-		Success: true,
-		Message: "Links reordered successfully",
-	})
-}
-
-// GetPublicUserLinks retrieves all active links for a public user by username
-func (h *LinkHandler) GetPublicUserLinks(c *gin.Context) {
-	username := c.Param("username")
-	if username == "" {
-		c.JSON(http.StatusBadRequest, LinkResponse{
-			Success: false,
-			Message: "Username is required",
-			Error:   "INVALID_USERNAME",
-		})
-		return
-	}
-
-	// Skip cache and get fresh data directly from database
-	var user models.User
-	err := h.db.Where("username = ? AND is_active = ? AND is_public = ?", username, true, true).First(&user).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, LinkResponse{
-				Success: false,
-				Message: "User not found or profile is private",
-				Error:   "USER_NOT_FOUND",
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, LinkResponse{
-				Success: false,
-				Message: "Failed to find user",
-				Error:   "DATABASE_ERROR",
-			})
-		}
-		return
-	}
-
-	// Get user's active links
-	var links []models.Link
-	err = h.db.Where("user_id = ? AND is_active = ?", user.ID, true).
-		Order("\"order\" ASC, created_at ASC").
-		Find(&links).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, LinkResponse{
-			Success: false,
-			Message: "Failed to retrieve links",
-			Error:   "DATABASE_ERROR",
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, LinkResponse{
-		Success: true,
-		Message: "Links retrieved successfully",
-		Data: gin.H{
-			"links": links,
-		},
-	})
-}
-
-// Note: isValidHexColor function is imported from dashboard.go
-
-// Helper function to clear user links cache
-func (h *LinkHandler) clearUserLinksCache(userID uint) {
-	if h.redisClient != nil {
-		cacheKey := fmt.Sprintf("links:user:%d", userID)
-		h.redisClient.Delete(cacheKey)
-	}
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotchu-backend/internal/config"
+	"gotchu-backend/internal/middleware"
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/analytics"
+	"gotchu-backend/pkg/botfilter"
+	"gotchu-backend/pkg/ingest"
+	"gotchu-backend/pkg/linkschedule"
+	"gotchu-backend/pkg/redis"
+	"gotchu-backend/pkg/storage"
+	"gotchu-backend/pkg/unfurl"
+	"gotchu-backend/pkg/urlpolicy"
+	"gotchu-backend/pkg/workers"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LinkHandler handles link-related endpoints
+type LinkHandler struct {
+	db            *gorm.DB
+	redisClient   *redis.Client
+	profileAccess *ProfileAccess
+	workerPool    *workers.WorkerPool
+	config        *config.Config
+	scheduler     *linkschedule.Scheduler
+	geoService    *analytics.GeoLocationService
+	unfurler      *unfurl.Service
+	urlPolicy     *urlpolicy.Policy
+	ingest        *ingest.Pipeline
+	bots          *botfilter.Filter
+}
+
+// NewLinkHandler creates a new link handler
+func NewLinkHandler(db *gorm.DB, redisClient *redis.Client, profileAccess *ProfileAccess, workerPool *workers.WorkerPool, cfg *config.Config, scheduler *linkschedule.Scheduler, supabaseStorage *storage.SupabaseStorage, urlPolicy *urlpolicy.Policy, ingestPipeline *ingest.Pipeline, geoService *analytics.GeoLocationService) *LinkHandler {
+	return &LinkHandler{
+		db:            db,
+		redisClient:   redisClient,
+		profileAccess: profileAccess,
+		workerPool:    workerPool,
+		config:        cfg,
+		scheduler:     scheduler,
+		geoService:    geoService,
+		unfurler:      unfurl.NewService(nil, supabaseStorage, redisClient),
+		urlPolicy:     urlPolicy,
+		ingest:        ingestPipeline,
+		bots:          botfilter.NewFilter(redisClient, botfilter.Config{RateLimit: cfg.BotRateLimit}),
+	}
+}
+
+// liveLinkCondition restricts a links query to links inside their visibility window;
+// it takes the current time twice
+const liveLinkCondition = "(visible_from IS NULL OR visible_from <= ?) AND (visible_until IS NULL OR visible_until > ?)"
+
+// publicLinksCacheTTL is the longest a user's public links are cached; entries
+// expire earlier when a scheduled link is about to go live or expire
+const publicLinksCacheTTL = 5 * time.Minute
+
+// unfurlTimeout bounds how long link creation waits for a preview
+const unfurlTimeout = 10 * time.Second
+
+// slugPattern restricts custom short-link slugs to URL-safe characters
+var slugPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{2,63}$`)
+
+// LinkResponse represents standard link response format
+type LinkResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// CreateLinkRequest represents the request payload for creating a link
+type CreateLinkRequest struct {
+	Title        string                `json:"title" binding:"omitempty,max=255"`
+	URL          *string               `json:"url" binding:"omitempty,url"`
+	Description  *string               `json:"description" binding:"omitempty,max=1000"`
+	Type         models.LinkType       `json:"type" binding:"omitempty,oneof=DEFAULT HEADER PRODUCT SERVICE MARKETPLACE"`
+	Icon         *string               `json:"icon" binding:"omitempty,max=500"`
+	ImageURL     *string               `json:"image_url" binding:"omitempty,url"`
+	Color        *string               `json:"color" binding:"omitempty,len=7"`
+	IsActive     *bool                 `json:"is_active"`
+	Slug         *string               `json:"slug" binding:"omitempty,max=64"`
+	VisibleFrom  *string               `json:"visible_from"`
+	VisibleUntil *string               `json:"visible_until"`
+	Targeting    *models.LinkTargeting `json:"targeting"`
+	Sensitivity  *string               `json:"sensitivity" binding:"omitempty,oneof=none nsfw spoiler"`
+	SectionID    *uint                 `json:"section_id"` // HEADER link to add the link to
+	Collapsed    *bool                 `json:"collapsed"`  // HEADER links only
+	Commerce     *models.LinkCommerce  `json:"commerce"`   // PRODUCT, SERVICE and MARKETPLACE links only
+}
+
+// UpdateLinkRequest represents the request payload for updating a link
+type UpdateLinkRequest struct {
+	Title        *string               `json:"title" binding:"omitempty,min=1,max=255"`
+	URL          *string               `json:"url" binding:"omitempty,url"`
+	Description  *string               `json:"description" binding:"omitempty,max=1000"`
+	Type         *models.LinkType      `json:"type" binding:"omitempty,oneof=DEFAULT HEADER PRODUCT SERVICE MARKETPLACE"`
+	Icon         *string               `json:"icon" binding:"omitempty,max=500"`
+	ImageURL     *string               `json:"image_url" binding:"omitempty,url"`
+	Color        *string               `json:"color" binding:"omitempty,len=7"`
+	IsActive     *bool                 `json:"is_active"`
+	Order        *int                  `json:"order"`
+	Slug         *string               `json:"slug" binding:"omitempty,max=64"`
+	VisibleFrom  *string               `json:"visible_from"`  // RFC 3339; empty string clears
+	VisibleUntil *string               `json:"visible_until"` // RFC 3339; empty string clears
+	Targeting    *models.LinkTargeting `json:"targeting"`     // empty object clears
+	Sensitivity  *string               `json:"sensitivity" binding:"omitempty,oneof=none nsfw spoiler"`
+	SectionID    *uint                 `json:"section_id"` // 0 moves the link to the top level
+	Collapsed    *bool                 `json:"collapsed"`  // HEADER links only
+	Commerce     *models.LinkCommerce  `json:"commerce"`   // empty object clears
+}
+
+// GetLinks retrieves all links for the authenticated user
+func (h *LinkHandler) GetLinks(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, LinkResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   "UNAUTHORIZED",
+		})
+		return
+	}
+
+	// Fetch from database directly (no caching for links)
+	var links []models.Link
+	err := h.db.Preload("Health").Where("user_id = ?", user.ID).
+		Order("\"order\" ASC, created_at ASC").
+		Find(&links).Error
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to retrieve links",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	// Each header is followed by its section's links
+	links = flattenLinkSections(links, true)
+
+	now := time.Now()
+	for i := range links {
+		links[i].ScheduleState = links[i].ScheduleStateAt(now)
+	}
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Links retrieved successfully",
+		Data: gin.H{
+			"links": links,
+		},
+	})
+}
+
+// GetLink retrieves a specific link by ID
+func (h *LinkHandler) GetLink(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, LinkResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   "UNAUTHORIZED",
+		})
+		return
+	}
+
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid link ID",
+			Error:   "INVALID_ID",
+		})
+		return
+	}
+
+	var link models.Link
+	err = h.db.Preload("Variants", orderVariants).Preload("Health").Where("id = ? AND user_id = ?", linkID, user.ID).First(&link).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, LinkResponse{
+				Success: false,
+				Message: "Link not found",
+				Error:   "NOT_FOUND",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to retrieve link",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+	link.ScheduleState = link.ScheduleStateAt(time.Now())
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Link retrieved successfully",
+		Data: gin.H{
+			"link": link,
+		},
+	})
+}
+
+// CreateLink creates a new link for the authenticated user
+func (h *LinkHandler) CreateLink(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, LinkResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   "UNAUTHORIZED",
+		})
+		return
+	}
+
+	var req CreateLinkRequest
+	var err error
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+			Error:   "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	// Validate color format if provided
+	if req.Color != nil && !isValidHexColor(*req.Color) {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid color format. Use hex format like #FF0000",
+			Error:   "INVALID_COLOR",
+		})
+		return
+	}
+
+	if req.Slug != nil && *req.Slug != "" {
+		if code, message := h.validateSlug(user, *req.Slug, 0); code != "" {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: message,
+				Error:   code,
+			})
+			return
+		}
+	} else {
+		req.Slug = nil
+	}
+
+	var visibleFrom, visibleUntil *time.Time
+	if req.VisibleFrom != nil {
+		if visibleFrom, err = parseScheduleTime(*req.VisibleFrom); err != nil {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: "Invalid visible_from. Use RFC 3339 format like 2024-01-02T15:04:05Z",
+				Error:   "INVALID_SCHEDULE",
+			})
+			return
+		}
+	}
+	if req.VisibleUntil != nil {
+		if visibleUntil, err = parseScheduleTime(*req.VisibleUntil); err != nil {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: "Invalid visible_until. Use RFC 3339 format like 2024-01-02T15:04:05Z",
+				Error:   "INVALID_SCHEDULE",
+			})
+			return
+		}
+	}
+	if visibleFrom != nil && visibleUntil != nil && !visibleUntil.After(*visibleFrom) {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "visible_until must be after visible_from",
+			Error:   "INVALID_SCHEDULE",
+		})
+		return
+	}
+
+	targeting, err := normalizeLinkTargeting(req.Targeting)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: err.Error(),
+			Error:   "INVALID_TARGETING",
+		})
+		return
+	}
+
+	if !h.checkLinkURLs(c, urlpolicy.LinkTargets(&models.Link{URL: req.URL, Targeting: targeting})) {
+		return
+	}
+
+	// Check for duplicate platform (except Custom URL which can have multiple)
+	if req.Icon != nil && *req.Icon != "link" {
+		var existingLink models.Link
+		err := h.db.Where("user_id = ? AND icon = ? AND is_active = ?", user.ID, *req.Icon, true).First(&existingLink).Error
+		if err == nil {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: "You already have a link for this platform. Each platform can only be added once.",
+				Error:   "DUPLICATE_PLATFORM",
+			})
+			return
+		}
+	}
+
+	// Fill in a missing title or icon from the target page
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" || req.Icon == nil {
+		h.applyLinkPreview(c.Request.Context(), &req)
+	}
+	if req.Title == "" {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Title is required for links without a URL",
+			Error:   "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	// Set default values
+	linkType := models.LinkTypeDefault
+	if req.Type != "" {
+		linkType = req.Type
+	}
+
+	commerce, err := normalizeLinkCommerce(linkType, req.Commerce)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: err.Error(),
+			Error:   "INVALID_COMMERCE",
+		})
+		return
+	}
+
+	var sectionID *uint
+	if req.SectionID != nil {
+		var err error
+		sectionID, err = resolveLinkSection(h.db, user.ID, linkType, *req.SectionID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: err.Error(),
+				Error:   "INVALID_SECTION",
+			})
+			return
+		}
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	sensitivity := models.LinkSensitivityNone
+	if req.Sensitivity != nil {
+		sensitivity, _ = models.ParseLinkSensitivity(*req.Sensitivity)
+	}
+
+	// Create the link
+	link := models.Link{
+		Title:       req.Title,
+		URL:         req.URL,
+		Description: req.Description,
+		Type:        linkType,
+		Icon:        req.Icon,
+		ImageURL:    req.ImageURL,
+		Color:       req.Color,
+		IsActive:    isActive,
+		Order:       nextLinkOrder(h.db, user.ID, sectionID),
+		SectionID:    sectionID,
+		Collapsed:    req.Collapsed != nil && *req.Collapsed,
+		Slug:         req.Slug,
+		VisibleFrom:  visibleFrom,
+		VisibleUntil: visibleUntil,
+		Targeting:    targeting,
+		Commerce:     commerce,
+		Sensitivity:  sensitivity,
+		UserID:       user.ID,
+		Clicks:       0,
+	}
+
+	if err := h.db.Create(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to create link",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	h.clearUserLinksCache(user.ID)
+	if h.scheduler != nil {
+		h.scheduler.ScheduleLink(&link)
+	}
+	link.ScheduleState = link.ScheduleStateAt(time.Now())
+
+	c.JSON(http.StatusCreated, LinkResponse{
+		Success: true,
+		Message: "Link created successfully",
+		Data: gin.H{
+			"link": link,
+		},
+	})
+}
+
+// UpdateLink updates an existing link
+func (h *LinkHandler) UpdateLink(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, LinkResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   "UNAUTHORIZED",
+		})
+		return
+	}
+
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid link ID",
+			Error:   "INVALID_ID",
+		})
+		return
+	}
+
+	var req UpdateLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+			Error:   "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	// Validate color format if provided
+	if req.Color != nil && !isValidHexColor(*req.Color) {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid color format. Use hex format like #FF0000",
+			Error:   "INVALID_COLOR",
+		})
+		return
+	}
+
+	// Find the link
+	var link models.Link
+	err = h.db.Where("id = ? AND user_id = ?", linkID, user.ID).First(&link).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, LinkResponse{
+				Success: false,
+				Message: "Link not found",
+				Error:   "NOT_FOUND",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to find link",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	// Prepare updates
+	updates := make(map[string]interface{})
+	if req.Title != nil {
+		updates["title"] = *req.Title
+	}
+	if req.URL != nil {
+		updates["url"] = *req.URL
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Type != nil {
+		updates["type"] = *req.Type
+	}
+	if req.Icon != nil {
+		updates["icon"] = *req.Icon
+	}
+	if req.ImageURL != nil {
+		updates["image_url"] = *req.ImageURL
+	}
+	if req.Color != nil {
+		updates["color"] = *req.Color
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if req.Order != nil {
+		updates["\"order\""] = *req.Order
+	}
+	if req.Slug != nil {
+		if *req.Slug == "" {
+			updates["slug"] = nil
+		} else if code, message := h.validateSlug(user, *req.Slug, link.ID); code != "" {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: message,
+				Error:   code,
+			})
+			return
+		} else {
+			updates["slug"] = *req.Slug
+		}
+	}
+
+	// Validate the schedule against whichever bound is not being changed
+	visibleFrom, visibleUntil := link.VisibleFrom, link.VisibleUntil
+	if req.VisibleFrom != nil {
+		if visibleFrom, err = parseScheduleTime(*req.VisibleFrom); err != nil {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: "Invalid visible_from. Use RFC 3339 format like 2024-01-02T15:04:05Z",
+				Error:   "INVALID_SCHEDULE",
+			})
+			return
+		}
+		updates["visible_from"] = visibleFrom
+	}
+	if req.VisibleUntil != nil {
+		if visibleUntil, err = parseScheduleTime(*req.VisibleUntil); err != nil {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: "Invalid visible_until. Use RFC 3339 format like 2024-01-02T15:04:05Z",
+				Error:   "INVALID_SCHEDULE",
+			})
+			return
+		}
+		updates["visible_until"] = visibleUntil
+	}
+	if visibleFrom != nil && visibleUntil != nil && !visibleUntil.After(*visibleFrom) {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "visible_until must be after visible_from",
+			Error:   "INVALID_SCHEDULE",
+		})
+		return
+	}
+	if req.Targeting != nil {
+		targeting, err := normalizeLinkTargeting(req.Targeting)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: err.Error(),
+				Error:   "INVALID_TARGETING",
+			})
+			return
+		}
+		if targeting == nil {
+			updates["targeting"] = nil
+		} else {
+			updates["targeting"] = *targeting
+		}
+	}
+	if req.Sensitivity != nil {
+		sensitivity, _ := models.ParseLinkSensitivity(*req.Sensitivity)
+		// A flag forced by an admin can only be changed by an admin
+		if link.SensitivityForced && sensitivity != link.Sensitivity {
+			c.JSON(http.StatusForbidden, LinkResponse{
+				Success: false,
+				Message: "This link was flagged by a moderator and its sensitivity cannot be changed",
+				Error:   "SENSITIVITY_LOCKED",
+			})
+			return
+		}
+		updates["sensitivity"] = sensitivity
+	}
+
+	// A link moved to another section goes last in it unless an order is
+	// given; a link that becomes a header moves to the top level, and a
+	// header that stops being one hands its links to the top level
+	newType := link.Type
+	if req.Type != nil {
+		newType = *req.Type
+	}
+	sectionID := link.SectionID
+	if req.SectionID != nil {
+		sectionID, err = resolveLinkSection(h.db, user.ID, newType, *req.SectionID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: err.Error(),
+				Error:   "INVALID_SECTION",
+			})
+			return
+		}
+	} else if newType == models.LinkTypeHeader {
+		sectionID = nil
+	}
+	if !sameLinkSection(sectionID, link.SectionID) {
+		updates["section_id"] = sectionID
+		if req.Order == nil {
+			updates["\"order\""] = nextLinkOrder(h.db, user.ID, sectionID)
+		}
+	}
+	releasing := link.Type == models.LinkTypeHeader && newType != models.LinkTypeHeader
+
+	// Commerce details are checked against the link's type, so a type change
+	// re-checks the existing ones and drops them from non-commerce types
+	commerce := req.Commerce
+	if commerce == nil && newType != link.Type && link.Commerce != nil {
+		commerce = link.Commerce
+		if !newType.IsCommerceType() {
+			commerce = &models.LinkCommerce{}
+		}
+	}
+	if commerce != nil {
+		normalized, err := normalizeLinkCommerce(newType, commerce)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: err.Error(),
+				Error:   "INVALID_COMMERCE",
+			})
+			return
+		}
+		if normalized == nil {
+			updates["commerce"] = nil
+		} else {
+			updates["commerce"] = *normalized
+		}
+	}
+	if req.Collapsed != nil {
+		updates["collapsed"] = *req.Collapsed
+	}
+
+	// Screen new targets. Turning a link the URL policy disabled back on
+	// screens everything it can redirect to, variants included.
+	reactivating := link.BlockedReason != nil && req.IsActive != nil && *req.IsActive
+	if req.URL != nil || req.Targeting != nil || reactivating {
+		candidate := link
+		if req.URL != nil {
+			candidate.URL = req.URL
+		}
+		if req.Targeting != nil {
+			candidate.Targeting, _ = normalizeLinkTargeting(req.Targeting)
+		}
+		if link.BlockedReason != nil {
+			h.db.Where("link_id = ?", link.ID).Find(&candidate.Variants)
+		}
+		if !h.checkLinkURLs(c, urlpolicy.LinkTargets(&candidate)) {
+			return
+		}
+		if link.BlockedReason != nil {
+			updates["blocked_reason"] = nil
+		}
+	}
+	updates["updated_at"] = time.Now()
+	urlChanged := req.URL != nil && (link.URL == nil || *link.URL != *req.URL)
+
+	// Update the link
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if releasing {
+			if err := releaseLinkSection(tx, &link); err != nil {
+				return err
+			}
+		}
+		if urlChanged {
+			// The health of the old URL says nothing about the new one; the
+			// checker picks the link up again on its next sweep
+			if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkHealth{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&link).Updates(updates).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to update link",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	// Fetch updated link
+	err = h.db.Where("id = ?", linkID).First(&link).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to retrieve updated link",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	h.clearUserLinksCache(user.ID)
+	if h.scheduler != nil {
+		h.scheduler.ScheduleLink(&link)
+	}
+	link.ScheduleState = link.ScheduleStateAt(time.Now())
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Link updated successfully",
+		Data: gin.H{
+			"link": link,
+		},
+	})
+}
+
+// DeleteLink deletes a link
+func (h *LinkHandler) DeleteLink(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, LinkResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   "UNAUTHORIZED",
+		})
+		return
+	}
+
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid link ID",
+			Error:   "INVALID_ID",
+		})
+		return
+	}
+
+	// Check if link exists and belongs to user
+	var link models.Link
+	err = h.db.Where("id = ? AND user_id = ?", linkID, user.ID).First(&link).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, LinkResponse{
+				Success: false,
+				Message: "Link not found",
+				Error:   "NOT_FOUND",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to find link",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	// Start transaction for safe deletion
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to start transaction",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	// First delete all related link clicks and their rollups
+	err = tx.Where("link_id = ?", linkID).Delete(&models.LinkClick{}).Error
+	if err == nil {
+		err = tx.Where("link_id = ?", linkID).Delete(&models.DailyLinkStat{}).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to delete link analytics",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	// And its A/B variants and health record
+	err = tx.Where("link_id = ?", linkID).Delete(&models.LinkVariant{}).Error
+	if err == nil {
+		err = tx.Where("link_id = ?", linkID).Delete(&models.LinkHealth{}).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to delete link data",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	// A deleted header's links stay, moved to the top level
+	if link.Type == models.LinkTypeHeader {
+		if err := releaseLinkSection(tx, &link); err != nil {
+			c.JSON(http.StatusInternalServerError, LinkResponse{
+				Success: false,
+				Message: "Failed to move the section's links",
+				Error:   "DATABASE_ERROR",
+			})
+			return
+		}
+	}
+
+	// Then delete the link
+	err = tx.Delete(&link).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to delete link",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to commit deletion",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	h.clearUserLinksCache(user.ID)
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Link deleted successfully",
+	})
+}
+
+// TrackClick records a click on a link and increments the click counter
+func (h *LinkHandler) TrackClick(c *gin.Context) {
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid link ID",
+			Error:   "INVALID_ID",
+		})
+		return
+	}
+
+	// Find the link
+	var link models.Link
+	err = h.db.Preload("Variants", orderVariants).Where("id = ? AND is_active = ?", linkID, true).First(&link).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, LinkResponse{
+				Success: false,
+				Message: "Link not found or inactive",
+				Error:   "NOT_FOUND",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to find link",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	var source *string
+	if ref := c.Query("ref"); models.IsTrafficSource(ref) {
+		source = &ref
+	}
+
+	// Location and device are filled in by the ingestion pipeline
+	event := clickEventFromRequest(c, &link, source, h.bots)
+	abVariant := serveLinkVariant(&link, linkVisitorKey(c))
+	if abVariant != nil {
+		event.VariantID = &abVariant.ID
+	}
+	_, _, variant := link.TargetFor(linkAudience(c, h.geoService, h.redisClient, h.config.BehindCloudflare, link.Targeting.NeedsCountry()))
+	if variant != "" {
+		event.Variant = &variant
+	}
+	if !h.ingest.Enqueue(event) {
+		fmt.Printf("Analytics queue full, dropped click for link %d\n", linkID)
+	}
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Click tracked successfully",
+		Data: gin.H{
+			"link_id": linkID,
+		},
+	})
+}
+
+// RedirectShortLink resolves a short code or custom slug, records the click
+// asynchronously and redirects to the link target
+func (h *LinkHandler) RedirectShortLink(c *gin.Context) {
+	code := c.Param("code")
+	c.Header("Cache-Control", "no-store")
+
+	// Custom slugs take precedence over generated codes
+	var link models.Link
+	err := h.db.Preload("User").Preload("Variants", orderVariants).Where("slug = ?", code).First(&link).Error
+	if err == gorm.ErrRecordNotFound {
+		err = h.db.Preload("User").Preload("Variants", orderVariants).Where("short_code = ?", code).First(&link).Error
+	}
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			fmt.Printf("Failed to resolve short link %s: %v\n", code, err)
+		}
+		c.String(http.StatusNotFound, "Link not found")
+		return
+	}
+
+	if !link.User.IsActive {
+		c.String(http.StatusNotFound, "Link not found")
+		return
+	}
+
+	profileURL := fmt.Sprintf("%s/%s", strings.TrimRight(h.config.FrontendURL, "/"), url.PathEscape(link.User.Username))
+
+	// Profiles that need an unlock or share link are handled by the profile page
+	if err := h.profileAccess.Check(c, &link.User); err != nil {
+		c.Redirect(http.StatusFound, profileURL)
+		return
+	}
+
+	// Inactive, scheduled or expired links, links not targeted at this visitor
+	// and links without a target fall back to the owner's profile
+	if !link.IsActive || !link.IsLiveAt(time.Now()) {
+		c.Redirect(http.StatusFound, profileURL)
+		return
+	}
+	abVariant := serveLinkVariant(&link, linkVisitorKey(c))
+	visible, target, variant := link.TargetFor(linkAudience(c, h.geoService, h.redisClient, h.config.BehindCloudflare, link.Targeting.NeedsCountry()))
+	if !visible || target == nil || !isRedirectableURL(*target) {
+		c.Redirect(http.StatusFound, profileURL)
+		return
+	}
+	if !passLinkGate(c, h.config.JWTSecret, &link, profileURL) {
+		return
+	}
+
+	var source *string
+	if ref := c.Query("ref"); models.IsTrafficSource(ref) {
+		source = &ref
+	}
+	event := clickEventFromRequest(c, &link, source, h.bots)
+	event.Variant = &variant
+	if abVariant != nil {
+		event.VariantID = &abVariant.ID
+	}
+	if !h.ingest.Enqueue(event) {
+		fmt.Printf("Analytics queue full, dropped click for link %d\n", link.ID)
+	}
+
+	c.Redirect(http.StatusFound, *target)
+}
+
+// validateSlug checks a custom slug, returning an error code and message if it cannot be used
+func (h *LinkHandler) validateSlug(user *models.User, slug string, linkID uint) (string, string) {
+	if !user.IsPremium() {
+		return "PREMIUM_REQUIRED", "Custom short-link slugs require a premium plan"
+	}
+	if !slugPattern.MatchString(slug) {
+		return "INVALID_SLUG", "Slug must be 3-64 characters of letters, numbers, dashes or underscores"
+	}
+
+	var count int64
+	h.db.Model(&models.Link{}).
+		Where("(slug = ? OR short_code = ?) AND id <> ?", slug, slug, linkID).
+		Count(&count)
+	if count > 0 {
+		return "SLUG_TAKEN", "This slug is already in use"
+	}
+	return "", ""
+}
+
+// clickEventFromRequest builds a click event from request data; it must be
+// called on the request goroutine since the gin context is recycled afterwards.
+// Clicks the filter takes for automated traffic are marked so they are only
+// counted as filtered.
+func clickEventFromRequest(c *gin.Context, link *models.Link, source *string, bots *botfilter.Filter) ingest.Event {
+	ipAddress := c.ClientIP()
+	return ingest.Event{
+		Kind:      ingest.KindClick,
+		UserID:    link.UserID,
+		LinkID:    link.ID,
+		Source:    source,
+		IPAddress: ipAddress,
+		UserAgent: c.GetHeader("User-Agent"),
+		Referer:   c.GetHeader("Referer"),
+		SessionID: c.GetHeader("X-Session-ID"),
+		BotReason: bots.Classify(c.Request, ipAddress),
+	}
+}
+
+// isRedirectableURL reports whether a link target is safe to redirect to
+func isRedirectableURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https", "mailto", "tel":
+		return true
+	}
+	return false
+}
+
+// ReorderLinks updates the order of multiple links
+func (h *LinkHandler) ReorderLinks(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, LinkResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   "UNAUTHORIZED",
+		})
+		return
+	}
+
+	type ReorderRequest struct {
+		Links []struct {
+			ID        uint  `json:"id" binding:"required"`
+			Order     int   `json:"order" binding:"required"`
+			SectionID *uint `json:"section_id"` // 0 moves the link to the top level; omitted keeps its section
+		} `json:"links" binding:"required"`
+	}
+
+	var req ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+			Error:   "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	// Links can move between sections, but only into the user's own headers
+	// and never a header into another
+	var linkTypes map[uint]models.LinkType
+	for _, linkOrder := range req.Links {
+		if linkOrder.SectionID == nil || *linkOrder.SectionID == 0 {
+			continue
+		}
+		if linkTypes == nil {
+			var owned []models.Link
+			h.db.Select("id, type").Where("user_id = ?", user.ID).Find(&owned)
+			linkTypes = make(map[uint]models.LinkType, len(owned))
+			for _, link := range owned {
+				linkTypes[link.ID] = link.Type
+			}
+		}
+		if linkTypes[*linkOrder.SectionID] != models.LinkTypeHeader || linkTypes[linkOrder.ID] == models.LinkTypeHeader {
+			c.JSON(http.StatusBadRequest, LinkResponse{
+				Success: false,
+				Message: fmt.Sprintf("Link %d cannot be placed in section %d", linkOrder.ID, *linkOrder.SectionID),
+				Error:   "INVALID_SECTION",
+			})
+			return
+		}
+	}
+
+	// Start transaction
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to start transaction",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	// Update each link's order and section
+	for _, linkOrder := range req.Links {
+		updates := map[string]interface{}{"\"order\"": linkOrder.Order}
+		if linkOrder.SectionID != nil {
+			updates["section_id"] = nil
+			if *linkOrder.SectionID != 0 {
+				updates["section_id"] = *linkOrder.SectionID
+			}
+		}
+		err := tx.Model(&models.Link{}).
+			Where("id = ? AND user_id = ?", linkOrder.ID, user.ID).
+			Updates(updates).Error
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, LinkResponse{
+				Success: false,
+				Message: "Failed to update link order",
+				Error:   "DATABASE_ERROR",
+			})
+			return
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to commit changes",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	h.clearUserLinksCache(user.ID)
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Links reordered successfully",
+	})
+}
+
+// GetPublicUserLinks retrieves all active links for a public user by username
+func (h *LinkHandler) GetPublicUserLinks(c *gin.Context) {
+	username := c.Param("username")
+	if username == "" {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Username is required",
+			Error:   "INVALID_USERNAME",
+		})
+		return
+	}
+
+	// Sections are nested by default; older clients ask for ?layout=flat
+	layout, err := parseLinkLayout(c.Query("layout"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: err.Error(),
+			Error:   "INVALID_LAYOUT",
+		})
+		return
+	}
+
+	var user models.User
+	err = h.db.Where("username = ? AND is_active = ?", username, true).First(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, LinkResponse{
+				Success: false,
+				Message: "User not found",
+				Error:   "USER_NOT_FOUND",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, LinkResponse{
+				Success: false,
+				Message: "Failed to find user",
+				Error:   "DATABASE_ERROR",
+			})
+		}
+		return
+	}
+
+	if err := h.profileAccess.Check(c, &user); err != nil {
+		status, message, code := profileAccessError(err)
+		c.JSON(status, LinkResponse{
+			Success: false,
+			Message: message,
+			Error:   code,
+		})
+		return
+	}
+
+	// The cache holds only live links and is invalidated by the schedule watcher
+	// whenever one of them goes live or expires. Targeting depends on the
+	// visitor, so it is applied after the cache.
+	cacheKey := fmt.Sprintf("links:user:%d", user.ID)
+	var links []models.Link
+	if h.redisClient != nil {
+		if err := h.redisClient.Get(cacheKey, &links); err != nil {
+			links = nil
+		}
+	}
+
+	if links == nil {
+		// Get user's active links
+		err = h.db.Preload("Variants", orderVariants).
+			Where("user_id = ? AND is_active = ?", user.ID, true).
+			Order("\"order\" ASC, created_at ASC").
+			Find(&links).Error
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, LinkResponse{
+				Success: false,
+				Message: "Failed to retrieve links",
+				Error:   "DATABASE_ERROR",
+			})
+			return
+		}
+
+		now := time.Now()
+		var next *time.Time
+		links, next = liveLinks(links, now)
+
+		if h.redisClient != nil {
+			// Never outlive the next transition, even if the watcher misses it
+			ttl := publicLinksCacheTTL
+			if next != nil && next.Sub(now) < ttl {
+				ttl = next.Sub(now)
+			}
+			if ttl > 0 {
+				h.redisClient.Set(cacheKey, links, ttl)
+			}
+		}
+	}
+
+	// Variants are applied before targeting so OS-specific URLs still win
+	serveLinkVariants(links, linkVisitorKey(c))
+	links = targetLinks(links, linkAudience(c, h.geoService, h.redisClient, h.config.BehindCloudflare, linksNeedCountry(links)))
+	links = hideSensitiveLinks(links, h.config.BaseURL)
+
+	if layout == LinkLayoutFlat {
+		links = flattenLinkSections(links, false)
+	} else {
+		links = nestLinkSections(links)
+	}
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Links retrieved successfully",
+		Data: gin.H{
+			"links": links,
+		},
+	})
+}
+
+// liveLinks filters out scheduled and expired links and returns the earliest
+// upcoming transition among all of them
+func liveLinks(links []models.Link, now time.Time) ([]models.Link, *time.Time) {
+	live := make([]models.Link, 0, len(links))
+	var next *time.Time
+	for _, link := range links {
+		if at := link.NextTransition(now); at != nil && (next == nil || at.Before(*next)) {
+			next = at
+		}
+		if link.IsLiveAt(now) {
+			live = append(live, link)
+		}
+	}
+	return live, next
+}
+
+// applyLinkPreview fills an empty title, icon and image from the target
+// page's metadata. Failures are not fatal; the title falls back to the host.
+func (h *LinkHandler) applyLinkPreview(ctx context.Context, req *CreateLinkRequest) {
+	if req.URL == nil {
+		return
+	}
+	parsed, err := url.Parse(*req.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, unfurlTimeout)
+	defer cancel()
+
+	preview, err := h.unfurler.Preview(ctx, *req.URL)
+	if err != nil {
+		fmt.Printf("Failed to unfurl %s: %v\n", *req.URL, err)
+		preview = &unfurl.Preview{}
+	}
+
+	if req.Title == "" {
+		req.Title = preview.Title
+		if req.Title == "" {
+			req.Title = strings.TrimPrefix(parsed.Hostname(), "www.")
+		}
+	}
+	if req.Icon == nil && preview.IconURL != "" {
+		req.Icon = &preview.IconURL
+	}
+	if req.ImageURL == nil && preview.ImageURL != "" {
+		req.ImageURL = &preview.ImageURL
+	}
+}
+
+// parseScheduleTime parses an RFC 3339 schedule bound; an empty string clears it
+func parseScheduleTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	at = at.UTC()
+	return &at, nil
+}
+
+// Note: isValidHexColor function is imported from dashboard.go
+
+// Helper function to clear user links cache
+func (h *LinkHandler) clearUserLinksCache(userID uint) {
+	if h.redisClient != nil {
+		cacheKey := fmt.Sprintf("links:user:%d", userID)
+		h.redisClient.Delete(cacheKey)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gotchu-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// liveHeartbeatInterval keeps idle streams from being closed by proxies
+	liveHeartbeatInterval = 15 * time.Second
+	// liveWriteTimeout disconnects a client that stops reading
+	liveWriteTimeout = 10 * time.Second
+	// liveRetryMillis is how long browsers wait before reconnecting a dropped stream
+	liveRetryMillis = 3000
+)
+
+// StreamLiveAnalytics streams the user's profile views and link clicks as
+// Server-Sent Events as they are recorded. "view" and "click" events carry
+// the visitor's country, device, browser and referrer; a "heartbeat" event is
+// sent every 15 seconds, and a "dropped" event reports how many events were
+// skipped because the client was not keeping up.
+func (h *DashboardHandler) StreamLiveAnalytics(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	sub, err := h.live.Subscribe(user.ID)
+	if err != nil {
+		c.JSON(http.StatusTooManyRequests, DashboardResponse{
+			Success: false,
+			Message: "Too many live analytics streams open. Close one and try again.",
+		})
+		return
+	}
+	defer h.live.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // stops nginx from buffering the stream
+	c.Status(http.StatusOK)
+
+	// Every write gets its own deadline in place of the server's write timeout,
+	// which would otherwise end the stream after 15 seconds
+	controller := http.NewResponseController(c.Writer)
+	write := func(frame string) bool {
+		controller.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+		if _, err := c.Writer.WriteString(frame); err != nil {
+			return false
+		}
+		return controller.Flush() == nil
+	}
+	send := func(name string, data interface{}) bool {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return false
+		}
+		return write(fmt.Sprintf("event: %s\ndata: %s\n\n", name, payload))
+	}
+	reportDropped := func() bool {
+		if dropped := sub.TakeDropped(); dropped > 0 {
+			return send("dropped", gin.H{"count": dropped})
+		}
+		return true
+	}
+
+	if !write(fmt.Sprintf("retry: %d\n\n", liveRetryMillis)) {
+		return
+	}
+
+	heartbeat := time.NewTicker(liveHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event := <-sub.Events():
+			if !reportDropped() || !send(event.Type, event) {
+				return
+			}
+		case <-heartbeat.C:
+			if !reportDropped() || !send("heartbeat", gin.H{"time": time.Now()}) {
+				return
+			}
+		case <-c.Request.Context().Done():
+			return
+		case <-h.live.Done():
+			return
+		}
+	}
+}
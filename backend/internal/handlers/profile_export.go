@@ -0,0 +1,652 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	net_url "net/url"
+	"reflect"
+	"sort"
+	"time"
+
+	"gotchu-backend/internal/middleware"
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/storage"
+	"gotchu-backend/pkg/urlpolicy"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ProfileBundleVersion is the current schema version of exported profile bundles.
+// Bump it whenever the bundle layout changes and register an upgrader below.
+const ProfileBundleVersion = 1
+
+// maxProfileBundleSize limits the size of an uploaded profile bundle (1MB)
+const maxProfileBundleSize = 1 << 20
+
+// maxProfileBundleLinks limits the number of links accepted in a single import
+const maxProfileBundleLinks = 200
+
+// profileBundleUpgraders upgrade a raw bundle from version N (the key) to N+1.
+// Upgraders operate on the decoded JSON so old exports never need the old Go types.
+var profileBundleUpgraders = map[int]func(bundle map[string]interface{}) error{}
+
+// ProfileBundle is a portable, schema-versioned snapshot of a user's profile setup
+type ProfileBundle struct {
+	SchemaVersion int                   `json:"schema_version"`
+	ExportedAt    time.Time             `json:"exported_at"`
+	Username      string                `json:"username"`
+	Profile       ProfileBundleProfile  `json:"profile"`
+	Customization CustomizationSettings `json:"customization"`
+	Links         []ProfileBundleLink   `json:"links"`
+	Assets        []ProfileBundleAsset  `json:"assets"`
+}
+
+// ProfileBundleProfile holds profile fields that are not part of CustomizationSettings
+type ProfileBundleProfile struct {
+	DisplayName       *string `json:"display_name,omitempty"`
+	Location          *string `json:"location,omitempty"`
+	SplashSubText     *string `json:"splash_sub_text,omitempty"`
+	ShowSplashSubText bool    `json:"show_splash_sub_text"`
+}
+
+// ProfileBundleLink is a link entry in a profile bundle; order is the array
+// position, and a link in a section follows its header
+type ProfileBundleLink struct {
+	Title       string               `json:"title"`
+	URL         *string              `json:"url,omitempty"`
+	Description *string              `json:"description,omitempty"`
+	Type        models.LinkType      `json:"type"`
+	Icon        *string              `json:"icon,omitempty"`
+	ImageURL    *string              `json:"image_url,omitempty"`
+	Color       *string              `json:"color,omitempty"`
+	IsActive    bool                 `json:"is_active"`
+	InSection   bool                 `json:"in_section,omitempty"` // belongs to the nearest header before it
+	Collapsed   bool                 `json:"collapsed,omitempty"`  // HEADER links only
+	Commerce    *models.LinkCommerce `json:"commerce,omitempty"`
+}
+
+// ProfileBundleAsset references an uploaded asset by URL
+type ProfileBundleAsset struct {
+	Kind string `json:"kind"` // avatar, background, audio, cursor
+	URL  string `json:"url"`
+}
+
+// ProfileFieldChange describes a single field changed by an import
+type ProfileFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// ProfileImportPreview summarizes what an import would change
+type ProfileImportPreview struct {
+	SourceVersion        int                  `json:"source_version"`
+	SchemaVersion        int                  `json:"schema_version"`
+	LinksMode            string               `json:"links_mode"`
+	ProfileChanges       []ProfileFieldChange `json:"profile_changes"`
+	CustomizationChanges []ProfileFieldChange `json:"customization_changes"`
+	LinksAdded           int                  `json:"links_added"`
+	LinksRemoved         int                  `json:"links_removed"`
+	ClicksDiscarded      int                  `json:"clicks_discarded"` // recorded clicks of the links replace mode removes
+	Links                []ProfileBundleLink  `json:"links"`
+}
+
+// ExportProfile exports the authenticated user's profile as a versioned JSON bundle
+func (h *DashboardHandler) ExportProfile(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	var dbUser models.User
+	if err := h.db.Where("id = ?", user.ID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to retrieve user data",
+		})
+		return
+	}
+
+	var links []models.Link
+	if err := h.db.Where("user_id = ?", user.ID).Order("\"order\" ASC, created_at ASC").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to retrieve links",
+		})
+		return
+	}
+
+	bundle := buildProfileBundle(&dbUser, links)
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"gotchu-%s-profile.json\"", dbUser.Username))
+	c.IndentedJSON(http.StatusOK, bundle)
+}
+
+// PreviewProfileImport validates a profile bundle and reports the changes it would make
+func (h *DashboardHandler) PreviewProfileImport(c *gin.Context) {
+	h.handleProfileImport(c, false)
+}
+
+// ImportProfile validates a profile bundle and applies it to the authenticated user
+func (h *DashboardHandler) ImportProfile(c *gin.Context) {
+	h.handleProfileImport(c, true)
+}
+
+// handleProfileImport runs the shared preview/apply flow for profile imports
+func (h *DashboardHandler) handleProfileImport(c *gin.Context, apply bool) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	linksMode := c.DefaultQuery("links_mode", "replace")
+	if linksMode != "replace" && linksMode != "append" && linksMode != "skip" {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: "links_mode must be one of replace, append or skip",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxProfileBundleSize+1))
+	if err != nil || len(body) > maxProfileBundleSize {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: "Profile bundle is missing or too large",
+		})
+		return
+	}
+
+	bundle, sourceVersion, err := parseProfileBundle(body)
+	if err == nil {
+		err = validateProfileBundleAssets(h.storage, bundle.Assets)
+	}
+	if err == nil {
+		err = screenProfileBundleLinks(c.Request.Context(), h.urlPolicy, bundle.Links)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: "Invalid profile bundle: " + err.Error(),
+		})
+		return
+	}
+
+	var dbUser models.User
+	if err := h.db.Where("id = ?", user.ID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to retrieve user data",
+		})
+		return
+	}
+
+	var existingLinks []models.Link
+	if err := h.db.Where("user_id = ?", user.ID).Order("\"order\" ASC, created_at ASC").Find(&existingLinks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to retrieve links",
+		})
+		return
+	}
+
+	preview := diffProfileBundle(buildProfileBundle(&dbUser, existingLinks), bundle, linksMode)
+	preview.SourceVersion = sourceVersion
+	if linksMode == "replace" {
+		for _, link := range existingLinks {
+			preview.ClicksDiscarded += link.Clicks
+		}
+	}
+
+	if !apply {
+		c.JSON(http.StatusOK, DashboardResponse{
+			Success: true,
+			Message: "Profile import preview generated",
+			Data: gin.H{
+				"preview": preview,
+			},
+		})
+		return
+	}
+
+	// Replacing links deletes their click history, so that has to be asked for
+	if preview.ClicksDiscarded > 0 && c.Query("discard_analytics") != "true" {
+		c.JSON(http.StatusConflict, DashboardResponse{
+			Success: false,
+			Message: "Replacing your links deletes their click analytics. Confirm with discard_analytics=true or import in append mode.",
+			Data: gin.H{
+				"preview": preview,
+			},
+		})
+		return
+	}
+
+	err = h.applyProfileBundle(&dbUser, bundle, linksMode)
+	if errors.Is(err, errLinkLimitExceeded) {
+		c.JSON(http.StatusForbidden, DashboardResponse{
+			Success: false,
+			Message: fmt.Sprintf("Importing %d links would exceed your plan's limit of %d links", len(bundle.Links), h.config.LinkLimit(dbUser.Plan)),
+			Data: gin.H{
+				"preview": preview,
+			},
+		})
+		return
+	}
+	if err != nil {
+		fmt.Printf("Failed to import profile bundle for user %d: %v\n", user.ID, err)
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to import profile",
+		})
+		return
+	}
+
+	// Clear caches affected by the import
+	if h.redisClient != nil {
+		h.redisClient.Delete(fmt.Sprintf("customization:user:%d", user.ID))
+		h.redisClient.Delete(fmt.Sprintf("dashboard:user:%d", user.ID))
+		h.redisClient.Delete(fmt.Sprintf("links:user:%d", user.ID))
+		h.redisClient.InvalidateUserCache(user.ID)
+	}
+
+	c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Profile imported successfully",
+		Data: gin.H{
+			"preview": preview,
+		},
+	})
+}
+
+// applyProfileBundle writes a validated bundle to the database in a single transaction
+func (h *DashboardHandler) applyProfileBundle(user *models.User, bundle *ProfileBundle, linksMode string) error {
+	updates := customizationUpdates(&bundle.Customization)
+	updates["display_name"] = bundle.Profile.DisplayName
+	updates["location"] = bundle.Profile.Location
+	updates["splash_sub_text"] = bundle.Profile.SplashSubText
+	updates["show_splash_sub_text"] = bundle.Profile.ShowSplashSubText
+	for _, asset := range bundle.Assets {
+		if asset.Kind == "avatar" {
+			updates["avatar_url"] = asset.URL
+		}
+	}
+
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update profile: %v", err)
+		}
+
+		if linksMode == "skip" {
+			return nil
+		}
+
+		nextOrder := 1
+		if linksMode == "replace" {
+			// Only reached with links that have clicks once the caller confirmed
+			// discarding their analytics
+			linkIDs := tx.Model(&models.Link{}).Select("id").Where("user_id = ?", user.ID)
+			if err := tx.Where("link_id IN (?)", linkIDs).Delete(&models.LinkClick{}).Error; err != nil {
+				return fmt.Errorf("failed to delete link analytics: %v", err)
+			}
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.DailyLinkStat{}).Error; err != nil {
+				return fmt.Errorf("failed to delete link analytics: %v", err)
+			}
+			if err := tx.Where("link_id IN (?)", linkIDs).Delete(&models.LinkVariant{}).Error; err != nil {
+				return fmt.Errorf("failed to delete link variants: %v", err)
+			}
+			if err := tx.Where("link_id IN (?)", linkIDs).Delete(&models.LinkHealth{}).Error; err != nil {
+				return fmt.Errorf("failed to delete link health: %v", err)
+			}
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.Link{}).Error; err != nil {
+				return fmt.Errorf("failed to delete links: %v", err)
+			}
+		} else {
+			nextOrder = nextLinkOrder(tx, user.ID, nil)
+		}
+		if err := reserveLinks(tx, user.ID, h.config.LinkLimit(user.Plan), len(bundle.Links)); err != nil {
+			return err
+		}
+
+		var sectionID *uint
+		sectionOrder := 0
+		for _, bundleLink := range bundle.Links {
+			link := models.Link{
+				Title:       bundleLink.Title,
+				URL:         bundleLink.URL,
+				Description: bundleLink.Description,
+				Type:        bundleLink.Type,
+				Icon:        bundleLink.Icon,
+				ImageURL:    bundleLink.ImageURL,
+				Color:       bundleLink.Color,
+				IsActive:    bundleLink.IsActive,
+				Collapsed:   bundleLink.Collapsed,
+				Commerce:    bundleLink.Commerce,
+				UserID:      user.ID,
+			}
+			switch {
+			case link.Type == models.LinkTypeHeader:
+				link.Order = nextOrder
+				nextOrder++
+			case bundleLink.InSection && sectionID != nil:
+				sectionOrder++
+				link.SectionID = sectionID
+				link.Order = sectionOrder
+			default:
+				link.Order = nextOrder
+				nextOrder++
+			}
+			if err := tx.Create(&link).Error; err != nil {
+				return fmt.Errorf("failed to create link %q: %v", bundleLink.Title, err)
+			}
+			if link.Type == models.LinkTypeHeader {
+				sectionID = &link.ID
+				sectionOrder = 0
+			}
+		}
+
+		return nil
+	})
+}
+
+// buildProfileBundle converts a user and their links into a profile bundle
+func buildProfileBundle(user *models.User, links []models.Link) *ProfileBundle {
+	bundle := &ProfileBundle{
+		SchemaVersion: ProfileBundleVersion,
+		ExportedAt:    time.Now().UTC(),
+		Username:      user.Username,
+		Profile: ProfileBundleProfile{
+			DisplayName:       user.DisplayName,
+			Location:          user.Location,
+			SplashSubText:     user.SplashSubText,
+			ShowSplashSubText: user.ShowSplashSubText,
+		},
+		Customization: customizationSettingsFromUser(user),
+		Links:         make([]ProfileBundleLink, 0, len(links)),
+		Assets:        make([]ProfileBundleAsset, 0),
+	}
+
+	for _, link := range flattenLinkSections(links, true) {
+		bundle.Links = append(bundle.Links, ProfileBundleLink{
+			Title:       link.Title,
+			URL:         link.URL,
+			Description: link.Description,
+			Type:        link.Type,
+			Icon:        link.Icon,
+			ImageURL:    link.ImageURL,
+			Color:       link.Color,
+			IsActive:    link.IsActive,
+			InSection:   link.SectionID != nil,
+			Collapsed:   link.Collapsed,
+			Commerce:    link.Commerce,
+		})
+	}
+
+	assets := []ProfileBundleAsset{
+		{Kind: "avatar", URL: getStringValue(user.AvatarURL)},
+		{Kind: "background", URL: getStringValue(user.BackgroundURL)},
+		{Kind: "audio", URL: getStringValue(user.AudioURL)},
+		{Kind: "cursor", URL: getStringValue(user.CustomCursorURL)},
+	}
+	for _, asset := range assets {
+		if asset.URL != "" {
+			bundle.Assets = append(bundle.Assets, asset)
+		}
+	}
+
+	return bundle
+}
+
+// parseProfileBundle decodes, upgrades and validates a raw profile bundle.
+// It returns the bundle along with the schema version it was exported with.
+func parseProfileBundle(body []byte) (*ProfileBundle, int, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, 0, fmt.Errorf("malformed JSON: %v", err)
+	}
+
+	sourceVersion, err := upgradeProfileBundle(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to re-encode bundle: %v", err)
+	}
+
+	var bundle ProfileBundle
+	if err := json.Unmarshal(upgraded, &bundle); err != nil {
+		return nil, 0, fmt.Errorf("unexpected bundle layout: %v", err)
+	}
+
+	// Asset references take precedence over the URLs embedded in customization
+	for _, asset := range bundle.Assets {
+		switch asset.Kind {
+		case "avatar":
+		case "background":
+			bundle.Customization.BackgroundURL = asset.URL
+		case "audio":
+			bundle.Customization.AudioURL = asset.URL
+		case "cursor":
+			bundle.Customization.CursorURL = asset.URL
+		default:
+			return nil, 0, fmt.Errorf("unknown asset kind: %s", asset.Kind)
+		}
+		if asset.URL != "" && !isValidBundleURL(asset.URL) {
+			return nil, 0, fmt.Errorf("invalid %s asset URL", asset.Kind)
+		}
+	}
+
+	if err := validateCustomizationSettings(&bundle.Customization); err != nil {
+		return nil, 0, err
+	}
+
+	if err := validateProfileBundleLinks(bundle.Links); err != nil {
+		return nil, 0, err
+	}
+
+	return &bundle, sourceVersion, nil
+}
+
+// upgradeProfileBundle runs registered upgraders until the bundle reaches the current version
+func upgradeProfileBundle(raw map[string]interface{}) (int, error) {
+	versionValue, ok := raw["schema_version"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("schema_version is required")
+	}
+
+	version := int(versionValue)
+	sourceVersion := version
+	if version < 1 {
+		return 0, fmt.Errorf("unsupported schema_version: %d", version)
+	}
+	if version > ProfileBundleVersion {
+		return 0, fmt.Errorf("schema_version %d is newer than supported version %d", version, ProfileBundleVersion)
+	}
+
+	for version < ProfileBundleVersion {
+		upgrade, exists := profileBundleUpgraders[version]
+		if !exists {
+			return 0, fmt.Errorf("no upgrader registered for schema_version %d", version)
+		}
+		if err := upgrade(raw); err != nil {
+			return 0, fmt.Errorf("failed to upgrade bundle from version %d: %v", version, err)
+		}
+		version++
+		raw["schema_version"] = version
+	}
+
+	return sourceVersion, nil
+}
+
+// validateProfileBundleAssets only accepts assets hosted in our own storage,
+// which is the only place the upload handlers ever point them at. Anything
+// else could be a third party image tracking the profile's visitors.
+func validateProfileBundleAssets(store *storage.SupabaseStorage, assets []ProfileBundleAsset) error {
+	for _, asset := range assets {
+		if asset.URL != "" && !store.IsPublicURL(asset.URL) {
+			return fmt.Errorf("%s asset must be a file uploaded to Gotchu", asset.Kind)
+		}
+	}
+	return nil
+}
+
+// screenProfileBundleLinks checks bundle link URLs against the URL policy,
+// like links created or imported any other way
+func screenProfileBundleLinks(ctx context.Context, policy *urlpolicy.Policy, links []ProfileBundleLink) error {
+	ctx, cancel := context.WithTimeout(ctx, urlCheckTimeout)
+	defer cancel()
+
+	for i, link := range links {
+		if link.URL == nil || *link.URL == "" {
+			continue
+		}
+		if err := policy.Check(ctx, *link.URL); err != nil {
+			return fmt.Errorf("link %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// validateProfileBundleLinks validates links contained in a profile bundle
+func validateProfileBundleLinks(links []ProfileBundleLink) error {
+	if len(links) > maxProfileBundleLinks {
+		return fmt.Errorf("bundle contains %d links, maximum is %d", len(links), maxProfileBundleLinks)
+	}
+
+	validTypes := []string{
+		string(models.LinkTypeDefault),
+		string(models.LinkTypeHeader),
+		string(models.LinkTypeProduct),
+		string(models.LinkTypeService),
+		string(models.LinkTypeMarketplace),
+	}
+
+	for i := range links {
+		link := &links[i]
+		if link.Title == "" || len(link.Title) > 255 {
+			return fmt.Errorf("link %d: title must be between 1 and 255 characters", i+1)
+		}
+		if link.Type == "" {
+			link.Type = models.LinkTypeDefault
+		}
+		if !contains(validTypes, string(link.Type)) {
+			return fmt.Errorf("link %d: invalid type %s", i+1, link.Type)
+		}
+		if link.URL != nil && !isValidBundleURL(*link.URL) {
+			return fmt.Errorf("link %d: invalid url", i+1)
+		}
+		if link.ImageURL != nil && !isValidBundleURL(*link.ImageURL) {
+			return fmt.Errorf("link %d: invalid image_url", i+1)
+		}
+		if link.Color != nil && !isValidHexColor(*link.Color) {
+			return fmt.Errorf("link %d: invalid color format", i+1)
+		}
+		if link.Description != nil && len(*link.Description) > 1000 {
+			return fmt.Errorf("link %d: description cannot exceed 1000 characters", i+1)
+		}
+		if link.Icon != nil && len(*link.Icon) > 500 {
+			return fmt.Errorf("link %d: icon cannot exceed 500 characters", i+1)
+		}
+		commerce, err := normalizeLinkCommerce(link.Type, link.Commerce)
+		if err != nil {
+			return fmt.Errorf("link %d: %v", i+1, err)
+		}
+		link.Commerce = commerce
+	}
+
+	return nil
+}
+
+// isValidBundleURL checks that a URL is an absolute http(s) URL
+func isValidBundleURL(rawURL string) bool {
+	parsed, err := net_url.ParseRequestURI(rawURL)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// diffProfileBundle compares the current profile with an incoming bundle
+func diffProfileBundle(current, incoming *ProfileBundle, linksMode string) *ProfileImportPreview {
+	preview := &ProfileImportPreview{
+		SchemaVersion:        ProfileBundleVersion,
+		LinksMode:            linksMode,
+		ProfileChanges:       diffJSONFields(current.Profile, incoming.Profile),
+		CustomizationChanges: diffJSONFields(current.Customization, incoming.Customization),
+		Links:                incoming.Links,
+	}
+
+	for _, asset := range incoming.Assets {
+		if asset.Kind == "avatar" {
+			from := ""
+			for _, currentAsset := range current.Assets {
+				if currentAsset.Kind == "avatar" {
+					from = currentAsset.URL
+				}
+			}
+			if from != asset.URL {
+				preview.ProfileChanges = append(preview.ProfileChanges, ProfileFieldChange{Field: "avatar_url", From: from, To: asset.URL})
+			}
+		}
+	}
+
+	switch linksMode {
+	case "replace":
+		preview.LinksAdded = len(incoming.Links)
+		preview.LinksRemoved = len(current.Links)
+	case "append":
+		preview.LinksAdded = len(incoming.Links)
+	}
+
+	return preview
+}
+
+// diffJSONFields returns the JSON fields that differ between two values of the same type
+func diffJSONFields(from, to interface{}) []ProfileFieldChange {
+	fromFields := toJSONFields(from)
+	toFields := toJSONFields(to)
+
+	keys := make([]string, 0, len(toFields))
+	for key := range toFields {
+		keys = append(keys, key)
+	}
+	for key := range fromFields {
+		if _, exists := toFields[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]ProfileFieldChange, 0)
+	for _, key := range keys {
+		if !reflect.DeepEqual(fromFields[key], toFields[key]) {
+			changes = append(changes, ProfileFieldChange{Field: key, From: fromFields[key], To: toFields[key]})
+		}
+	}
+	return changes
+}
+
+// toJSONFields flattens a struct into its JSON field map
+func toJSONFields(value interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
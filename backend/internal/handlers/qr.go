@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotchu-backend/internal/config"
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/qrcode"
+	"gotchu-backend/pkg/redis"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// qrCacheTTL is how long rendered QR codes are cached
+	qrCacheTTL = time.Hour
+	// maxQRLogoBytes caps the avatar download used as a QR center logo
+	maxQRLogoBytes = 5 << 20
+)
+
+// QRHandler renders profile QR codes
+type QRHandler struct {
+	db            *gorm.DB
+	redisClient   *redis.Client
+	config        *config.Config
+	profileAccess *ProfileAccess
+	httpClient    *http.Client
+}
+
+// NewQRHandler creates a new QR handler
+func NewQRHandler(db *gorm.DB, redisClient *redis.Client, cfg *config.Config, profileAccess *ProfileAccess) *QRHandler {
+	return &QRHandler{
+		db:            db,
+		redisClient:   redisClient,
+		config:        cfg,
+		profileAccess: profileAccess,
+		httpClient:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// GetProfileQR renders a QR code pointing at the user's profile.
+// Query parameters: format (png|svg), size, ec (L|M|Q|H), fg, bg, margin, logo, download.
+func (h *QRHandler) GetProfileQR(c *gin.Context) {
+	username := c.Param("username")
+
+	var user models.User
+	if err := h.db.Where("(username = ? OR alias = ?) AND is_active = ?", username, username, true).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, DashboardResponse{
+			Success: false,
+			Message: "User not found",
+		})
+		return
+	}
+
+	if err := h.profileAccess.Check(c, &user); err != nil {
+		status, message, code := profileAccessError(err)
+		c.JSON(status, DashboardResponse{
+			Success: false,
+			Message: message,
+			Data:    gin.H{"code": code},
+		})
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "png"))
+	if format != "png" && format != "svg" {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: "Format must be png or svg",
+		})
+		return
+	}
+
+	opts, withLogo, err := h.parseQROptions(c, &user)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Scans are tagged so they show up as their own referrer channel
+	content := fmt.Sprintf("%s/%s?ref=%s", strings.TrimRight(h.config.FrontendURL, "/"), url.PathEscape(user.Username), models.TrafficSourceQR)
+
+	cacheKey := h.qrCacheKey(&user, format, content, opts, withLogo)
+	var data []byte
+	if h.redisClient != nil {
+		h.redisClient.Get(cacheKey, &data)
+	}
+
+	if len(data) == 0 {
+		if withLogo {
+			opts.Logo = h.fetchAvatar(user.AvatarURL)
+		}
+
+		code, err := qrcode.Encode(content, opts)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, DashboardResponse{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if format == "svg" {
+			data, err = code.SVG()
+		} else {
+			data, err = code.PNG()
+		}
+		if err != nil {
+			fmt.Printf("Failed to render QR code for user %d: %v\n", user.ID, err)
+			c.JSON(http.StatusInternalServerError, DashboardResponse{
+				Success: false,
+				Message: "Failed to generate QR code",
+			})
+			return
+		}
+
+		if h.redisClient != nil {
+			h.redisClient.Set(cacheKey, data, qrCacheTTL)
+		}
+	}
+
+	contentType := "image/png"
+	if format == "svg" {
+		contentType = "image/svg+xml"
+	}
+	if c.Query("download") == "true" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-qr.%s"`, user.Username, format))
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// parseQROptions reads style options from the query, defaulting the foreground to the user's accent color
+func (h *QRHandler) parseQROptions(c *gin.Context, user *models.User) (qrcode.Options, bool, error) {
+	opts := qrcode.Options{
+		Level:  qrcode.Level(strings.ToUpper(c.DefaultQuery("ec", string(qrcode.LevelMedium)))),
+		Size:   qrcode.DefaultSize,
+		Margin: qrcode.DefaultMargin,
+	}
+
+	switch opts.Level {
+	case qrcode.LevelLow, qrcode.LevelMedium, qrcode.LevelQuartile, qrcode.LevelHigh:
+	default:
+		return opts, false, fmt.Errorf("Error correction level must be one of L, M, Q, H")
+	}
+
+	if sizeStr := c.Query("size"); sizeStr != "" {
+		size, err := strconv.Atoi(sizeStr)
+		if err != nil || size < qrcode.MinSize || size > qrcode.MaxSize {
+			return opts, false, fmt.Errorf("Size must be between %d and %d", qrcode.MinSize, qrcode.MaxSize)
+		}
+		opts.Size = size
+	}
+
+	if marginStr := c.Query("margin"); marginStr != "" {
+		margin, err := strconv.Atoi(marginStr)
+		if err != nil || margin < 0 || margin > 16 {
+			return opts, false, fmt.Errorf("Margin must be between 0 and 16")
+		}
+		opts.Margin = margin
+	}
+
+	background, err := qrcode.ParseColor(c.DefaultQuery("bg", "#ffffff"))
+	if err != nil {
+		return opts, false, fmt.Errorf("Invalid background color")
+	}
+	opts.Background = background
+
+	if fg := c.Query("fg"); fg != "" {
+		foreground, err := qrcode.ParseColor(fg)
+		if err != nil {
+			return opts, false, fmt.Errorf("Invalid foreground color")
+		}
+		if qrcode.Contrast(foreground, background) < qrcode.MinContrast {
+			return opts, false, fmt.Errorf("Foreground and background colors do not have enough contrast to scan")
+		}
+		opts.Foreground = foreground
+	} else {
+		// Fall back to black when the accent color would not scan against the background
+		opts.Foreground, _ = qrcode.ParseColor("#000000")
+		if accent, err := qrcode.ParseColor(user.AccentColor); err == nil && qrcode.Contrast(accent, background) >= qrcode.MinContrast {
+			opts.Foreground = accent
+		}
+	}
+
+	withLogo := c.Query("logo") == "true" && user.AvatarURL != nil && *user.AvatarURL != ""
+	return opts, withLogo, nil
+}
+
+// qrCacheKey identifies a rendered QR code; it changes whenever the profile is updated
+func (h *QRHandler) qrCacheKey(user *models.User, format, content string, opts qrcode.Options, withLogo bool) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%d|%d|%s|%s|%t|%d",
+		format, content, opts.Level, opts.Size, opts.Margin,
+		qrcode.Hex(opts.Foreground), qrcode.Hex(opts.Background), withLogo, user.UpdatedAt.Unix())))
+	return fmt.Sprintf("qr:user:%d:%s", user.ID, hex.EncodeToString(sum[:8]))
+}
+
+// fetchAvatar downloads and decodes the avatar for use as a center logo.
+// Only avatars hosted on our own storage or API are fetched.
+func (h *QRHandler) fetchAvatar(avatarURL *string) image.Image {
+	if avatarURL == nil || !h.isTrustedAssetURL(*avatarURL) {
+		return nil
+	}
+
+	resp, err := h.httpClient.Get(*avatarURL)
+	if err != nil {
+		fmt.Printf("Failed to fetch avatar for QR logo: %v\n", err)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	img, _, err := image.Decode(io.LimitReader(resp.Body, maxQRLogoBytes))
+	if err != nil {
+		fmt.Printf("Failed to decode avatar for QR logo: %v\n", err)
+		return nil
+	}
+	return img
+}
+
+// isTrustedAssetURL reports whether a URL points at storage we control
+func (h *QRHandler) isTrustedAssetURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return false
+	}
+
+	for _, trusted := range []string{h.config.SupabaseURL, h.config.BaseURL} {
+		if trusted == "" {
+			continue
+		}
+		if trustedURL, err := url.Parse(trusted); err == nil && strings.EqualFold(trustedURL.Host, parsed.Host) {
+			return true
+		}
+	}
+	return false
+}
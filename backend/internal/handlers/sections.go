@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"gotchu-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Sections are HEADER links. A link joins a section by pointing its
+// section_id at the header; its order is then its position inside the
+// section, while headers and ungrouped links share the top-level order.
+
+const (
+	// LinkLayoutNested returns sections with their links nested under them
+	LinkLayoutNested = "nested"
+	// LinkLayoutFlat returns each header followed by its links, for older clients
+	LinkLayoutFlat = "flat"
+)
+
+// errInvalidSection is returned when a section_id does not name one of the user's headers
+var errInvalidSection = errors.New("section_id must be the ID of one of your HEADER links")
+
+// splitLinkSections separates top-level links from the links of each section.
+// Links must be sorted by order. Links whose header is not in the list are
+// dropped, so hiding a header hides its section, unless keepOrphans is set.
+func splitLinkSections(links []models.Link, keepOrphans bool) ([]models.Link, map[uint][]models.Link) {
+	headers := make(map[uint]bool)
+	for _, link := range links {
+		if link.Type == models.LinkTypeHeader {
+			headers[link.ID] = true
+		}
+	}
+
+	top := make([]models.Link, 0, len(links))
+	sections := make(map[uint][]models.Link)
+	for _, link := range links {
+		switch {
+		case link.SectionID == nil || link.Type == models.LinkTypeHeader:
+			top = append(top, link)
+		case headers[*link.SectionID]:
+			sections[*link.SectionID] = append(sections[*link.SectionID], link)
+		case keepOrphans:
+			top = append(top, link)
+		}
+	}
+	return top, sections
+}
+
+// nestLinkSections returns the top-level links with each header carrying
+// its section's links
+func nestLinkSections(links []models.Link) []models.Link {
+	top, sections := splitLinkSections(links, false)
+	for i := range top {
+		if top[i].Type == models.LinkTypeHeader {
+			top[i].Links = sections[top[i].ID]
+		}
+	}
+	return top
+}
+
+// flattenLinkSections returns links in display order: each header directly
+// followed by its section's links
+func flattenLinkSections(links []models.Link, keepOrphans bool) []models.Link {
+	top, sections := splitLinkSections(links, keepOrphans)
+	flat := make([]models.Link, 0, len(links))
+	for _, link := range top {
+		flat = append(flat, link)
+		if link.Type == models.LinkTypeHeader {
+			flat = append(flat, sections[link.ID]...)
+		}
+	}
+	return flat
+}
+
+// parseLinkLayout reads the ?layout= parameter of public link lists
+func parseLinkLayout(value string) (string, error) {
+	switch value {
+	case "", LinkLayoutNested:
+		return LinkLayoutNested, nil
+	case LinkLayoutFlat:
+		return LinkLayoutFlat, nil
+	}
+	return "", fmt.Errorf("layout must be %s or %s", LinkLayoutNested, LinkLayoutFlat)
+}
+
+// resolveLinkSection converts a requested section_id, where 0 means the top
+// level, into the value to store. Headers cannot be placed in a section.
+func resolveLinkSection(db *gorm.DB, userID uint, linkType models.LinkType, sectionID uint) (*uint, error) {
+	if sectionID == 0 {
+		return nil, nil
+	}
+	if linkType == models.LinkTypeHeader {
+		return nil, errors.New("HEADER links cannot be placed in a section")
+	}
+
+	var count int64
+	db.Model(&models.Link{}).
+		Where("id = ? AND user_id = ? AND type = ?", sectionID, userID, models.LinkTypeHeader).
+		Count(&count)
+	if count == 0 {
+		return nil, errInvalidSection
+	}
+	return &sectionID, nil
+}
+
+// nextLinkOrder returns the order that places a link last in a section, or
+// last at the top level when sectionID is nil
+func nextLinkOrder(db *gorm.DB, userID uint, sectionID *uint) int {
+	query := db.Model(&models.Link{}).Where("user_id = ?", userID)
+	if sectionID != nil {
+		query = query.Where("section_id = ?", *sectionID)
+	} else {
+		query = query.Where("section_id IS NULL")
+	}
+
+	var maxOrder int
+	query.Select("COALESCE(MAX(\"order\"), 0)").Scan(&maxOrder)
+	return maxOrder + 1
+}
+
+// releaseLinkSection moves a section's links to the top level in the header's
+// place, keeping their order. It runs when a header is deleted or stops being
+// a header.
+func releaseLinkSection(tx *gorm.DB, header *models.Link) error {
+	var ids []uint
+	err := tx.Model(&models.Link{}).
+		Where("section_id = ?", header.ID).
+		Order("\"order\" ASC, created_at ASC").
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	// Make room after the header, then slot the links in
+	err = tx.Model(&models.Link{}).
+		Where("user_id = ? AND section_id IS NULL AND \"order\" > ? AND id <> ?", header.UserID, header.Order, header.ID).
+		UpdateColumn("\"order\"", gorm.Expr("\"order\" + ?", len(ids))).Error
+	if err != nil {
+		return err
+	}
+	for i, id := range ids {
+		err := tx.Model(&models.Link{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+			"section_id": nil,
+			"\"order\"":  header.Order + i + 1,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameLinkSection reports whether two section_id values name the same section
+func sameLinkSection(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
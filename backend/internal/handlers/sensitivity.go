@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gotchu-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// linkConsentCookie remembers which sensitive links a visitor agreed to
+	// open. It is a session cookie, so consent lasts until the browser closes.
+	linkConsentCookie = "gotchu_link_consent"
+	// maxLinkConsents caps the entries kept in the cookie; the oldest go first
+	maxLinkConsents = 50
+
+	// ActivityLinkSensitivityForced is the activity type recorded when an admin flags a link
+	ActivityLinkSensitivityForced = "link_sensitivity_forced"
+)
+
+// LinkSensitivityRequest represents the admin request payload for flagging a link
+type LinkSensitivityRequest struct {
+	Sensitivity string `json:"sensitivity" binding:"required,oneof=none nsfw spoiler"`
+	Reason      string `json:"reason" binding:"omitempty,max=255"`
+}
+
+// ForceLinkSensitivity lets an admin flag a reported link. The flag is locked
+// so the owner cannot clear it; setting "none" lifts the lock.
+func (h *LinkHandler) ForceLinkSensitivity(c *gin.Context) {
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid link ID",
+			Error:   "INVALID_ID",
+		})
+		return
+	}
+
+	var req LinkSensitivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+			Error:   "VALIDATION_ERROR",
+		})
+		return
+	}
+	sensitivity, _ := models.ParseLinkSensitivity(req.Sensitivity)
+
+	var link models.Link
+	if err := h.db.Where("id = ?", linkID).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, LinkResponse{
+			Success: false,
+			Message: "Link not found",
+			Error:   "NOT_FOUND",
+		})
+		return
+	}
+
+	forced := sensitivity != models.LinkSensitivityNone
+	err = h.db.Model(&link).Updates(map[string]interface{}{
+		"sensitivity":        sensitivity,
+		"sensitivity_forced": forced,
+	}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to update link",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+	link.Sensitivity = sensitivity
+	link.SensitivityForced = forced
+
+	if forced {
+		description := fmt.Sprintf("An admin marked your link \"%s\" as %s", link.Title, sensitivity)
+		if req.Reason != "" {
+			description += ": " + req.Reason
+		}
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"link_id":     link.ID,
+			"sensitivity": sensitivity,
+			"reason":      req.Reason,
+		})
+		metadataStr := string(metadata)
+		activity := models.Activity{
+			UserID:      link.UserID,
+			Type:        ActivityLinkSensitivityForced,
+			Description: description,
+			Metadata:    &metadataStr,
+		}
+		if err := h.db.Create(&activity).Error; err != nil {
+			fmt.Printf("Failed to record sensitivity activity for link %d: %v\n", link.ID, err)
+		}
+	}
+
+	h.clearUserLinksCache(link.UserID)
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Link sensitivity updated",
+		Data: gin.H{
+			"link": link,
+		},
+	})
+}
+
+// hideSensitiveLinks strips the target and preview image from sensitive links
+// and points visitors at the interstitial instead. Links must already have
+// had their variant and targeting applied.
+func hideSensitiveLinks(links []models.Link, baseURL string) []models.Link {
+	base := strings.TrimRight(baseURL, "/")
+	for i := range links {
+		if !links[i].IsSensitive() {
+			continue
+		}
+		links[i].URL = nil
+		links[i].ImageURL = nil
+		if links[i].Commerce != nil {
+			commerce := *links[i].Commerce
+			commerce.Images = nil
+			links[i].Commerce = &commerce
+		}
+		links[i].Targeting = nil
+		links[i].Variants = nil
+		if links[i].ShortCode != nil {
+			gateURL := fmt.Sprintf("%s/l/%s", base, *links[i].ShortCode)
+			links[i].GateURL = &gateURL
+		}
+	}
+	return links
+}
+
+// passLinkGate reports whether the visitor may be redirected to a link. For
+// a sensitive link without consent it renders the interstitial instead; the
+// interstitial posts back to the same URL, which records consent and
+// continues. cancelURL is where "Go back" leads.
+func passLinkGate(c *gin.Context, secret string, link *models.Link, cancelURL string) bool {
+	if !link.IsSensitive() {
+		return true
+	}
+
+	consents := readLinkConsents(c, secret)
+	key := linkConsentKey(link)
+	if c.Request.Method == http.MethodPost && c.PostForm("consent") == "yes" {
+		consents = append(consents, key)
+		writeLinkConsents(c, secret, consents)
+		return true
+	}
+	for _, consent := range consents {
+		if consent == key {
+			return true
+		}
+	}
+
+	data := linkInterstitialData{
+		Title:     link.Title,
+		NSFW:      link.Sensitivity == models.LinkSensitivityNSFW,
+		ActionURL: c.Request.URL.RequestURI(),
+		CancelURL: cancelURL,
+	}
+	var buf bytes.Buffer
+	if err := linkInterstitialTemplate.Execute(&buf, data); err != nil {
+		fmt.Printf("Failed to render link interstitial: %v\n", err)
+		c.String(http.StatusInternalServerError, "Failed to render page")
+		return false
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+	return false
+}
+
+// linkConsentKey is what a visitor consents to: confirming their age covers
+// every adult link, while spoilers are accepted one link at a time
+func linkConsentKey(link *models.Link) string {
+	if link.Sensitivity == models.LinkSensitivityNSFW {
+		return string(models.LinkSensitivityNSFW)
+	}
+	return "l" + strconv.FormatUint(uint64(link.ID), 10)
+}
+
+// readLinkConsents returns the consents in the visitor's cookie, ignoring
+// cookies whose signature does not match
+func readLinkConsents(c *gin.Context, secret string) []string {
+	value, err := c.Cookie(linkConsentCookie)
+	if err != nil {
+		return nil
+	}
+	dot := strings.LastIndexByte(value, '.')
+	if dot < 0 {
+		return nil
+	}
+	payload, signature := value[:dot], value[dot+1:]
+	if !hmac.Equal([]byte(signature), []byte(signLinkConsents(secret, payload))) || payload == "" {
+		return nil
+	}
+	return strings.Split(payload, "~")
+}
+
+// writeLinkConsents stores consents in a signed session cookie
+func writeLinkConsents(c *gin.Context, secret string, consents []string) {
+	seen := make(map[string]bool, len(consents))
+	unique := make([]string, 0, len(consents))
+	for i := len(consents) - 1; i >= 0 && len(unique) < maxLinkConsents; i-- {
+		if !seen[consents[i]] {
+			seen[consents[i]] = true
+			unique = append([]string{consents[i]}, unique...)
+		}
+	}
+
+	payload := strings.Join(unique, "~")
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     linkConsentCookie,
+		Value:    payload + "." + signLinkConsents(secret, payload),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func signLinkConsents(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("link-consent:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// linkInterstitialData is rendered by linkInterstitialTemplate
+type linkInterstitialData struct {
+	Title     string
+	NSFW      bool
+	ActionURL string
+	CancelURL string
+}
+
+var linkInterstitialTemplate = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{if .NSFW}}Age confirmation{{else}}Spoiler warning{{end}} · gotchu</title>
+<style>
+*{box-sizing:border-box;margin:0;padding:0}
+body{font-family:system-ui,-apple-system,"Segoe UI",Roboto,sans-serif;background:#0f0f12;color:#f3f4f6;min-height:100vh;display:flex;align-items:center;justify-content:center;padding:16px}
+.card{max-width:420px;width:100%;background:#18181d;border:1px solid #2a2a31;border-radius:16px;padding:28px;text-align:center;display:flex;flex-direction:column;gap:14px}
+h1{font-size:20px}
+p{opacity:.75;font-size:14px;line-height:1.5}
+.title{font-weight:600;opacity:1}
+.actions{display:flex;gap:10px;justify-content:center;margin-top:6px}
+button,a.back{padding:10px 18px;border-radius:10px;font-size:14px;font-weight:500;cursor:pointer;text-decoration:none}
+button{background:#1bbd9a;color:#fff;border:none}
+a.back{color:inherit;border:1px solid #2a2a31}
+</style>
+</head>
+<body>
+<form class="card" method="post" action="{{.ActionURL}}">
+{{if .NSFW}}<h1>This link may contain adult content</h1>
+<p>You are about to open <span class="title">{{.Title}}</span>. You must be 18 or older to continue.</p>
+{{else}}<h1>Spoiler warning</h1>
+<p><span class="title">{{.Title}}</span> may reveal details you have not seen yet.</p>
+{{end}}<input type="hidden" name="consent" value="yes">
+<div class="actions">
+<a class="back" href="{{.CancelURL}}">Go back</a>
+<button type="submit">{{if .NSFW}}I am 18 or older{{else}}Show me{{end}}</button>
+</div>
+</form>
+</body>
+</html>
+`))
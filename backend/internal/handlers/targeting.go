@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/analytics"
+	"gotchu-backend/pkg/redis"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// geoCountryCacheTTL is how long a resolved visitor country is cached per IP
+	geoCountryCacheTTL = 24 * time.Hour
+	// maxTargetCountries caps the size of a link's country allow list
+	maxTargetCountries = 250
+	// unknownCountry is cached for IPs that cannot be located
+	unknownCountry = "XX"
+)
+
+// linkAudience describes the requesting visitor for link targeting. The
+// country is only resolved when needCountry is set since it may need a lookup.
+// trustCountryHeader is set when the deployment sits behind Cloudflare.
+func linkAudience(c *gin.Context, geoService *analytics.GeoLocationService, redisClient *redis.Client, trustCountryHeader, needCountry bool) models.LinkAudience {
+	device := analytics.DetectDevice(c.GetHeader("User-Agent"))
+	audience := models.LinkAudience{
+		Device: device.Device,
+		OS:     device.OS,
+	}
+	if needCountry {
+		audience.Country = visitorCountry(c, geoService, redisClient, trustCountryHeader)
+	}
+	return audience
+}
+
+// visitorCountry returns the visitor's ISO country code, or "" when unknown.
+// Behind Cloudflare its country header is used when present; otherwise the IP
+// is looked up and the result cached. Anyone can send the header, so it is
+// ignored unless trustCountryHeader is set.
+func visitorCountry(c *gin.Context, geoService *analytics.GeoLocationService, redisClient *redis.Client, trustCountryHeader bool) string {
+	if trustCountryHeader {
+		if code := strings.ToUpper(c.GetHeader("CF-IPCountry")); len(code) == 2 && code != unknownCountry && code != "T1" {
+			return code
+		}
+	}
+
+	ipAddress := c.ClientIP()
+	cacheKey := fmt.Sprintf("geo:country:%s", ipAddress)
+	if redisClient != nil {
+		var code string
+		if err := redisClient.Get(cacheKey, &code); err == nil && code != "" {
+			if code == unknownCountry {
+				return ""
+			}
+			return code
+		}
+	}
+
+	location, err := geoService.GetLocation(ipAddress)
+	if err != nil {
+		// Lookup failures are not cached so the next request retries
+		fmt.Printf("Failed to resolve country for link targeting: %v\n", err)
+		return ""
+	}
+
+	code := strings.ToUpper(location.CountryCode)
+	if len(code) != 2 {
+		code = unknownCountry
+	}
+	if redisClient != nil {
+		redisClient.Set(cacheKey, code, geoCountryCacheTTL)
+	}
+	if code == unknownCountry {
+		return ""
+	}
+	return code
+}
+
+// linksNeedCountry reports whether any link has a country allow list
+func linksNeedCountry(links []models.Link) bool {
+	for i := range links {
+		if links[i].Targeting.NeedsCountry() {
+			return true
+		}
+	}
+	return false
+}
+
+// targetLinks applies each link's targeting rules for the visitor, dropping
+// links that are not shown to them and serving alternate URLs. The rules
+// themselves are not exposed to visitors.
+func targetLinks(links []models.Link, audience models.LinkAudience) []models.Link {
+	targeted := make([]models.Link, 0, len(links))
+	for _, link := range links {
+		visible, target, _ := link.TargetFor(audience)
+		if !visible {
+			continue
+		}
+		link.URL = target
+		link.Targeting = nil
+		targeted = append(targeted, link)
+	}
+	return targeted
+}
+
+// normalizeLinkTargeting validates targeting rules from a request and returns
+// them in canonical form, or nil when no rules are set
+func normalizeLinkTargeting(targeting *models.LinkTargeting) (*models.LinkTargeting, error) {
+	if targeting.IsEmpty() {
+		return nil, nil
+	}
+
+	normalized := &models.LinkTargeting{}
+
+	if len(targeting.Countries) > maxTargetCountries {
+		return nil, fmt.Errorf("Targeting can list at most %d countries", maxTargetCountries)
+	}
+	seen := make(map[string]bool)
+	for _, country := range targeting.Countries {
+		code := strings.ToUpper(strings.TrimSpace(country))
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return nil, fmt.Errorf("Invalid country code %q. Use ISO 3166-1 alpha-2 codes like US", country)
+		}
+		if !seen[code] {
+			seen[code] = true
+			normalized.Countries = append(normalized.Countries, code)
+		}
+	}
+
+	seen = make(map[string]bool)
+	for _, device := range targeting.Devices {
+		device = strings.ToLower(strings.TrimSpace(device))
+		switch device {
+		case models.TargetDeviceMobile, models.TargetDeviceTablet, models.TargetDeviceDesktop:
+		default:
+			return nil, fmt.Errorf("Invalid device %q. Use mobile, tablet or desktop", device)
+		}
+		if !seen[device] {
+			seen[device] = true
+			normalized.Devices = append(normalized.Devices, device)
+		}
+	}
+
+	for os, target := range targeting.OSURLs {
+		os = strings.ToLower(strings.TrimSpace(os))
+		if !isTargetOS(os) {
+			return nil, fmt.Errorf("Invalid operating system %q. Use one of %s", os, strings.Join(models.TargetOperatingSystems, ", "))
+		}
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		if len(target) > 1000 || !isRedirectableURL(target) {
+			return nil, fmt.Errorf("Invalid alternate URL for %s", os)
+		}
+		if normalized.OSURLs == nil {
+			normalized.OSURLs = make(map[string]string)
+		}
+		normalized.OSURLs[os] = target
+	}
+
+	if normalized.IsEmpty() {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+// isTargetOS reports whether os is a known operating system name
+func isTargetOS(os string) bool {
+	for _, name := range models.TargetOperatingSystems {
+		if name == os {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotchu-backend/internal/middleware"
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/urlpolicy"
+	"gotchu-backend/pkg/workers"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxBlocklistUploadSize limits the size of an uploaded blocklist (5MB)
+const maxBlocklistUploadSize = 5 << 20
+
+// urlCheckTimeout bounds a URL policy check, including shortener unwrapping
+const urlCheckTimeout = 10 * time.Second
+
+// URLPolicyHandler lets admins manage the blocked domain list
+type URLPolicyHandler struct {
+	db         *gorm.DB
+	policy     *urlpolicy.Policy
+	rescanner  *urlpolicy.Rescanner
+	workerPool *workers.WorkerPool
+}
+
+// NewURLPolicyHandler creates a new URL policy handler
+func NewURLPolicyHandler(db *gorm.DB, policy *urlpolicy.Policy, rescanner *urlpolicy.Rescanner, workerPool *workers.WorkerPool) *URLPolicyHandler {
+	return &URLPolicyHandler{
+		db:         db,
+		policy:     policy,
+		rescanner:  rescanner,
+		workerPool: workerPool,
+	}
+}
+
+// BlockedDomainRequest represents the request payload for blocking a domain
+type BlockedDomainRequest struct {
+	Domain string `json:"domain" binding:"required,max=253"`
+	Reason string `json:"reason" binding:"omitempty,max=255"`
+}
+
+// ListBlockedDomains returns blocked domains, optionally filtered by ?search=
+func (h *URLPolicyHandler) ListBlockedDomains(c *gin.Context) {
+	query := h.db.Model(&models.BlockedDomain{}).Order("domain ASC")
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		query = query.Where("domain ILIKE ?", "%"+search+"%")
+	}
+
+	var domains []models.BlockedDomain
+	if err := query.Limit(500).Find(&domains).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to retrieve blocked domains",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Blocked domains retrieved successfully",
+		Data: gin.H{
+			"domains": domains,
+		},
+	})
+}
+
+// AddBlockedDomain blocks a domain and its subdomains, then rescans existing links
+func (h *URLPolicyHandler) AddBlockedDomain(c *gin.Context) {
+	var req BlockedDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+			Error:   "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	domain, err := urlpolicy.NormalizeDomain(strings.TrimPrefix(req.Domain, "*."))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid domain",
+			Error:   "INVALID_DOMAIN",
+		})
+		return
+	}
+
+	var existing int64
+	h.db.Model(&models.BlockedDomain{}).Where("domain = ?", domain).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, LinkResponse{
+			Success: false,
+			Message: "This domain is already blocked",
+			Error:   "ALREADY_BLOCKED",
+		})
+		return
+	}
+
+	entry := models.BlockedDomain{
+		Domain: domain,
+		Reason: strings.TrimSpace(req.Reason),
+		Source: models.BlockedDomainSourceAdmin,
+	}
+	if user, ok := middleware.GetCurrentUser(c); ok {
+		entry.CreatedBy = &user.ID
+	}
+	if err := h.db.Create(&entry).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to block domain",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	h.blocklistChanged()
+
+	c.JSON(http.StatusCreated, LinkResponse{
+		Success: true,
+		Message: "Domain blocked; existing links are being rescanned",
+		Data: gin.H{
+			"domain": entry,
+		},
+	})
+}
+
+// ImportBlockedDomains adds the domains in an uploaded blocklist file
+func (h *URLPolicyHandler) ImportBlockedDomains(c *gin.Context) {
+	body := io.LimitReader(c.Request.Body, maxBlocklistUploadSize)
+	added, err := urlpolicy.Import(h.db, body, models.BlockedDomainSourceFile)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid blocklist: " + err.Error(),
+			Error:   "INVALID_BLOCKLIST",
+		})
+		return
+	}
+
+	if added > 0 {
+		h.blocklistChanged()
+	}
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Blocklist imported",
+		Data: gin.H{
+			"added": added,
+		},
+	})
+}
+
+// RemoveBlockedDomain unblocks a domain. Links disabled because of it stay
+// disabled until their owners turn them back on.
+func (h *URLPolicyHandler) RemoveBlockedDomain(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid blocked domain ID",
+			Error:   "INVALID_ID",
+		})
+		return
+	}
+
+	result := h.db.Delete(&models.BlockedDomain{}, id)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to unblock domain",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, LinkResponse{
+			Success: false,
+			Message: "Blocked domain not found",
+			Error:   "NOT_FOUND",
+		})
+		return
+	}
+
+	if err := h.policy.Reload(h.db); err != nil {
+		fmt.Printf("Failed to reload URL policy: %v\n", err)
+	}
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Domain unblocked",
+	})
+}
+
+// RescanLinks re-checks all active links against the current policy
+func (h *URLPolicyHandler) RescanLinks(c *gin.Context) {
+	h.submitRescan()
+
+	c.JSON(http.StatusAccepted, LinkResponse{
+		Success: true,
+		Message: "Rescan started",
+	})
+}
+
+// CheckURL reports whether a URL passes the policy without saving anything
+func (h *URLPolicyHandler) CheckURL(c *gin.Context) {
+	var req struct {
+		URL string `json:"url" binding:"required,max=1000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+			Error:   "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), urlCheckTimeout)
+	defer cancel()
+
+	result := gin.H{"allowed": true}
+	if err := h.policy.Check(ctx, req.URL); err != nil {
+		result = gin.H{"allowed": false, "reason": err.Error()}
+		if violation, ok := err.(*urlpolicy.Violation); ok {
+			result["code"] = violation.Code
+		}
+	}
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "URL checked",
+		Data:    result,
+	})
+}
+
+// blocklistChanged reloads the policy and rescans existing links in the background
+func (h *URLPolicyHandler) blocklistChanged() {
+	if err := h.policy.Reload(h.db); err != nil {
+		fmt.Printf("Failed to reload URL policy: %v\n", err)
+		return
+	}
+	h.submitRescan()
+}
+
+// submitRescan queues a rescan; a full pass over every link can take a while
+func (h *URLPolicyHandler) submitRescan() {
+	h.workerPool.Submit(workers.Job{
+		ID:      "url-policy-rescan",
+		Handler: h.rescanner.Run,
+		Timeout: time.Hour,
+	})
+}
+
+// checkLinkURLs rejects the request when any of the link's targets violates
+// the URL policy; it reports whether the request may continue
+func (h *LinkHandler) checkLinkURLs(c *gin.Context, targets []string) bool {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), urlCheckTimeout)
+	defer cancel()
+
+	for _, target := range targets {
+		err := h.urlPolicy.Check(ctx, target)
+		if err == nil {
+			continue
+		}
+
+		code := urlpolicy.CodeInvalid
+		if violation, ok := err.(*urlpolicy.Violation); ok {
+			code = violation.Code
+		}
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: err.Error(),
+			Error:   code,
+		})
+		return false
+	}
+	return true
+}
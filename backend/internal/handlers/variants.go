@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gotchu-backend/internal/middleware"
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/abtest"
+	"gotchu-backend/pkg/redis"
+	"gotchu-backend/pkg/workers"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LinkVariantRequest represents the request payload for creating or updating an A/B variant
+type LinkVariantRequest struct {
+	Name   *string `json:"name" binding:"omitempty,min=1,max=50"`
+	Title  *string `json:"title" binding:"omitempty,max=255"`
+	URL    *string `json:"url" binding:"omitempty,max=1000"`
+	Icon   *string `json:"icon" binding:"omitempty,max=500"`
+	Color  *string `json:"color" binding:"omitempty,len=7"`
+	Weight *int    `json:"weight" binding:"omitempty,min=0,max=100"`
+}
+
+// LinkVariantStats is a variant with its click-through rate and comparison against the control
+type LinkVariantStats struct {
+	models.LinkVariant
+	CTR          float64            `json:"ctr"`
+	TrafficShare float64            `json:"traffic_share"`
+	VsControl    *abtest.Comparison `json:"vs_control,omitempty"`
+}
+
+// GetLinkVariants returns a link's A/B variants with significance stats
+func (h *LinkHandler) GetLinkVariants(c *gin.Context) {
+	link, ok := h.ownedLinkWithVariants(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Variants retrieved successfully",
+		Data:    linkExperimentSummary(link),
+	})
+}
+
+// CreateLinkVariant adds an A/B variant to a link
+func (h *LinkHandler) CreateLinkVariant(c *gin.Context) {
+	link, ok := h.ownedLinkWithVariants(c)
+	if !ok {
+		return
+	}
+
+	var req LinkVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+			Error:   "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	if len(link.Variants) >= models.MaxLinkVariants {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: fmt.Sprintf("A link can have at most %d variants", models.MaxLinkVariants),
+			Error:   "VARIANT_LIMIT",
+		})
+		return
+	}
+
+	variant := models.LinkVariant{
+		LinkID: link.ID,
+		Name:   string(rune('A' + len(link.Variants))),
+		Weight: 1,
+	}
+	if code, message := applyLinkVariantRequest(&variant, &req); code != "" {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: message,
+			Error:   code,
+		})
+		return
+	}
+	if req.URL != nil && *req.URL != "" && !h.checkLinkURLs(c, []string{*req.URL}) {
+		return
+	}
+
+	if err := h.db.Create(&variant).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to create variant",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	h.clearUserLinksCache(link.UserID)
+
+	c.JSON(http.StatusCreated, LinkResponse{
+		Success: true,
+		Message: "Variant created successfully",
+		Data: gin.H{
+			"variant": variant,
+		},
+	})
+}
+
+// UpdateLinkVariant updates an A/B variant's content or weight
+func (h *LinkHandler) UpdateLinkVariant(c *gin.Context) {
+	link, ok := h.ownedLinkWithVariants(c)
+	if !ok {
+		return
+	}
+
+	variant, ok := findLinkVariant(c, link)
+	if !ok {
+		return
+	}
+
+	var req LinkVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+			Error:   "VALIDATION_ERROR",
+		})
+		return
+	}
+
+	if code, message := applyLinkVariantRequest(variant, &req); code != "" {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: message,
+			Error:   code,
+		})
+		return
+	}
+	if req.URL != nil && *req.URL != "" && !h.checkLinkURLs(c, []string{*req.URL}) {
+		return
+	}
+
+	// Counters are updated concurrently by traffic, so only content columns are written
+	err := h.db.Model(variant).Select("name", "title", "url", "icon", "color", "weight", "updated_at").Updates(variant).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to update variant",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	h.clearUserLinksCache(link.UserID)
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Variant updated successfully",
+		Data: gin.H{
+			"variant": variant,
+		},
+	})
+}
+
+// DeleteLinkVariant removes an A/B variant from a link
+func (h *LinkHandler) DeleteLinkVariant(c *gin.Context) {
+	link, ok := h.ownedLinkWithVariants(c)
+	if !ok {
+		return
+	}
+
+	variant, ok := findLinkVariant(c, link)
+	if !ok {
+		return
+	}
+
+	if err := h.db.Delete(variant).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to delete variant",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	h.clearUserLinksCache(link.UserID)
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Variant deleted successfully",
+	})
+}
+
+// PromoteLinkVariant ends the test by copying the chosen variant into the
+// link and removing all variants
+func (h *LinkHandler) PromoteLinkVariant(c *gin.Context) {
+	link, ok := h.ownedLinkWithVariants(c)
+	if !ok {
+		return
+	}
+
+	variant, ok := findLinkVariant(c, link)
+	if !ok {
+		return
+	}
+
+	promoted := *link
+	promoted.ApplyVariant(variant)
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Link{}).Where("id = ?", link.ID).Updates(map[string]interface{}{
+			"title":      promoted.Title,
+			"url":        promoted.URL,
+			"icon":       promoted.Icon,
+			"color":      promoted.Color,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("link_id = ?", link.ID).Delete(&models.LinkVariant{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to promote variant",
+			Error:   "DATABASE_ERROR",
+		})
+		return
+	}
+
+	h.clearUserLinksCache(link.UserID)
+
+	var updated models.Link
+	h.db.Where("id = ?", link.ID).First(&updated)
+	updated.ScheduleState = updated.ScheduleStateAt(time.Now())
+
+	c.JSON(http.StatusOK, LinkResponse{
+		Success: true,
+		Message: "Variant promoted successfully",
+		Data: gin.H{
+			"link": updated,
+		},
+	})
+}
+
+// ownedLinkWithVariants loads the authenticated user's link with its variants,
+// writing an error response when it cannot
+func (h *LinkHandler) ownedLinkWithVariants(c *gin.Context) (*models.Link, bool) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, LinkResponse{
+			Success: false,
+			Message: "Authentication required",
+			Error:   "UNAUTHORIZED",
+		})
+		return nil, false
+	}
+
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, LinkResponse{
+			Success: false,
+			Message: "Invalid link ID",
+			Error:   "INVALID_ID",
+		})
+		return nil, false
+	}
+
+	var link models.Link
+	err = h.db.Preload("Variants", orderVariants).Where("id = ? AND user_id = ?", linkID, user.ID).First(&link).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, LinkResponse{
+				Success: false,
+				Message: "Link not found",
+				Error:   "NOT_FOUND",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, LinkResponse{
+			Success: false,
+			Message: "Failed to find link",
+			Error:   "DATABASE_ERROR",
+		})
+		return nil, false
+	}
+
+	return &link, true
+}
+
+// findLinkVariant looks up the :variantId parameter among the link's variants
+func findLinkVariant(c *gin.Context, link *models.Link) (*models.LinkVariant, bool) {
+	variantID, err := strconv.ParseUint(c.Param("variantId"), 10, 32)
+	if err == nil {
+		for i := range link.Variants {
+			if link.Variants[i].ID == uint(variantID) {
+				return &link.Variants[i], true
+			}
+		}
+	}
+
+	c.JSON(http.StatusNotFound, LinkResponse{
+		Success: false,
+		Message: "Variant not found",
+		Error:   "NOT_FOUND",
+	})
+	return nil, false
+}
+
+// applyLinkVariantRequest validates a variant request and copies it onto the
+// variant. It returns an error code and message when validation fails.
+func applyLinkVariantRequest(variant *models.LinkVariant, req *LinkVariantRequest) (string, string) {
+	if req.Color != nil && *req.Color != "" && !isValidHexColor(*req.Color) {
+		return "INVALID_COLOR", "Invalid color format. Use hex format like #FF0000"
+	}
+	if req.URL != nil && *req.URL != "" && !isRedirectableURL(*req.URL) {
+		return "INVALID_URL", "Variant URL must be an http, https, mailto or tel link"
+	}
+
+	if req.Name != nil {
+		variant.Name = *req.Name
+	}
+	if req.Title != nil {
+		variant.Title = req.Title
+	}
+	if req.URL != nil {
+		variant.URL = req.URL
+	}
+	if req.Icon != nil {
+		variant.Icon = req.Icon
+	}
+	if req.Color != nil {
+		variant.Color = req.Color
+	}
+	if req.Weight != nil {
+		variant.Weight = *req.Weight
+	}
+	return "", ""
+}
+
+// linkExperimentSummary computes per-variant stats. The first variant is the
+// control; a winner is reported once the leader differs significantly from it.
+func linkExperimentSummary(link *models.Link) gin.H {
+	totalWeight := 0
+	for _, variant := range link.Variants {
+		if variant.Weight > 0 {
+			totalWeight += variant.Weight
+		}
+	}
+
+	stats := make([]LinkVariantStats, 0, len(link.Variants))
+	var control abtest.Arm
+	for i, variant := range link.Variants {
+		arm := abtest.Arm{Impressions: variant.Impressions, Clicks: variant.Clicks}
+		entry := LinkVariantStats{LinkVariant: variant, CTR: arm.Rate()}
+		if totalWeight > 0 && variant.Weight > 0 {
+			entry.TrafficShare = float64(variant.Weight) / float64(totalWeight)
+		}
+		if i == 0 {
+			control = arm
+		} else {
+			comparison := abtest.Compare(control, arm, abtest.DefaultConfidence)
+			entry.VsControl = &comparison
+		}
+		stats = append(stats, entry)
+	}
+
+	var winnerID *uint
+	if len(stats) >= 2 {
+		leader := 0
+		for i := range stats {
+			if stats[i].CTR > stats[leader].CTR {
+				leader = i
+			}
+		}
+		if leader == 0 {
+			// The control wins only if every challenger is significantly worse
+			won := true
+			for _, entry := range stats[1:] {
+				if !entry.VsControl.Significant {
+					won = false
+				}
+			}
+			if won {
+				winnerID = &stats[0].ID
+			}
+		} else if stats[leader].VsControl.Significant {
+			winnerID = &stats[leader].ID
+		}
+	}
+
+	return gin.H{
+		"link_id":    link.ID,
+		"active":     link.HasExperiment(),
+		"variants":   stats,
+		"winner_id":  winnerID,
+		"confidence": abtest.DefaultConfidence,
+	}
+}
+
+// orderVariants preloads variants in a stable order so assignment is sticky
+func orderVariants(db *gorm.DB) *gorm.DB {
+	return db.Order("id ASC")
+}
+
+// linkVisitorKey identifies the visitor for sticky variant assignment,
+// preferring the frontend session over the client address
+func linkVisitorKey(c *gin.Context) string {
+	if sessionID := c.GetHeader("X-Session-ID"); sessionID != "" {
+		return "session:" + sessionID
+	}
+	return "client:" + c.ClientIP() + "|" + c.GetHeader("User-Agent")
+}
+
+// serveLinkVariant applies the visitor's assigned variant to the link and
+// returns it, or nil when the link is not being tested
+func serveLinkVariant(link *models.Link, visitorKey string) *models.LinkVariant {
+	if !link.HasExperiment() {
+		link.Variants = nil
+		return nil
+	}
+
+	weights := make([]int, len(link.Variants))
+	for i := range link.Variants {
+		weights[i] = link.Variants[i].Weight
+	}
+
+	var variant *models.LinkVariant
+	if index := abtest.Assign(fmt.Sprintf("%d:%s", link.ID, visitorKey), weights); index >= 0 {
+		served := link.Variants[index]
+		variant = &served
+		link.ApplyVariant(variant)
+		link.ServedVariantID = &variant.ID
+	}
+	link.Variants = nil
+	return variant
+}
+
+// serveLinkVariants applies assigned variants to every tested link
+func serveLinkVariants(links []models.Link, visitorKey string) {
+	for i := range links {
+		serveLinkVariant(&links[i], visitorKey)
+	}
+}
+
+// recordVariantImpressions counts an impression for each variant served in
+// links (non-blocking). Like profile views, automated traffic is skipped and
+// a visitor is counted once per variant every 24 hours.
+func recordVariantImpressions(db *gorm.DB, redisClient *redis.Client, workerPool *workers.WorkerPool, links []models.Link, visitorKey, botReason string) {
+	if botReason != "" {
+		return
+	}
+	var ids []uint
+	for _, link := range links {
+		if link.ServedVariantID != nil {
+			ids = append(ids, *link.ServedVariantID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	workerPool.SubmitFunc(fmt.Sprintf("variant-impressions-%d", ids[0]), func() error {
+		if redisClient != nil {
+			fresh := ids[:0]
+			for _, id := range ids {
+				// If Redis fails the impression is counted rather than lost
+				stored, err := redisClient.SetIfNotExists(fmt.Sprintf("variant_impression:%d:%s", id, visitorKey), "1", 24*time.Hour)
+				if err != nil || stored {
+					fresh = append(fresh, id)
+				}
+			}
+			if ids = fresh; len(ids) == 0 {
+				return nil
+			}
+		}
+		return incrementVariantCounter(db, "impressions", ids)
+	})
+}
+
+// incrementVariantCounter bumps the impressions or clicks counter of the given variants
+func incrementVariantCounter(db *gorm.DB, column string, ids []uint) error {
+	err := db.Model(&models.LinkVariant{}).Where("id IN ?", ids).UpdateColumn(column, gorm.Expr(column+" + 1")).Error
+	if err != nil {
+		fmt.Printf("Failed to record variant %s for %v: %v\n", column, ids, err)
+	}
+	return err
+}
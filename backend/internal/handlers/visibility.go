@@ -0,0 +1,590 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotchu-backend/internal/config"
+	"gotchu-backend/internal/middleware"
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/auth"
+	"gotchu-backend/pkg/redis"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrProfilePrivate is returned when a private profile is requested without a valid share link
+	ErrProfilePrivate = errors.New("profile is private")
+	// ErrProfileLocked is returned when a password-protected profile has not been unlocked
+	ErrProfileLocked = errors.New("profile is password protected")
+)
+
+const (
+	// profileUnlockTTL is how long a password unlock stays valid
+	profileUnlockTTL = time.Hour
+	// profileUnlockCookiePrefix prefixes the per-profile unlock cookie name
+	profileUnlockCookiePrefix = "profile_unlock_"
+	// maxShareLinkTTL caps how long a share link can stay valid
+	maxShareLinkTTL = 365 * 24 * time.Hour
+	// shareLinkContextKey holds the ID of the share link a request was let in with
+	shareLinkContextKey = "profile_share_link_id"
+)
+
+// ProfileAccess applies profile visibility rules consistently across public endpoints
+type ProfileAccess struct {
+	db     *gorm.DB
+	secret []byte
+}
+
+// NewProfileAccess creates a new profile access checker
+func NewProfileAccess(db *gorm.DB, secret string) *ProfileAccess {
+	return &ProfileAccess{
+		db:     db,
+		secret: []byte(secret),
+	}
+}
+
+// Check returns nil if the current request may view the given user's profile.
+// Profiles that are viewable but not listed are marked noindex for crawlers.
+func (pa *ProfileAccess) Check(c *gin.Context, user *models.User) error {
+	if currentUser, ok := middleware.GetCurrentUser(c); ok && currentUser.ID == user.ID {
+		return nil
+	}
+
+	switch profileVisibility(user) {
+	case models.ProfileVisibilityPublic:
+		return nil
+	case models.ProfileVisibilityUnlisted:
+		c.Header("X-Robots-Tag", "noindex, nofollow")
+		return nil
+	case models.ProfileVisibilityPassword:
+		if pa.hasValidUnlock(c, user) || pa.hasValidShareLink(c, user) {
+			c.Header("X-Robots-Tag", "noindex, nofollow")
+			return nil
+		}
+		return ErrProfileLocked
+	default:
+		if pa.hasValidShareLink(c, user) {
+			c.Header("X-Robots-Tag", "noindex, nofollow")
+			return nil
+		}
+		return ErrProfilePrivate
+	}
+}
+
+// profileVisibility resolves the effective visibility, honoring the legacy is_public flag
+func profileVisibility(user *models.User) models.ProfileVisibility {
+	visibility := user.Visibility
+	if visibility == "" {
+		visibility = models.ProfileVisibilityPublic
+	}
+	if !user.IsPublic && visibility == models.ProfileVisibilityPublic {
+		return models.ProfileVisibilityPrivate
+	}
+	return visibility
+}
+
+// profileAccessError maps an access error to an HTTP status, message and error code
+func profileAccessError(err error) (int, string, string) {
+	if errors.Is(err, ErrProfileLocked) {
+		return http.StatusUnauthorized, "Profile is password protected", "PROFILE_LOCKED"
+	}
+	return http.StatusForbidden, "Profile is private", "PROFILE_PRIVATE"
+}
+
+// sign computes a URL-safe HMAC signature over the given payload
+func (pa *ProfileAccess) sign(payload string) string {
+	mac := hmac.New(sha256.New, pa.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18])
+}
+
+// unlockToken builds a signed unlock token bound to the profile's current password
+func (pa *ProfileAccess) unlockToken(user *models.User, expiresAt time.Time) string {
+	expires := expiresAt.Unix()
+	signature := pa.sign(fmt.Sprintf("unlock:%d:%d:%s", user.ID, expires, getStringValue(user.ProfilePasswordHash)))
+	return fmt.Sprintf("%d.%d.%s", user.ID, expires, signature)
+}
+
+// hasValidUnlock checks the unlock cookie or X-Profile-Unlock header
+func (pa *ProfileAccess) hasValidUnlock(c *gin.Context, user *models.User) bool {
+	if user.ProfilePasswordHash == nil {
+		return false
+	}
+
+	token := c.GetHeader("X-Profile-Unlock")
+	if token == "" {
+		token, _ = c.Cookie(fmt.Sprintf("%s%d", profileUnlockCookiePrefix, user.ID))
+	}
+	if token == "" {
+		return false
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil || uint(userID) != user.ID {
+		return false
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+
+	expected := pa.unlockToken(user, time.Unix(expires, 0))
+	return hmac.Equal([]byte(expected), []byte(token))
+}
+
+// shareToken builds the signed token for a share link
+func (pa *ProfileAccess) shareToken(link *models.ProfileShareLink) string {
+	var expires int64
+	if link.ExpiresAt != nil {
+		expires = link.ExpiresAt.Unix()
+	}
+	signature := pa.sign(fmt.Sprintf("share:%d:%d:%s:%d", link.ID, link.UserID, link.Nonce, expires))
+	return fmt.Sprintf("%d.%s", link.ID, signature)
+}
+
+// hasValidShareLink checks the share query parameter or X-Profile-Share header
+func (pa *ProfileAccess) hasValidShareLink(c *gin.Context, user *models.User) bool {
+	token := c.Query("share")
+	if token == "" {
+		token = c.GetHeader("X-Profile-Share")
+	}
+	if token == "" {
+		return false
+	}
+
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	linkID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return false
+	}
+
+	var link models.ProfileShareLink
+	if err := pa.db.Where("id = ? AND user_id = ?", linkID, user.ID).First(&link).Error; err != nil {
+		return false
+	}
+	if link.RevokedAt != nil || (link.ExpiresAt != nil && time.Now().After(*link.ExpiresAt)) {
+		return false
+	}
+	if !hmac.Equal([]byte(pa.shareToken(&link)), []byte(token)) {
+		return false
+	}
+
+	c.Set(shareLinkContextKey, link.ID)
+	return true
+}
+
+// RecordShareLinkUse counts a use of the share link the request was let in
+// with, if any. A page load checks access on several endpoints, so only the
+// profile endpoint records uses, once per visit.
+func (pa *ProfileAccess) RecordShareLinkUse(c *gin.Context) {
+	linkID, ok := c.Get(shareLinkContextKey)
+	if !ok {
+		return
+	}
+	pa.db.Model(&models.ProfileShareLink{}).Where("id = ?", linkID).UpdateColumns(map[string]interface{}{
+		"use_count":    gorm.Expr("use_count + ?", 1),
+		"last_used_at": time.Now(),
+	})
+}
+
+// VisibilityHandler handles profile visibility settings, unlocks and share links
+type VisibilityHandler struct {
+	db          *gorm.DB
+	redisClient *redis.Client
+	authService *auth.Service
+	access      *ProfileAccess
+	config      *config.Config
+}
+
+// NewVisibilityHandler creates a new visibility handler
+func NewVisibilityHandler(db *gorm.DB, redisClient *redis.Client, authService *auth.Service, access *ProfileAccess, cfg *config.Config) *VisibilityHandler {
+	return &VisibilityHandler{
+		db:          db,
+		redisClient: redisClient,
+		authService: authService,
+		access:      access,
+		config:      cfg,
+	}
+}
+
+// UpdateVisibilityRequest represents a visibility change request
+type UpdateVisibilityRequest struct {
+	Visibility models.ProfileVisibility `json:"visibility" binding:"required,oneof=public unlisted password private"`
+	Password   string                   `json:"password"`
+}
+
+// UnlockProfileRequest represents a password unlock request
+type UnlockProfileRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// CreateShareLinkRequest represents a share link creation request
+type CreateShareLinkRequest struct {
+	Label          *string `json:"label" binding:"omitempty,max=100"`
+	ExpiresInHours int     `json:"expires_in_hours" binding:"omitempty,min=0"`
+}
+
+// GetVisibility returns the authenticated user's visibility settings
+func (h *VisibilityHandler) GetVisibility(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	var dbUser models.User
+	if err := h.db.Where("id = ?", user.ID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to retrieve user data",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Visibility retrieved successfully",
+		Data: gin.H{
+			"visibility":        profileVisibility(&dbUser),
+			"has_password":      dbUser.ProfilePasswordHash != nil,
+			"discovery_opt_out": dbUser.DiscoveryOptOut,
+		},
+	})
+}
+
+// UpdateVisibility changes the authenticated user's visibility mode
+func (h *VisibilityHandler) UpdateVisibility(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	var req UpdateVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	var dbUser models.User
+	if err := h.db.Where("id = ?", user.ID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to retrieve user data",
+		})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"visibility": req.Visibility,
+		"is_public":  req.Visibility == models.ProfileVisibilityPublic || req.Visibility == models.ProfileVisibilityUnlisted,
+		"updated_at": time.Now(),
+	}
+
+	if req.Password != "" {
+		if len(req.Password) < 4 || len(req.Password) > 72 {
+			c.JSON(http.StatusBadRequest, DashboardResponse{
+				Success: false,
+				Message: "Profile password must be between 4 and 72 characters",
+			})
+			return
+		}
+		hash, err := h.authService.HashPassword(req.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, DashboardResponse{
+				Success: false,
+				Message: "Failed to process password",
+			})
+			return
+		}
+		updates["profile_password_hash"] = hash
+	} else if req.Visibility == models.ProfileVisibilityPassword && dbUser.ProfilePasswordHash == nil {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: "A password is required for password-protected profiles",
+		})
+		return
+	}
+
+	if err := h.db.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to update visibility",
+		})
+		return
+	}
+
+	if h.redisClient != nil {
+		h.redisClient.InvalidateUserCache(user.ID)
+		h.redisClient.Delete(fmt.Sprintf("dashboard:user:%d", user.ID))
+	}
+
+	c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Visibility updated successfully",
+		Data: gin.H{
+			"visibility": req.Visibility,
+		},
+	})
+}
+
+// UnlockProfile verifies a profile password and issues a short-lived unlock cookie
+func (h *VisibilityHandler) UnlockProfile(c *gin.Context) {
+	username := c.Param("username")
+
+	var req UnlockProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.db.Where("(username = ? OR alias = ?) AND is_active = ?", username, username, true).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, DashboardResponse{
+			Success: false,
+			Message: "User not found",
+		})
+		return
+	}
+
+	if profileVisibility(&user) != models.ProfileVisibilityPassword || user.ProfilePasswordHash == nil {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: "Profile is not password protected",
+		})
+		return
+	}
+
+	// Limit unlock attempts per profile and client IP
+	if h.redisClient != nil {
+		result, err := h.redisClient.CheckRateLimit(fmt.Sprintf("profile_unlock:%d:%s", user.ID, c.ClientIP()), 10, 15*time.Minute)
+		if err == nil && result.Exceeded {
+			c.JSON(http.StatusTooManyRequests, DashboardResponse{
+				Success: false,
+				Message: "Too many unlock attempts. Please try again later.",
+			})
+			return
+		}
+	}
+
+	if !h.authService.VerifyPassword(req.Password, *user.ProfilePasswordHash) {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Incorrect password",
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(profileUnlockTTL)
+	token := h.access.unlockToken(&user, expiresAt)
+
+	if h.config.IsProduction() {
+		c.SetSameSite(http.SameSiteNoneMode)
+	} else {
+		c.SetSameSite(http.SameSiteLaxMode)
+	}
+	c.SetCookie(fmt.Sprintf("%s%d", profileUnlockCookiePrefix, user.ID), token, int(profileUnlockTTL.Seconds()), "/", "", h.config.IsProduction(), true)
+
+	c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Profile unlocked",
+		Data: gin.H{
+			"unlock_token": token,
+			"expires_at":   expiresAt,
+		},
+	})
+}
+
+// ListShareLinks lists the authenticated user's share links
+func (h *VisibilityHandler) ListShareLinks(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	var links []models.ProfileShareLink
+	if err := h.db.Where("user_id = ?", user.ID).Order("created_at DESC").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to retrieve share links",
+		})
+		return
+	}
+
+	result := make([]gin.H, 0, len(links))
+	for i := range links {
+		result = append(result, h.shareLinkResponse(user.Username, &links[i]))
+	}
+
+	c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Share links retrieved successfully",
+		Data: gin.H{
+			"share_links": result,
+		},
+	})
+}
+
+// CreateShareLink creates a new signed share link for the authenticated user
+func (h *VisibilityHandler) CreateShareLink(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	var req CreateShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to generate share link",
+		})
+		return
+	}
+
+	link := models.ProfileShareLink{
+		UserID: user.ID,
+		Label:  req.Label,
+		Nonce:  hex.EncodeToString(nonce),
+	}
+	if req.ExpiresInHours > 0 {
+		ttl := time.Duration(req.ExpiresInHours) * time.Hour
+		if ttl > maxShareLinkTTL {
+			ttl = maxShareLinkTTL
+		}
+		expiresAt := time.Now().Add(ttl)
+		link.ExpiresAt = &expiresAt
+	}
+
+	if err := h.db.Create(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to create share link",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, DashboardResponse{
+		Success: true,
+		Message: "Share link created successfully",
+		Data: gin.H{
+			"share_link": h.shareLinkResponse(user.Username, &link),
+		},
+	})
+}
+
+// RevokeShareLink revokes one of the authenticated user's share links
+func (h *VisibilityHandler) RevokeShareLink(c *gin.Context) {
+	user, exists := middleware.GetCurrentUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, DashboardResponse{
+			Success: false,
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Message: "Invalid share link ID",
+		})
+		return
+	}
+
+	result := h.db.Model(&models.ProfileShareLink{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", linkID, user.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Message: "Failed to revoke share link",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, DashboardResponse{
+			Success: false,
+			Message: "Share link not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Share link revoked successfully",
+	})
+}
+
+// shareLinkResponse formats a share link including its shareable URL
+func (h *VisibilityHandler) shareLinkResponse(username string, link *models.ProfileShareLink) gin.H {
+	status := "active"
+	if link.RevokedAt != nil {
+		status = "revoked"
+	} else if link.ExpiresAt != nil && time.Now().After(*link.ExpiresAt) {
+		status = "expired"
+	}
+
+	response := gin.H{
+		"id":           link.ID,
+		"label":        link.Label,
+		"status":       status,
+		"expires_at":   link.ExpiresAt,
+		"revoked_at":   link.RevokedAt,
+		"last_used_at": link.LastUsedAt,
+		"use_count":    link.UseCount,
+		"created_at":   link.CreatedAt,
+	}
+	if status == "active" {
+		response["url"] = fmt.Sprintf("%s/%s?share=%s", h.config.FrontendURL, username, h.access.shareToken(link))
+	}
+	return response
+}
@@ -1,30 +1,30 @@
-package middleware
-
-import (
-	"strings"
-	"time"
-
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
-)
-
-// SetupCORS sets up CORS middleware
-func SetupCORS(allowedOrigins string) gin.HandlerFunc {
-	origins := strings.Split(allowedOrigins, ",")
-	
-	// Clean up origins
-	for i, origin := range origins {
-		origins[i] = strings.TrimSpace(origin)
-	}
-
-	config := cors.Config{
-		AllowOrigins:     origins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Session-ID", "X-Requested-With", "Cache-Control"},
-		ExposeHeaders:    []string{"Content-Length", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}
-
-	return cors.New(config)
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCORS sets up CORS middleware
+func SetupCORS(allowedOrigins string) gin.HandlerFunc {
+	origins := strings.Split(allowedOrigins, ",")
+	
+	// Clean up origins
+	for i, origin := range origins {
+		origins[i] = strings.TrimSpace(origin)
+	}
+
+	config := cors.Config{
+		AllowOrigins:     origins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Session-ID", "X-Requested-With", "Cache-Control", "X-Profile-Unlock", "X-Profile-Share"},
+		ExposeHeaders:    []string{"Content-Length", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}
+
+	return cors.New(config)
 }
\ No newline at end of file
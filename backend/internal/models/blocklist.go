@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Blocked domain sources
+const (
+	BlockedDomainSourceAdmin = "admin"
+	BlockedDomainSourceFile  = "file"
+)
+
+// BlockedDomain is a domain that links may not point to; subdomains are blocked too
+type BlockedDomain struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Domain    string    `json:"domain" gorm:"not null;size:253;uniqueIndex"` // lower-case ASCII (punycode) form
+	Reason    string    `json:"reason" gorm:"size:255"`
+	Source    string    `json:"source" gorm:"not null;size:20;default:'admin'"`
+	CreatedBy *uint     `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
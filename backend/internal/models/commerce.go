@@ -0,0 +1,68 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Availability values; which ones apply depends on the link type
+const (
+	AvailabilityInStock     = "in_stock"
+	AvailabilityLowStock    = "low_stock"
+	AvailabilityOutOfStock  = "out_of_stock"
+	AvailabilityPreorder    = "preorder"
+	AvailabilitySold        = "sold"
+	AvailabilityAvailable   = "available"
+	AvailabilityBooked      = "booked"
+	AvailabilityUnavailable = "unavailable"
+)
+
+// CommerceAvailability lists the availability values accepted per link type
+var CommerceAvailability = map[LinkType][]string{
+	LinkTypeProduct:     {AvailabilityInStock, AvailabilityLowStock, AvailabilityOutOfStock, AvailabilityPreorder},
+	LinkTypeService:     {AvailabilityAvailable, AvailabilityBooked, AvailabilityUnavailable},
+	LinkTypeMarketplace: {AvailabilityInStock, AvailabilityOutOfStock, AvailabilitySold},
+}
+
+// LinkCommerce holds storefront details for PRODUCT, SERVICE and MARKETPLACE
+// links. Amounts are decimal numbers in the currency's major unit, kept as
+// json.Number so they never pass through a float.
+type LinkCommerce struct {
+	Price          *json.Number `json:"price,omitempty"`
+	CompareAtPrice *json.Number `json:"compare_at_price,omitempty"` // original price shown struck through
+	Currency       string       `json:"currency,omitempty"`         // ISO 4217, e.g. USD
+	Availability   string       `json:"availability,omitempty"`
+	Images         []string     `json:"images,omitempty"` // gallery, first image is the cover
+}
+
+// IsEmpty reports whether no commerce details are set
+func (m *LinkCommerce) IsEmpty() bool {
+	return m == nil || (m.Price == nil && m.CompareAtPrice == nil && m.Currency == "" && m.Availability == "" && len(m.Images) == 0)
+}
+
+// IsCommerceType reports whether links of this type can carry commerce details
+func (t LinkType) IsCommerceType() bool {
+	_, ok := CommerceAvailability[t]
+	return ok
+}
+
+// Value stores the commerce details as JSON
+func (m LinkCommerce) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+// Scan reads the commerce details from JSON
+func (m *LinkCommerce) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	case nil:
+		*m = LinkCommerce{}
+		return nil
+	default:
+		return fmt.Errorf("unsupported type for LinkCommerce: %T", value)
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// LinkHealth is the latest health check result for a link
+type LinkHealth struct {
+	LinkID              uint       `json:"link_id" gorm:"primaryKey;autoIncrement:false"`
+	StatusCode          *int       `json:"status_code,omitempty"`
+	FinalURL            *string    `json:"final_url,omitempty" gorm:"size:1000"`
+	LatencyMs           int        `json:"latency_ms"`
+	Error               *string    `json:"error,omitempty" gorm:"size:255"`
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"default:0"`
+	IsBroken            bool       `json:"is_broken" gorm:"default:false;index"`
+	BrokenSince         *time.Time `json:"broken_since,omitempty"`
+	NotifiedAt          *time.Time `json:"notified_at,omitempty"`
+	CheckedAt           time.Time  `json:"checked_at" gorm:"index"`
+}
+
+// TableName specifies the table name for LinkHealth
+func (LinkHealth) TableName() string {
+	return "link_health"
+}
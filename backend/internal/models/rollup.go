@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Rollup dimensions. Every day has a "total" row with an empty value; the
+// others split the same events by one attribute, with an empty value when
+// the attribute is unknown.
+const (
+	RollupTotal    = "total"
+	RollupCountry  = "country"
+	RollupDevice   = "device"
+	RollupBrowser  = "browser"
+	RollupReferrer = "referrer" // referring domain, or the traffic source tag of tagged visits
+)
+
+// DailyProfileStat is the number of profile views a user received on a UTC
+// day, overall or for one value of a dimension
+type DailyProfileStat struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Day       time.Time `json:"day" gorm:"primaryKey;type:date"`
+	Dimension string    `json:"dimension" gorm:"primaryKey;size:20"`
+	Value     string    `json:"value" gorm:"primaryKey;size:255"`
+	Views     int       `json:"views" gorm:"not null;default:0"`
+}
+
+// DailyLinkStat is the number of clicks a link received on a UTC day,
+// overall or for one value of a dimension
+type DailyLinkStat struct {
+	LinkID    uint      `json:"link_id" gorm:"primaryKey;autoIncrement:false"`
+	Day       time.Time `json:"day" gorm:"primaryKey;type:date"`
+	Dimension string    `json:"dimension" gorm:"primaryKey;size:20"`
+	Value     string    `json:"value" gorm:"primaryKey;size:255"`
+	UserID    uint      `json:"user_id" gorm:"not null"`
+	Clicks    int       `json:"clicks" gorm:"not null;default:0"`
+}
+
+// DailyBotStat is the number of profile views or link clicks of a user's
+// profile that were filtered out as automated traffic on a UTC day, by the
+// reason they were filtered. Filtered traffic never reaches the raw views and
+// clicks, so it is counted here as it arrives instead of being rolled up.
+type DailyBotStat struct {
+	UserID uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Day    time.Time `json:"day" gorm:"primaryKey;type:date"`
+	Kind   string    `json:"kind" gorm:"primaryKey;size:10"` // "view" or "click"
+	Reason string    `json:"reason" gorm:"primaryKey;size:20"`
+	Count  int       `json:"count" gorm:"not null;default:0"`
+}
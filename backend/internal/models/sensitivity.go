@@ -0,0 +1,28 @@
+package models
+
+// LinkSensitivity marks links whose target visitors should be warned about
+type LinkSensitivity string
+
+const (
+	LinkSensitivityNone    LinkSensitivity = ""
+	LinkSensitivityNSFW    LinkSensitivity = "nsfw"    // adult content; visitors confirm they are 18+
+	LinkSensitivitySpoiler LinkSensitivity = "spoiler" // visitors confirm they want to see it
+)
+
+// ParseLinkSensitivity converts a request value; "none" and "" clear the flag
+func ParseLinkSensitivity(value string) (LinkSensitivity, bool) {
+	switch value {
+	case "", "none":
+		return LinkSensitivityNone, true
+	case string(LinkSensitivityNSFW):
+		return LinkSensitivityNSFW, true
+	case string(LinkSensitivitySpoiler):
+		return LinkSensitivitySpoiler, true
+	}
+	return LinkSensitivityNone, false
+}
+
+// IsSensitive reports whether visitors must pass an interstitial before the link opens
+func (l *Link) IsSensitive() bool {
+	return l.Sensitivity != LinkSensitivityNone
+}
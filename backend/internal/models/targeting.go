@@ -0,0 +1,102 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Targeting devices, matching analytics.DetectDevice
+const (
+	TargetDeviceMobile  = "mobile"
+	TargetDeviceTablet  = "tablet"
+	TargetDeviceDesktop = "desktop"
+)
+
+// TargetOperatingSystems lists the OS names accepted for alternate URLs, matching analytics.DetectDevice
+var TargetOperatingSystems = []string{"ios", "android", "windows", "macos", "linux", "chromeos"}
+
+// LinkVariantDefault is the variant recorded when a link is served with its own URL
+const LinkVariantDefault = "default"
+
+// LinkTargeting restricts who a link is shown to and can swap its URL per
+// operating system. Empty lists match every visitor.
+type LinkTargeting struct {
+	Countries []string          `json:"countries,omitempty"` // ISO 3166-1 alpha-2 allow list
+	Devices   []string          `json:"devices,omitempty"`   // mobile, tablet, desktop
+	OSURLs    map[string]string `json:"os_urls,omitempty"`   // alternate URL by OS, e.g. App Store for ios
+}
+
+// LinkAudience describes the visitor a link is resolved for
+type LinkAudience struct {
+	Country string // ISO 3166-1 alpha-2, empty when unknown
+	Device  string
+	OS      string
+}
+
+// IsEmpty reports whether the targeting has no rules
+func (t *LinkTargeting) IsEmpty() bool {
+	return t == nil || (len(t.Countries) == 0 && len(t.Devices) == 0 && len(t.OSURLs) == 0)
+}
+
+// NeedsCountry reports whether evaluating the rules requires the visitor's country
+func (t *LinkTargeting) NeedsCountry() bool {
+	return t != nil && len(t.Countries) > 0
+}
+
+// Value stores the targeting as JSON
+func (t LinkTargeting) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+// Scan reads the targeting from JSON
+func (t *LinkTargeting) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	case nil:
+		*t = LinkTargeting{}
+		return nil
+	default:
+		return fmt.Errorf("unsupported type for LinkTargeting: %T", value)
+	}
+}
+
+// TargetFor evaluates the link's targeting rules for a visitor. It reports
+// whether the link is shown and, if so, the URL to serve and the variant
+// recorded with clicks. Visitors whose country is unknown do not match a
+// country allow list.
+func (l *Link) TargetFor(audience LinkAudience) (visible bool, target *string, variant string) {
+	t := l.Targeting
+	if t.IsEmpty() {
+		return true, l.URL, LinkVariantDefault
+	}
+
+	if len(t.Countries) > 0 && !containsFold(t.Countries, audience.Country) {
+		return false, nil, ""
+	}
+	if len(t.Devices) > 0 && !containsFold(t.Devices, audience.Device) {
+		return false, nil, ""
+	}
+
+	if alternate, ok := t.OSURLs[strings.ToLower(audience.OS)]; ok && alternate != "" {
+		return true, &alternate, "os:" + strings.ToLower(audience.OS)
+	}
+	return true, l.URL, LinkVariantDefault
+}
+
+// containsFold reports whether value is in list, ignoring case; empty values never match
+func containsFold(list []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// LinkVariant is one arm of a link A/B test. Empty fields fall back to the link's own values.
+type LinkVariant struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	LinkID      uint      `json:"link_id" gorm:"not null;index"`
+	Name        string    `json:"name" gorm:"not null;size:50"`
+	Title       *string   `json:"title,omitempty" gorm:"size:255"`
+	URL         *string   `json:"url,omitempty" gorm:"size:1000"`
+	Icon        *string   `json:"icon,omitempty" gorm:"size:500"`
+	Color       *string   `json:"color,omitempty" gorm:"size:20"`
+	Weight      int       `json:"weight" gorm:"not null;default:1"`
+	Impressions int64     `json:"impressions" gorm:"default:0"`
+	Clicks      int64     `json:"clicks" gorm:"default:0"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// MaxLinkVariants caps the number of variants a link can test at once
+const MaxLinkVariants = 5
+
+// HasExperiment reports whether the link is running an A/B test; a single variant is not served
+func (l *Link) HasExperiment() bool {
+	return len(l.Variants) >= 2
+}
+
+// ApplyVariant overrides the link's fields with the variant's non-empty values
+func (l *Link) ApplyVariant(variant *LinkVariant) {
+	if variant.Title != nil && *variant.Title != "" {
+		l.Title = *variant.Title
+	}
+	if variant.URL != nil && *variant.URL != "" {
+		l.URL = variant.URL
+	}
+	if variant.Icon != nil && *variant.Icon != "" {
+		l.Icon = variant.Icon
+	}
+	if variant.Color != nil && *variant.Color != "" {
+		l.Color = variant.Color
+	}
+}
@@ -0,0 +1,90 @@
+package abtest
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+)
+
+const (
+	// DefaultConfidence is the confidence level used to call a result significant
+	DefaultConfidence = 0.95
+	// MinImpressions is the smallest sample per arm before a result can be significant
+	MinImpressions = 100
+)
+
+// Assign deterministically picks an arm index for key according to weights,
+// so the same visitor keeps seeing the same arm. Non-positive weights are
+// never picked; -1 is returned when no arm can be picked.
+func Assign(key string, weights []int) int {
+	total := 0
+	for _, weight := range weights {
+		if weight > 0 {
+			total += weight
+		}
+	}
+	if total == 0 {
+		return -1
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	point := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+	for i, weight := range weights {
+		if weight <= 0 {
+			continue
+		}
+		if point < weight {
+			return i
+		}
+		point -= weight
+	}
+	return -1
+}
+
+// Arm holds the observed counts for one variant
+type Arm struct {
+	Impressions int64
+	Clicks      int64
+}
+
+// Rate returns the arm's click-through rate
+func (a Arm) Rate() float64 {
+	if a.Impressions <= 0 {
+		return 0
+	}
+	return float64(a.Clicks) / float64(a.Impressions)
+}
+
+// Comparison is the result of a two-proportion z-test between two arms
+type Comparison struct {
+	Lift        float64 `json:"lift"` // relative change in click-through rate against the baseline
+	ZScore      float64 `json:"z_score"`
+	PValue      float64 `json:"p_value"`
+	Significant bool    `json:"significant"`
+}
+
+// Compare tests whether variant's click-through rate differs from baseline's
+// at the given confidence level (two-sided)
+func Compare(baseline, variant Arm, confidence float64) Comparison {
+	result := Comparison{PValue: 1}
+	if baseline.Impressions <= 0 || variant.Impressions <= 0 {
+		return result
+	}
+
+	p1, p2 := baseline.Rate(), variant.Rate()
+	if p1 > 0 {
+		result.Lift = (p2 - p1) / p1
+	}
+
+	pooled := float64(baseline.Clicks+variant.Clicks) / float64(baseline.Impressions+variant.Impressions)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(baseline.Impressions) + 1/float64(variant.Impressions)))
+	if se == 0 {
+		return result
+	}
+
+	result.ZScore = (p2 - p1) / se
+	result.PValue = math.Erfc(math.Abs(result.ZScore) / math.Sqrt2)
+	result.Significant = result.PValue < 1-confidence &&
+		baseline.Impressions >= MinImpressions && variant.Impressions >= MinImpressions
+	return result
+}
@@ -0,0 +1,59 @@
+package analytics
+
+import (
+	"container/list"
+	"sync"
+)
+
+// geoCache is a fixed-size LRU cache of locations by IP address
+type geoCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // most recently used first
+	entries  map[string]*list.Element
+}
+
+type geoCacheEntry struct {
+	ip       string
+	location GeoLocation
+}
+
+func newGeoCache(capacity int) *geoCache {
+	return &geoCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element, capacity),
+	}
+}
+
+// get returns a copy of the cached location for an IP address
+func (c *geoCache) get(ip string) (*GeoLocation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[ip]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	location := element.Value.(*geoCacheEntry).location
+	return &location, true
+}
+
+// add caches a location, evicting the least recently used one when full
+func (c *geoCache) add(ip string, location *GeoLocation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[ip]; ok {
+		element.Value.(*geoCacheEntry).location = *location
+		c.order.MoveToFront(element)
+		return
+	}
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*geoCacheEntry).ip)
+	}
+	c.entries[ip] = c.order.PushFront(&geoCacheEntry{ip: ip, location: *location})
+}
@@ -0,0 +1,181 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+var (
+	// ErrLocationNotFound is returned when a database has no entry for an IP
+	ErrLocationNotFound = errors.New("no location for IP address")
+	// ErrGeoDatabaseUnavailable is returned while no database file could be loaded
+	ErrGeoDatabaseUnavailable = errors.New("geolocation database not loaded")
+)
+
+// mmdbRecord holds the fields read from a City or Country database. MaxMind
+// GeoIP2/GeoLite2 and DB-IP databases share this layout.
+type mmdbRecord struct {
+	Country struct {
+		ISOCode string    `maxminddb:"iso_code"`
+		Names   mmdbNames `maxminddb:"names"`
+	} `maxminddb:"country"`
+	City struct {
+		Names mmdbNames `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Subdivisions []struct {
+		Names mmdbNames `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	Location struct {
+		TimeZone string `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+}
+
+// mmdbNames decodes only the English name, which avoids building a map per lookup
+type mmdbNames struct {
+	EN string `maxminddb:"en"`
+}
+
+// MMDBResolver looks up locations in a local MMDB file. The file is memory
+// mapped, so lookups take microseconds and never touch the network. It is
+// checked for changes periodically and reloaded in place, so a database
+// update job only has to replace the file.
+type MMDBResolver struct {
+	path string
+
+	mu      sync.RWMutex
+	reader  *maxminddb.Reader
+	modTime time.Time
+
+	quit chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewMMDBResolver opens the database at path and watches it for changes every
+// reloadInterval. A missing or unreadable file is not fatal: lookups fail with
+// ErrGeoDatabaseUnavailable until a valid file appears.
+func NewMMDBResolver(path string, reloadInterval time.Duration) *MMDBResolver {
+	r := &MMDBResolver{
+		path: path,
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := r.reload(); err != nil {
+		log.Printf("⚠️ Warning: GeoIP database not loaded: %v", err)
+	}
+
+	if reloadInterval <= 0 {
+		close(r.done)
+		return r
+	}
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(reloadInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				// A missing file was reported when the resolver was created
+				if err := r.reload(); err != nil && !errors.Is(err, os.ErrNotExist) {
+					log.Printf("⚠️ Warning: Failed to reload GeoIP database: %v", err)
+				}
+			case <-r.quit:
+				return
+			}
+		}
+	}()
+	return r
+}
+
+// reload opens the file if it changed since it was last loaded and swaps it in
+func (r *MMDBResolver) reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	unchanged := r.reader != nil && info.ModTime().Equal(r.modTime)
+	r.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	reader, err := maxminddb.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", r.path, err)
+	}
+
+	// Lookups hold the read lock, so the old file is unmapped only once none use it
+	r.mu.Lock()
+	old := r.reader
+	r.reader = reader
+	r.modTime = info.ModTime()
+	r.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+
+	log.Printf("🌍 Loaded GeoIP database %s (%s, built %s)", r.path, reader.Metadata.DatabaseType,
+		time.Unix(int64(reader.Metadata.BuildEpoch), 0).UTC().Format("2006-01-02"))
+	return nil
+}
+
+// GetLocation looks up an IP address in the database
+func (r *MMDBResolver) GetLocation(ipAddress string) (*GeoLocation, error) {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", ipAddress)
+	}
+
+	var record mmdbRecord
+	r.mu.RLock()
+	if r.reader == nil {
+		r.mu.RUnlock()
+		return nil, ErrGeoDatabaseUnavailable
+	}
+	_, found, err := r.reader.LookupNetwork(ip, &record)
+	r.mu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("geolocation lookup failed: %v", err)
+	}
+	if !found || record.Country.ISOCode == "" {
+		return nil, ErrLocationNotFound
+	}
+
+	location := &GeoLocation{
+		Country:     record.Country.Names.EN,
+		CountryCode: record.Country.ISOCode,
+		City:        record.City.Names.EN,
+		Timezone:    record.Location.TimeZone,
+	}
+	if location.Country == "" {
+		location.Country = record.Country.ISOCode
+	}
+	if len(record.Subdivisions) > 0 {
+		location.Region = record.Subdivisions[0].Names.EN
+	}
+	return location, nil
+}
+
+// Close stops watching the file and unmaps it
+func (r *MMDBResolver) Close() {
+	r.once.Do(func() { close(r.quit) })
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reader != nil {
+		r.reader.Close()
+		r.reader = nil
+	}
+}
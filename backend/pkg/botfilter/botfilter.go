@@ -0,0 +1,163 @@
+// Package botfilter tells crawlers, link-preview bots, uptime monitors and
+// scripted clients apart from people, so they can be kept out of profile view
+// and link click counts. Requests are judged on their user agent, on headers
+// browsers always send and on how fast the same address keeps coming back.
+package botfilter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gotchu-backend/pkg/redis"
+)
+
+// Reasons a request was classified as automated
+const (
+	ReasonCrawler    = "crawler"    // search engine and AI crawlers
+	ReasonPreview    = "preview"    // link unfurlers of chat apps and social networks, prefetches
+	ReasonMonitor    = "monitor"    // uptime and performance monitors
+	ReasonAutomation = "automation" // HTTP libraries, command line tools and headless browsers
+	ReasonHeaders    = "headers"    // headers no browser would send
+	ReasonRate       = "rate"       // more requests from one address than a person makes
+)
+
+// Signatures are matched against the lowercased user agent in this order, so
+// the more specific lists come before the catch-all crawler words
+var signatures = []struct {
+	reason   string
+	patterns []string
+}{
+	{ReasonPreview, []string{
+		"facebookexternalhit", "facebookcatalog", "meta-externalagent", "twitterbot", "slackbot",
+		"slack-imgproxy", "discordbot", "telegrambot", "whatsapp", "linkedinbot", "skypeuripreview",
+		"redditbot", "pinterestbot", "embedly", "iframely", "vkshare", "bitlybot", "mastodon",
+		"pleroma", "misskey", "google-pagerenderer", "googleimageproxy", "snapchat-preview",
+	}},
+	{ReasonMonitor, []string{
+		"uptimerobot", "pingdom", "statuscake", "site24x7", "betteruptime", "better uptime",
+		"uptime-kuma", "uptime kuma", "newrelicpinger", "datadogsynthetics", "checkly", "freshping",
+		"hetrixtools", "nodeping", "gtmetrix", "chrome-lighthouse", "pagespeed",
+	}},
+	{ReasonAutomation, []string{
+		"headlesschrome", "phantomjs", "puppeteer", "playwright", "selenium", "webdriver",
+		"curl/", "wget/", "httpie", "python-requests", "python-urllib", "aiohttp", "httpx",
+		"go-http-client", "okhttp", "java/", "apache-httpclient", "libwww-perl", "axios/",
+		"node-fetch", "undici", "postmanruntime", "insomnia", "scrapy",
+	}},
+	{ReasonCrawler, []string{
+		"googlebot", "bingbot", "yandexbot", "baiduspider", "duckduckbot", "slurp", "applebot",
+		"ahrefsbot", "semrushbot", "mj12bot", "dotbot", "petalbot", "bytespider", "gptbot",
+		"chatgpt-user", "oai-searchbot", "claudebot", "anthropic-ai", "perplexitybot", "ccbot",
+		"amazonbot", "facebookbot", "bot/", "bot;", "crawl", "spider", "archiver",
+	}},
+}
+
+// MatchUserAgent returns the reason a user agent belongs to a known bot, or
+// "" if it matches none of the signatures
+func MatchUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	for _, list := range signatures {
+		for _, pattern := range list.patterns {
+			if strings.Contains(ua, pattern) {
+				return list.reason
+			}
+		}
+	}
+	return ""
+}
+
+// Config controls the request-rate signal
+type Config struct {
+	RateLimit  int           // tracked requests one address can make per window
+	RateWindow time.Duration // length of the fixed window requests are counted in
+}
+
+// DefaultConfig returns the settings used in production
+func DefaultConfig() Config {
+	return Config{
+		RateLimit:  60,
+		RateWindow: time.Minute,
+	}
+}
+
+// Filter classifies requests. It is safe for concurrent use.
+type Filter struct {
+	redisClient *redis.Client
+	config      Config
+}
+
+// NewFilter creates a filter; without Redis the rate signal is skipped
+func NewFilter(redisClient *redis.Client, config Config) *Filter {
+	if config.RateLimit <= 0 {
+		config.RateLimit = DefaultConfig().RateLimit
+	}
+	if config.RateWindow < time.Second {
+		config.RateWindow = DefaultConfig().RateWindow
+	}
+	return &Filter{redisClient: redisClient, config: config}
+}
+
+// Classify returns the reason a request looks automated, or "" if it looks
+// like a person. ipAddress is the client address the request is tracked
+// under. Only requests that pass the user agent and header checks count
+// towards the rate limit.
+func (f *Filter) Classify(r *http.Request, ipAddress string) string {
+	userAgent := r.Header.Get("User-Agent")
+	if userAgent == "" {
+		return ReasonHeaders
+	}
+	if reason := MatchUserAgent(userAgent); reason != "" {
+		return reason
+	}
+	if reason := checkHeaders(r.Header, strings.ToLower(userAgent)); reason != "" {
+		return reason
+	}
+	if f.overRate(ipAddress) {
+		return ReasonRate
+	}
+	return ""
+}
+
+// checkHeaders looks for prefetches and for requests missing headers browsers
+// send on every page load and fetch. A single missing header is tolerated
+// since some proxies and privacy tools strip one; two or more are not.
+func checkHeaders(header http.Header, ua string) string {
+	for _, name := range []string{"Purpose", "Sec-Purpose", "X-Purpose", "X-Moz"} {
+		if value := strings.ToLower(header.Get(name)); strings.Contains(value, "prefetch") || strings.Contains(value, "preview") {
+			return ReasonPreview
+		}
+	}
+
+	missing := 0
+	if header.Get("Accept") == "" {
+		missing++
+	}
+	if header.Get("Accept-Language") == "" {
+		missing++
+	}
+	// Chromium has sent client hints and fetch metadata for years; a user
+	// agent claiming to be Chrome without either is usually a script
+	if strings.Contains(ua, "chrome/") && header.Get("Sec-Ch-Ua") == "" && header.Get("Sec-Fetch-Mode") == "" {
+		missing++
+	}
+	if missing >= 2 {
+		return ReasonHeaders
+	}
+	return ""
+}
+
+// overRate counts a request from an address in the current window and reports
+// whether the address has gone over the limit
+func (f *Filter) overRate(ipAddress string) bool {
+	if f.redisClient == nil || ipAddress == "" {
+		return false
+	}
+	window := time.Now().Unix() / int64(f.config.RateWindow/time.Second)
+	count, err := f.redisClient.IncrementCounter(fmt.Sprintf("bot_rate:%s:%d", ipAddress, window), f.config.RateWindow)
+	if err != nil {
+		return false
+	}
+	return count > int64(f.config.RateLimit)
+}
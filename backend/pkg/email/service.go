@@ -1,253 +1,297 @@
-package email
-
-import (
-	"bytes"
-	"crypto/rand"
-	"encoding/hex"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"time"
-)
-
-type Service struct {
-	apiKey   string
-	fromName string
-	fromEmail string
-	baseURL  string
-}
-
-type EmailRequest struct {
-	From    string `json:"from"`
-	To      []string `json:"to"`
-	Subject string `json:"subject"`
-	HTML    string `json:"html"`
-}
-
-type EmailAddress struct {
-	Email string `json:"email"`
-	Name  string `json:"name,omitempty"`
-}
-
-type EmailResponse struct {
-	ID      string `json:"id"`
-	Message string `json:"message,omitempty"`
-}
-
-// NewService creates a new email service
-func NewService(apiKey, fromName, fromEmail string) *Service {
-	return &Service{
-		apiKey:    apiKey,
-		fromName:  fromName,
-		fromEmail: fromEmail,
-		baseURL:   "https://api.resend.com",
-	}
-}
-
-// GenerateVerificationToken generates a secure verification token
-func (s *Service) GenerateVerificationToken() (string, error) {
-	bytes := make([]byte, 32) // 256-bit token
-	if _, err := rand.Read(bytes); err != nil {
-		return "", fmt.Errorf("failed to generate token: %v", err)
-	}
-	return hex.EncodeToString(bytes), nil
-}
-
-// SendVerificationEmail sends an email verification link
-func (s *Service) SendVerificationEmail(toEmail, username, verificationToken, baseURL string) error {
-	verificationLink := fmt.Sprintf("%s/verify-email?token=%s", baseURL, verificationToken)
-	
-	htmlContent := s.buildVerificationEmailHTML(username, verificationLink)
-	
-	emailReq := EmailRequest{
-		From:    s.fromEmail,
-		To:      []string{toEmail},
-		Subject: "Welcome to Gotchu - Verify your email",
-		HTML:    htmlContent,
-	}
-
-	return s.sendEmail(emailReq)
-}
-
-// sendEmail sends an email via Resend API
-func (s *Service) sendEmail(req EmailRequest) error {
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("failed to marshal email request: %v", err)
-	}
-
-	httpReq, err := http.NewRequest("POST", s.baseURL+"/emails", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-
-	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		return fmt.Errorf("failed to send email: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var errorResp map[string]interface{}
-		json.NewDecoder(resp.Body).Decode(&errorResp)
-		return fmt.Errorf("email API error (status %d): %v", resp.StatusCode, errorResp)
-	}
-
-	return nil
-}
-
-// buildVerificationEmailHTML creates beautiful verification email HTML
-func (s *Service) buildVerificationEmailHTML(username, verificationLink string) string {
-	return fmt.Sprintf(`
-<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Verify your email - Gotchu</title>
-    <style>
-        body {
-            margin: 0;
-            padding: 0;
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', 'Roboto', sans-serif;
-            background: linear-gradient(135deg, #0a0a0a 0%%, #1a1a1a 100%%);
-            color: #ffffff;
-        }
-        .container {
-            max-width: 600px;
-            margin: 0 auto;
-            padding: 40px 20px;
-            position: relative;
-        }
-        .geometric-shape {
-            position: absolute;
-            z-index: -1;
-        }
-        .shape-1 {
-            top: 80px;
-            left: -50px;
-            width: 100px;
-            height: 100px;
-            background: linear-gradient(45deg, rgba(88, 164, 176, 0.1), rgba(74, 140, 150, 0.1));
-            border-radius: 20px;
-            transform: rotate(45deg);
-        }
-        .shape-2 {
-            top: 200px;
-            right: -30px;
-            width: 60px;
-            height: 60px;
-            background: rgba(88, 164, 176, 0.08);
-            border-radius: 50%%;
-        }
-        .shape-3 {
-            bottom: 150px;
-            left: -20px;
-            width: 80px;
-            height: 80px;
-            background: rgba(88, 164, 176, 0.06);
-            clip-path: polygon(50%% 0%%, 0%% 100%%, 100%% 100%%);
-        }
-        .header {
-            text-align: center;
-            margin-bottom: 40px;
-        }
-        .logo {
-            font-size: 32px;
-            font-weight: bold;
-            color: #58A4B0;
-            margin-bottom: 10px;
-        }
-        .content {
-            background: rgba(255, 255, 255, 0.05);
-            border: 1px solid rgba(255, 255, 255, 0.1);
-            border-radius: 16px;
-            padding: 40px;
-            backdrop-filter: blur(10px);
-            text-align: center;
-        }
-        .welcome {
-            font-size: 24px;
-            font-weight: 600;
-            margin-bottom: 16px;
-            color: #ffffff;
-        }
-        .message {
-            font-size: 16px;
-            line-height: 1.6;
-            color: #a0a0a0;
-            margin-bottom: 32px;
-        }
-        .verify-btn {
-            display: inline-block;
-            background: linear-gradient(135deg, #58A4B0 0%%, #4A8C96 100%%);
-            background-color: #58A4B0;
-            color: #ffffff !important;
-            text-decoration: none !important;
-            padding: 16px 32px;
-            border-radius: 12px;
-            font-weight: 600;
-            font-size: 16px;
-            box-shadow: 0 8px 32px rgba(88, 164, 176, 0.3);
-            transition: all 0.3s ease;
-            border: none;
-            cursor: pointer;
-        }
-        .verify-btn:hover {
-            transform: translateY(-2px);
-            box-shadow: 0 12px 40px rgba(88, 164, 176, 0.4);
-        }
-        .security {
-            margin-top: 32px;
-            padding-top: 24px;
-            border-top: 1px solid rgba(255, 255, 255, 0.1);
-            font-size: 14px;
-            color: #666;
-        }
-        .footer {
-            margin-top: 40px;
-            text-align: center;
-            font-size: 14px;
-            color: #666;
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="geometric-shape shape-1"></div>
-        <div class="geometric-shape shape-2"></div>
-        <div class="geometric-shape shape-3"></div>
-        
-        <div class="header">
-            <div class="logo">gotchu</div>
-        </div>
-        
-        <div class="content">
-            <div class="welcome">Welcome to Gotchu, %s!</div>
-            
-            <div class="message">
-                Thank you for creating your account. To complete your registration and access all platform features, 
-                please verify your email address by clicking the button below.
-            </div>
-            
-            <a href="%s" class="verify-btn">
-                Verify Email Address
-            </a>
-            
-            <div class="security">
-                This verification link will expire in 24 hours for security purposes.<br>
-                If you did not create this account, please disregard this email.
-            </div>
-        </div>
-        
-        <div class="footer">
-            © 2025 Gotchu. Professional platform for creators.
-        </div>
-    </div>
-</body>
-</html>`, username, verificationLink)
-}
\ No newline at end of file
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Service struct {
+	apiKey   string
+	fromName string
+	fromEmail string
+	baseURL  string
+}
+
+type EmailRequest struct {
+	From    string `json:"from"`
+	To      []string `json:"to"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+}
+
+type EmailAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type EmailResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message,omitempty"`
+}
+
+// NewService creates a new email service
+func NewService(apiKey, fromName, fromEmail string) *Service {
+	return &Service{
+		apiKey:    apiKey,
+		fromName:  fromName,
+		fromEmail: fromEmail,
+		baseURL:   "https://api.resend.com",
+	}
+}
+
+// GenerateVerificationToken generates a secure verification token
+func (s *Service) GenerateVerificationToken() (string, error) {
+	bytes := make([]byte, 32) // 256-bit token
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate token: %v", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// SendVerificationEmail sends an email verification link
+func (s *Service) SendVerificationEmail(toEmail, username, verificationToken, baseURL string) error {
+	verificationLink := fmt.Sprintf("%s/verify-email?token=%s", baseURL, verificationToken)
+	
+	htmlContent := s.buildVerificationEmailHTML(username, verificationLink)
+	
+	emailReq := EmailRequest{
+		From:    s.fromEmail,
+		To:      []string{toEmail},
+		Subject: "Welcome to Gotchu - Verify your email",
+		HTML:    htmlContent,
+	}
+
+	return s.sendEmail(emailReq)
+}
+
+// sendEmail sends an email via Resend API
+func (s *Service) sendEmail(req EmailRequest) error {
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email request: %v", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", s.baseURL+"/emails", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&errorResp)
+		return fmt.Errorf("email API error (status %d): %v", resp.StatusCode, errorResp)
+	}
+
+	return nil
+}
+
+// buildVerificationEmailHTML creates beautiful verification email HTML
+func (s *Service) buildVerificationEmailHTML(username, verificationLink string) string {
+	return fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Verify your email - Gotchu</title>
+    <style>
+        body {
+            margin: 0;
+            padding: 0;
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', 'Roboto', sans-serif;
+            background: linear-gradient(135deg, #0a0a0a 0%%, #1a1a1a 100%%);
+            color: #ffffff;
+        }
+        .container {
+            max-width: 600px;
+            margin: 0 auto;
+            padding: 40px 20px;
+            position: relative;
+        }
+        .geometric-shape {
+            position: absolute;
+            z-index: -1;
+        }
+        .shape-1 {
+            top: 80px;
+            left: -50px;
+            width: 100px;
+            height: 100px;
+            background: linear-gradient(45deg, rgba(88, 164, 176, 0.1), rgba(74, 140, 150, 0.1));
+            border-radius: 20px;
+            transform: rotate(45deg);
+        }
+        .shape-2 {
+            top: 200px;
+            right: -30px;
+            width: 60px;
+            height: 60px;
+            background: rgba(88, 164, 176, 0.08);
+            border-radius: 50%%;
+        }
+        .shape-3 {
+            bottom: 150px;
+            left: -20px;
+            width: 80px;
+            height: 80px;
+            background: rgba(88, 164, 176, 0.06);
+            clip-path: polygon(50%% 0%%, 0%% 100%%, 100%% 100%%);
+        }
+        .header {
+            text-align: center;
+            margin-bottom: 40px;
+        }
+        .logo {
+            font-size: 32px;
+            font-weight: bold;
+            color: #58A4B0;
+            margin-bottom: 10px;
+        }
+        .content {
+            background: rgba(255, 255, 255, 0.05);
+            border: 1px solid rgba(255, 255, 255, 0.1);
+            border-radius: 16px;
+            padding: 40px;
+            backdrop-filter: blur(10px);
+            text-align: center;
+        }
+        .welcome {
+            font-size: 24px;
+            font-weight: 600;
+            margin-bottom: 16px;
+            color: #ffffff;
+        }
+        .message {
+            font-size: 16px;
+            line-height: 1.6;
+            color: #a0a0a0;
+            margin-bottom: 32px;
+        }
+        .verify-btn {
+            display: inline-block;
+            background: linear-gradient(135deg, #58A4B0 0%%, #4A8C96 100%%);
+            background-color: #58A4B0;
+            color: #ffffff !important;
+            text-decoration: none !important;
+            padding: 16px 32px;
+            border-radius: 12px;
+            font-weight: 600;
+            font-size: 16px;
+            box-shadow: 0 8px 32px rgba(88, 164, 176, 0.3);
+            transition: all 0.3s ease;
+            border: none;
+            cursor: pointer;
+        }
+        .verify-btn:hover {
+            transform: translateY(-2px);
+            box-shadow: 0 12px 40px rgba(88, 164, 176, 0.4);
+        }
+        .security {
+            margin-top: 32px;
+            padding-top: 24px;
+            border-top: 1px solid rgba(255, 255, 255, 0.1);
+            font-size: 14px;
+            color: #666;
+        }
+        .footer {
+            margin-top: 40px;
+            text-align: center;
+            font-size: 14px;
+            color: #666;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="geometric-shape shape-1"></div>
+        <div class="geometric-shape shape-2"></div>
+        <div class="geometric-shape shape-3"></div>
+        
+        <div class="header">
+            <div class="logo">gotchu</div>
+        </div>
+        
+        <div class="content">
+            <div class="welcome">Welcome to Gotchu, %s!</div>
+            
+            <div class="message">
+                Thank you for creating your account. To complete your registration and access all platform features, 
+                please verify your email address by clicking the button below.
+            </div>
+            
+            <a href="%s" class="verify-btn">
+                Verify Email Address
+            </a>
+            
+            <div class="security">
+                This verification link will expire in 24 hours for security purposes.<br>
+                If you did not create this account, please disregard this email.
+            </div>
+        </div>
+        
+        <div class="footer">
+            © 2025 Gotchu. Professional platform for creators.
+        </div>
+    </div>
+</body>
+</html>`, username, verificationLink)
+}
+
+// BrokenLink describes a link reported in a broken links email
+type BrokenLink struct {
+	Title  string
+	URL    string
+	Reason string
+}
+
+// SendBrokenLinksEmail tells a user that some of their profile links stopped working
+func (s *Service) SendBrokenLinksEmail(toEmail, username string, links []BrokenLink, dashboardURL string) error {
+	var items strings.Builder
+	for _, link := range links {
+		fmt.Fprintf(&items, `<li style="margin-bottom:12px"><strong>%s</strong><br><span style="color:#a1a1aa">%s</span><br><span style="color:#f87171">%s</span></li>`,
+			html.EscapeString(link.Title), html.EscapeString(link.URL), html.EscapeString(link.Reason))
+	}
+
+	subject := "One of your Gotchu links is broken"
+	if len(links) > 1 {
+		subject = fmt.Sprintf("%d of your Gotchu links are broken", len(links))
+	}
+
+	htmlContent := fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+<body style="margin:0;padding:40px 20px;background:#0a0a0a;color:#ffffff;font-family:-apple-system,BlinkMacSystemFont,'Segoe UI','Roboto',sans-serif">
+    <div style="max-width:600px;margin:0 auto">
+        <div style="font-size:28px;font-weight:700;margin-bottom:24px">gotchu</div>
+        <p>Hi %s,</p>
+        <p>We checked the links on your profile and these have stopped responding. Visitors who click them will land on an error page.</p>
+        <ul style="padding-left:20px">%s</ul>
+        <a href="%s" style="display:inline-block;margin-top:16px;padding:12px 24px;background:#ffffff;color:#0a0a0a;border-radius:8px;text-decoration:none;font-weight:600">Review your links</a>
+        <p style="margin-top:32px;color:#71717a;font-size:12px">We'll keep checking and stop flagging a link once it works again.</p>
+    </div>
+</body>
+</html>`, html.EscapeString(username), items.String(), html.EscapeString(dashboardURL))
+
+	return s.sendEmail(EmailRequest{
+		From:    s.fromEmail,
+		To:      []string{toEmail},
+		Subject: subject,
+		HTML:    htmlContent,
+	})
+}
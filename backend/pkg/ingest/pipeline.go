@@ -0,0 +1,535 @@
+package ingest
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/analytics"
+	"gotchu-backend/pkg/ipprivacy"
+	"gotchu-backend/pkg/live"
+	"gotchu-backend/pkg/redis"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kind identifies what an event records
+type Kind string
+
+const (
+	KindView  Kind = "view"
+	KindClick Kind = "click"
+)
+
+// Event is a profile view or link click waiting to be written. Everything is
+// copied out of the request, so events outlive it.
+type Event struct {
+	Kind      Kind
+	UserID    uint // profile owner, whose cached stats are cleared once the event is written
+	LinkID    uint // clicks only
+	VariantID *uint
+	Variant   *string
+	Source    *string
+	IPAddress string
+	UserAgent string
+	Referer   string
+	SessionID string
+	DedupeKey string // views only: Redis key released when the view cannot be saved
+	BotReason string // why the event was filtered as automated; such events are only counted in the daily bot stats
+	At        time.Time
+}
+
+// Config controls queueing, batching and retries
+type Config struct {
+	QueueSize     int           // events held in memory before new ones are dropped
+	BatchSize     int           // events written per batch
+	FlushInterval time.Duration // longest an event waits for its batch to fill
+	MaxAttempts   int           // tries per batch before it is dropped
+	RetryBackoff  time.Duration // wait before the first retry, doubled for each one after
+}
+
+// DefaultConfig returns the settings used in production
+func DefaultConfig() Config {
+	return Config{
+		QueueSize:     10000,
+		BatchSize:     500,
+		FlushInterval: 2 * time.Second,
+		MaxAttempts:   5,
+		RetryBackoff:  500 * time.Millisecond,
+	}
+}
+
+// Stats describes the pipeline's throughput and health
+type Stats struct {
+	QueueDepth    int     `json:"queue_depth"`
+	QueueCapacity int     `json:"queue_capacity"`
+	Enqueued      uint64  `json:"enqueued"`
+	Written       uint64  `json:"written"`
+	Filtered      uint64  `json:"filtered"` // written events counted as automated traffic instead of views or clicks
+	Dropped       uint64  `json:"dropped"`  // rejected because the queue was full
+	Failed        uint64  `json:"failed"`   // lost after every write attempt failed
+	Retries       uint64  `json:"retries"`
+	Batches       uint64  `json:"batches"`
+	LagSeconds    float64 `json:"lag_seconds"` // age of the oldest event in the last written batch
+}
+
+// Pipeline buffers view and click events in a bounded queue and writes them
+// in batches, so traffic spikes turn into a few bulk inserts instead of one
+// insert, geolocation call and cache invalidation per request
+type Pipeline struct {
+	db          *gorm.DB
+	redisClient *redis.Client
+	locator     analytics.GeoResolver
+	live        *live.Hub
+	anonymizer  *ipprivacy.Anonymizer
+	config      Config
+
+	queue chan Event
+	quit  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	enqueued atomic.Uint64
+	written  atomic.Uint64
+	filtered atomic.Uint64
+	dropped  atomic.Uint64
+	failed   atomic.Uint64
+	retries  atomic.Uint64
+	batches  atomic.Uint64
+	lag      atomic.Int64 // nanoseconds
+}
+
+// NewPipeline creates a pipeline; redisClient, locator, liveHub and anonymizer
+// may be nil, the latter storing raw addresses
+func NewPipeline(db *gorm.DB, redisClient *redis.Client, locator analytics.GeoResolver, liveHub *live.Hub, anonymizer *ipprivacy.Anonymizer, config Config) *Pipeline {
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultConfig().QueueSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultConfig().BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultConfig().FlushInterval
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+
+	return &Pipeline{
+		db:          db,
+		redisClient: redisClient,
+		locator:     locator,
+		live:        liveHub,
+		anonymizer:  anonymizer,
+		config:      config,
+		queue:       make(chan Event, config.QueueSize),
+		quit:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Enqueue hands an event to the pipeline without blocking. It reports false
+// when the queue is full and the event was dropped.
+func (p *Pipeline) Enqueue(event Event) bool {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	select {
+	case p.queue <- event:
+		p.enqueued.Add(1)
+		return true
+	default:
+		p.dropped.Add(1)
+		if event.Kind == KindView {
+			p.releaseDedupe([]Event{event})
+		}
+		return false
+	}
+}
+
+// Start runs the consumer
+func (p *Pipeline) Start() {
+	go p.run()
+	log.Printf("📥 Analytics ingestion started (queue %d, batch %d)", p.config.QueueSize, p.config.BatchSize)
+}
+
+// Stop flushes queued events and stops the consumer, waiting at most timeout
+func (p *Pipeline) Stop(timeout time.Duration) {
+	p.once.Do(func() { close(p.quit) })
+	select {
+	case <-p.done:
+	case <-time.After(timeout):
+		log.Printf("⚠️ Analytics ingestion shutdown timed out with %d events queued", len(p.queue))
+	}
+}
+
+// Stats returns the pipeline's counters
+func (p *Pipeline) Stats() Stats {
+	return Stats{
+		QueueDepth:    len(p.queue),
+		QueueCapacity: cap(p.queue),
+		Enqueued:      p.enqueued.Load(),
+		Written:       p.written.Load(),
+		Filtered:      p.filtered.Load(),
+		Dropped:       p.dropped.Load(),
+		Failed:        p.failed.Load(),
+		Retries:       p.retries.Load(),
+		Batches:       p.batches.Load(),
+		LagSeconds:    time.Duration(p.lag.Load()).Seconds(),
+	}
+}
+
+// run collects events into batches, flushing when a batch fills up or the
+// flush interval passes
+func (p *Pipeline) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, p.config.BatchSize)
+	for {
+		select {
+		case event := <-p.queue:
+			batch = append(batch, event)
+			if len(batch) >= p.config.BatchSize {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				p.flush(batch)
+				batch = batch[:0]
+			}
+		case <-p.quit:
+			// Drain what is left without waiting on retries for long
+			for {
+				select {
+				case event := <-p.queue:
+					batch = append(batch, event)
+					if len(batch) >= p.config.BatchSize {
+						p.flush(batch)
+						batch = batch[:0]
+					}
+				default:
+					if len(batch) > 0 {
+						p.flush(batch)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush enriches and writes a batch, retrying with exponential backoff
+func (p *Pipeline) flush(batch []Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("🚨 Analytics ingestion panicked writing %d events: %v", len(batch), r)
+			p.failed.Add(uint64(len(batch)))
+		}
+	}()
+
+	views, clicks := p.enrich(batch)
+	bots := countBots(batch)
+
+	backoff := p.config.RetryBackoff
+	var err error
+	for attempt := 1; attempt <= p.config.MaxAttempts; attempt++ {
+		if err = p.write(views, clicks, bots); err == nil {
+			break
+		}
+		if attempt == p.config.MaxAttempts {
+			break
+		}
+		p.retries.Add(1)
+		log.Printf("Analytics batch of %d events failed (attempt %d/%d), retrying in %s: %v", len(batch), attempt, p.config.MaxAttempts, backoff, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-p.quit:
+			// Shutting down: one last immediate try, then give up
+			attempt = p.config.MaxAttempts - 1
+		}
+		backoff *= 2
+	}
+
+	if err != nil {
+		log.Printf("🚨 Dropping analytics batch of %d events: %v", len(batch), err)
+		p.failed.Add(uint64(len(batch)))
+		p.releaseDedupe(batch)
+		return
+	}
+
+	oldest := batch[0].At
+	for _, event := range batch[1:] {
+		if event.At.Before(oldest) {
+			oldest = event.At
+		}
+	}
+	p.lag.Store(int64(time.Since(oldest)))
+	p.written.Add(uint64(len(batch)))
+	for _, stat := range bots {
+		p.filtered.Add(uint64(stat.Count))
+	}
+	p.batches.Add(1)
+	p.invalidateCaches(batch)
+	p.publishLive(batch, views, clicks)
+}
+
+// enrich turns events into records, detecting devices and looking up each
+// distinct IP address once per batch. Locations come from the full address,
+// before it is anonymized for storage. Automated events are left out.
+func (p *Pipeline) enrich(batch []Event) ([]models.ProfileView, []models.LinkClick) {
+	locations := make(map[string]*analytics.GeoLocation)
+	locate := func(ip string) (*string, *string) {
+		if p.locator == nil || ip == "" {
+			return nil, nil
+		}
+		location, seen := locations[ip]
+		if !seen {
+			var err error
+			location, err = p.locator.GetLocation(ip)
+			if err != nil {
+				location = nil
+			}
+			locations[ip] = location
+		}
+		if location == nil || location.Country == "Unknown" || location.Country == "" {
+			return nil, nil
+		}
+		country, city := location.Country, location.City
+		return &country, &city
+	}
+
+	var views []models.ProfileView
+	var clicks []models.LinkClick
+	var hashErr error
+	for i := range batch {
+		event := &batch[i]
+		if event.BotReason != "" {
+			continue
+		}
+		device := analytics.DetectDevice(event.UserAgent)
+		country, city := locate(event.IPAddress)
+		address, hash, err := p.anonymizer.Anonymize(event.IPAddress, event.At)
+		if err != nil {
+			hashErr = err
+		}
+
+		switch event.Kind {
+		case KindView:
+			views = append(views, models.ProfileView{
+				UserID:    event.UserID,
+				IPAddress: address,
+				IPHash:    hash,
+				UserAgent: &event.UserAgent,
+				Referer:   &event.Referer,
+				Country:   country,
+				City:      city,
+				Device:    &device.Device,
+				Browser:   &device.Browser,
+				SessionID: &event.SessionID,
+				Source:    event.Source,
+				CreatedAt: event.At,
+			})
+		case KindClick:
+			clicks = append(clicks, models.LinkClick{
+				LinkID:    event.LinkID,
+				IPAddress: address,
+				IPHash:    hash,
+				UserAgent: &event.UserAgent,
+				Referer:   &event.Referer,
+				Country:   country,
+				City:      city,
+				Device:    &device.Device,
+				Browser:   &device.Browser,
+				SessionID: &event.SessionID,
+				Source:    event.Source,
+				VariantID: event.VariantID,
+				Variant:   event.Variant,
+				CreatedAt: event.At,
+			})
+		}
+	}
+	if hashErr != nil {
+		log.Printf("⚠️ Warning: Stored analytics without IP hashes: %v", hashErr)
+	}
+	return views, clicks
+}
+
+// countBots adds up a batch's automated events by profile, day, kind and reason
+func countBots(batch []Event) []models.DailyBotStat {
+	type key struct {
+		userID uint
+		day    time.Time
+		kind   Kind
+		reason string
+	}
+	counts := make(map[key]int)
+	var order []key
+	for _, event := range batch {
+		if event.BotReason == "" || event.UserID == 0 {
+			continue
+		}
+		k := key{event.UserID, event.At.UTC().Truncate(24 * time.Hour), event.Kind, event.BotReason}
+		if counts[k] == 0 {
+			order = append(order, k)
+		}
+		counts[k]++
+	}
+
+	stats := make([]models.DailyBotStat, 0, len(order))
+	for _, k := range order {
+		stats = append(stats, models.DailyBotStat{UserID: k.userID, Day: k.day, Kind: string(k.kind), Reason: k.reason, Count: counts[k]})
+	}
+	return stats
+}
+
+// write stores a batch in one transaction so a retry never counts it twice.
+// Link and user click totals are kept up to date by database triggers.
+func (p *Pipeline) write(views []models.ProfileView, clicks []models.LinkClick, bots []models.DailyBotStat) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if len(views) > 0 {
+			if err := tx.CreateInBatches(&views, 500).Error; err != nil {
+				return fmt.Errorf("failed to save profile views: %w", err)
+			}
+
+			perUser := make(map[uint]int)
+			for _, view := range views {
+				perUser[view.UserID]++
+			}
+			for userID, count := range perUser {
+				err := tx.Model(&models.User{}).Where("id = ?", userID).
+					UpdateColumn("profile_views", gorm.Expr("profile_views + ?", count)).Error
+				if err != nil {
+					return fmt.Errorf("failed to update profile views of user %d: %w", userID, err)
+				}
+			}
+		}
+
+		if len(clicks) > 0 {
+			if err := tx.CreateInBatches(&clicks, 500).Error; err != nil {
+				return fmt.Errorf("failed to save link clicks: %w", err)
+			}
+
+			perVariant := make(map[uint]int)
+			for _, click := range clicks {
+				if click.VariantID != nil {
+					perVariant[*click.VariantID]++
+				}
+			}
+			for variantID, count := range perVariant {
+				err := tx.Model(&models.LinkVariant{}).Where("id = ?", variantID).
+					UpdateColumn("clicks", gorm.Expr("clicks + ?", count)).Error
+				if err != nil {
+					return fmt.Errorf("failed to update clicks of variant %d: %w", variantID, err)
+				}
+			}
+		}
+
+		if len(bots) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "day"}, {Name: "kind"}, {Name: "reason"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("daily_bot_stats.count + excluded.count")}),
+			}).CreateInBatches(&bots, 500).Error
+			if err != nil {
+				return fmt.Errorf("failed to save filtered traffic: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// invalidateCaches clears the cached stats of every profile owner in the
+// batch, once each. Clicks count too since they change the owner's totals.
+func (p *Pipeline) invalidateCaches(batch []Event) {
+	if p.redisClient == nil {
+		return
+	}
+	seen := make(map[uint]bool)
+	for _, event := range batch {
+		if event.UserID == 0 || seen[event.UserID] {
+			continue
+		}
+		seen[event.UserID] = true
+		p.redisClient.Delete(fmt.Sprintf("analytics:user:%d", event.UserID))
+		p.redisClient.InvalidateUserCache(event.UserID)
+		p.redisClient.Delete(fmt.Sprintf("dashboard:%d", event.UserID))
+	}
+}
+
+// publishLive sends written events to the owners' live dashboards. views and
+// clicks are the batch's records, in the order enrich built them.
+func (p *Pipeline) publishLive(batch []Event, views []models.ProfileView, clicks []models.LinkClick) {
+	if p.live == nil {
+		return
+	}
+
+	perUser := make(map[uint][]live.Event)
+	var nextView, nextClick int
+	for _, event := range batch {
+		if event.BotReason != "" {
+			continue
+		}
+		var country, device, browser *string
+		switch event.Kind {
+		case KindView:
+			view := views[nextView]
+			nextView++
+			country, device, browser = view.Country, view.Device, view.Browser
+		case KindClick:
+			click := clicks[nextClick]
+			nextClick++
+			country, device, browser = click.Country, click.Device, click.Browser
+		default:
+			continue
+		}
+		if event.UserID == 0 {
+			continue
+		}
+
+		liveEvent := live.Event{
+			Type:     string(event.Kind),
+			LinkID:   event.LinkID,
+			Country:  stringValue(country),
+			Device:   stringValue(device),
+			Browser:  stringValue(browser),
+			Referrer: analytics.ReferrerHost(event.Referer),
+			At:       event.At,
+		}
+		if event.Source != nil && *event.Source != "" {
+			liveEvent.Referrer = *event.Source
+		}
+		perUser[event.UserID] = append(perUser[event.UserID], liveEvent)
+	}
+
+	for userID, events := range perUser {
+		p.live.Publish(userID, events...)
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// releaseDedupe removes the deduplication keys of views that were not saved,
+// so the visitor's next view is counted
+func (p *Pipeline) releaseDedupe(events []Event) {
+	if p.redisClient == nil {
+		return
+	}
+	for _, event := range events {
+		if event.DedupeKey != "" {
+			p.redisClient.Delete(event.DedupeKey)
+		}
+	}
+}
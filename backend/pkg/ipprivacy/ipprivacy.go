@@ -0,0 +1,155 @@
+// Package ipprivacy keeps raw visitor IP addresses out of the database. In
+// private mode an address is stored as a truncated prefix, which is still
+// good enough to locate the visitor, and a salted hash for deduplication. The
+// salt changes every UTC day and is thrown away the day after, so stored
+// hashes cannot be linked across days or brute-forced back into addresses.
+package ipprivacy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/netip"
+	"sync"
+	"time"
+
+	"gotchu-backend/pkg/redis"
+)
+
+// Modes the IP_STORAGE_MODE setting picks between
+const (
+	ModeRaw     = "raw"     // store visitor addresses as they are
+	ModePrivate = "private" // store a truncated prefix and a daily salted hash
+)
+
+// saltTTL keeps a day's salt through the next day, so views just after
+// midnight are still deduplicated against the evening before
+const saltTTL = 48 * time.Hour
+
+// IPv4 addresses keep their /24 network and IPv6 addresses their /48 site
+// prefix, the granularity most geolocation databases resolve to anyway
+const (
+	ipv4PrefixBits = 24
+	ipv6PrefixBits = 48
+)
+
+// Anonymizer turns visitor addresses into what is stored for them
+type Anonymizer struct {
+	mode        string
+	redisClient *redis.Client
+
+	mu    sync.Mutex
+	salts map[string][]byte // by UTC day
+}
+
+// NewAnonymizer creates an anonymizer for a storage mode. Daily salts are
+// shared through Redis so every instance hashes alike; without it each
+// instance keeps its own.
+func NewAnonymizer(mode string, redisClient *redis.Client) *Anonymizer {
+	if mode != ModeRaw && mode != ModePrivate {
+		log.Printf("⚠️ Warning: Unknown IP storage mode %q, storing raw addresses", mode)
+		mode = ModeRaw
+	}
+	return &Anonymizer{
+		mode:        mode,
+		redisClient: redisClient,
+		salts:       make(map[string][]byte),
+	}
+}
+
+// Private reports whether addresses are anonymized before they are stored
+func (a *Anonymizer) Private() bool {
+	return a != nil && a.mode == ModePrivate
+}
+
+// Anonymize returns the address and hash to store for a visitor seen at a
+// given time. In raw mode the address is kept and there is no hash. If the
+// hash cannot be made the prefix is still returned, with the error, so a raw
+// address is never stored by mistake.
+func (a *Anonymizer) Anonymize(ipAddress string, at time.Time) (address *string, hash *string, err error) {
+	if !a.Private() || ipAddress == "" {
+		return &ipAddress, nil, nil
+	}
+	prefix := Prefix(ipAddress)
+	digest, err := a.Hash(ipAddress, at)
+	if err != nil {
+		return &prefix, nil, err
+	}
+	return &prefix, &digest, nil
+}
+
+// Hash returns the salted hash of an address for the UTC day of at
+func (a *Anonymizer) Hash(ipAddress string, at time.Time) (string, error) {
+	salt, err := a.salt(at.UTC().Format("2006-01-02"))
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(ipAddress))
+	return hex.EncodeToString(mac.Sum(nil)[:16]), nil
+}
+
+// Prefix truncates an address to its network prefix, with the host bits
+// zeroed. Anything that does not parse as an address is dropped.
+func Prefix(ipAddress string) string {
+	addr, err := netip.ParseAddr(ipAddress)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap().WithZone("")
+	bits := ipv6PrefixBits
+	if addr.Is4() {
+		bits = ipv4PrefixBits
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.Addr().String()
+}
+
+// salt returns the random salt of a day, creating it on first use
+func (a *Anonymizer) salt(day string) ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if salt, ok := a.salts[day]; ok {
+		return salt, nil
+	}
+
+	fresh := make([]byte, 32)
+	if _, err := rand.Read(fresh); err != nil {
+		return nil, fmt.Errorf("failed to generate IP salt: %w", err)
+	}
+	salt := fresh
+	if a.redisClient != nil {
+		// The first instance to need a day's salt picks it
+		key := "ip_salt:" + day
+		stored, err := a.redisClient.SetIfNotExists(key, hex.EncodeToString(fresh), saltTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store IP salt: %w", err)
+		}
+		if !stored {
+			var encoded string
+			if err := a.redisClient.Get(key, &encoded); err != nil {
+				return nil, fmt.Errorf("failed to load IP salt: %w", err)
+			}
+			if salt, err = hex.DecodeString(encoded); err != nil || len(salt) == 0 {
+				return nil, fmt.Errorf("invalid IP salt for %s", day)
+			}
+		}
+	}
+
+	// Forget the salts of days before yesterday, other than the one asked for
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	for cached := range a.salts {
+		if cached < yesterday {
+			delete(a.salts, cached)
+		}
+	}
+	a.salts[day] = salt
+	return salt, nil
+}
@@ -0,0 +1,189 @@
+package ipprivacy
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MigrationConfig controls how fast stored addresses are anonymized
+type MigrationConfig struct {
+	BatchSize int           // rows read and updated per transaction
+	Pause     time.Duration // wait between batches, to leave room for live traffic
+}
+
+// DefaultMigrationConfig returns the settings used in production
+func DefaultMigrationConfig() MigrationConfig {
+	return MigrationConfig{
+		BatchSize: 1000,
+		Pause:     100 * time.Millisecond,
+	}
+}
+
+// anonymizedTables are the tables holding visitor addresses, with the zero
+// value of their primary key to page from
+var anonymizedTables = []struct {
+	name  string
+	start interface{}
+}{
+	{"profile_views", int64(0)},
+	{"link_clicks", int64(0)},
+	{"badge_events", ""},
+}
+
+// Migrator anonymizes the addresses stored before private mode was turned on.
+// Rows without a hash are rewritten to a prefix and the hash for the day they
+// were created, so the job can stop at any point and pick up where it left
+// off on the next start.
+type Migrator struct {
+	db         *gorm.DB
+	anonymizer *Anonymizer
+	config     MigrationConfig
+
+	quit chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewMigrator creates a migration job
+func NewMigrator(db *gorm.DB, anonymizer *Anonymizer, config MigrationConfig) *Migrator {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultMigrationConfig().BatchSize
+	}
+	return &Migrator{
+		db:         db,
+		anonymizer: anonymizer,
+		config:     config,
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start anonymizes every table in the background; it does nothing unless the
+// anonymizer is in private mode
+func (m *Migrator) Start() {
+	if !m.anonymizer.Private() {
+		close(m.done)
+		return
+	}
+	go m.run()
+	log.Println("🕶️ Anonymizing stored visitor IP addresses")
+}
+
+// Stop interrupts the job after its current batch
+func (m *Migrator) Stop() {
+	m.once.Do(func() { close(m.quit) })
+	<-m.done
+}
+
+func (m *Migrator) run() {
+	defer close(m.done)
+
+	for _, table := range anonymizedTables {
+		count, err := m.anonymizeTable(table.name, table.start)
+		if err != nil {
+			log.Printf("🚨 Failed to anonymize %s after %d rows, resuming on next start: %v", table.name, count, err)
+			return
+		}
+		if count > 0 {
+			log.Printf("🕶️ Anonymized IP addresses of %d %s", count, table.name)
+		}
+		if m.stopping() {
+			return
+		}
+	}
+}
+
+// anonymizeTable pages through a table by primary key, rewriting the rows
+// that still hold a raw address
+func (m *Migrator) anonymizeTable(table string, cursor interface{}) (int64, error) {
+	var total int64
+	for {
+		ids, count, err := m.anonymizeBatch(table, cursor)
+		total += count
+		if err != nil {
+			return total, err
+		}
+		if len(ids) < m.config.BatchSize || m.stopping() {
+			return total, nil
+		}
+		cursor = ids[len(ids)-1]
+
+		select {
+		case <-time.After(m.config.Pause):
+		case <-m.quit:
+			return total, nil
+		}
+	}
+}
+
+// anonymizeBatch reads the next batch of rows after cursor and anonymizes the
+// ones without a hash. It returns the IDs read, in order, and how many rows
+// were rewritten.
+func (m *Migrator) anonymizeBatch(table string, cursor interface{}) ([]interface{}, int64, error) {
+	type row struct {
+		id        interface{}
+		ipAddress string
+		createdAt time.Time
+	}
+
+	rows, err := m.db.Raw(
+		fmt.Sprintf("SELECT id, COALESCE(ip_address, ''), ip_hash IS NULL, created_at FROM %s WHERE id > ? ORDER BY id LIMIT ?", table),
+		cursor, m.config.BatchSize,
+	).Rows()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+
+	var ids []interface{}
+	var pending []row
+	for rows.Next() {
+		var r row
+		var unhashed bool
+		if err := rows.Scan(&r.id, &r.ipAddress, &unhashed, &r.createdAt); err != nil {
+			rows.Close()
+			return nil, 0, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		ids = append(ids, r.id)
+		if unhashed && r.ipAddress != "" {
+			pending = append(pending, r)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	if len(pending) == 0 {
+		return ids, 0, nil
+	}
+
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		for _, r := range pending {
+			address, hash, err := m.anonymizer.Anonymize(r.ipAddress, r.createdAt)
+			if err != nil {
+				return err
+			}
+			err = tx.Exec(fmt.Sprintf("UPDATE %s SET ip_address = ?, ip_hash = ? WHERE id = ?", table), address, hash, r.id).Error
+			if err != nil {
+				return fmt.Errorf("failed to update %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return ids, int64(len(pending)), nil
+}
+
+func (m *Migrator) stopping() bool {
+	select {
+	case <-m.quit:
+		return true
+	default:
+		return false
+	}
+}
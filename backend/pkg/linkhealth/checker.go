@@ -0,0 +1,388 @@
+package linkhealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/safehttp"
+	"gotchu-backend/pkg/workers"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxDrainBytes bounds how much of a GET response body is read before closing
+const maxDrainBytes = 64 << 10
+
+// Config controls how often and how aggressively links are checked
+type Config struct {
+	Interval         time.Duration // time between sweeps
+	RecheckAfter     time.Duration // minimum age of a result before a link is checked again
+	BatchSize        int           // links checked per sweep
+	PerHostBatch     int           // links checked per host per sweep
+	HostInterval     time.Duration // minimum gap between requests to the same host
+	FailureThreshold int           // consecutive failures before a link is flagged as broken
+	MaxRedirects     int
+	Timeout          time.Duration // per request
+	UserAgent        string
+}
+
+// DefaultConfig returns the settings used in production
+func DefaultConfig() Config {
+	return Config{
+		Interval:         15 * time.Minute,
+		RecheckAfter:     24 * time.Hour,
+		BatchSize:        500,
+		PerHostBatch:     10,
+		HostInterval:     2 * time.Second,
+		FailureThreshold: 3,
+		MaxRedirects:     5,
+		Timeout:          10 * time.Second,
+		UserAgent:        "GotchuLinkChecker/1.0",
+	}
+}
+
+// Notifier is told about links that have just been flagged as broken
+type Notifier interface {
+	NotifyBrokenLinks(user *models.User, links []models.Link) error
+}
+
+// Outcome classifies a check result
+type Outcome int
+
+const (
+	OutcomeHealthy Outcome = iota
+	OutcomeFailed
+	// OutcomeInconclusive covers bot walls and rate limits, which say nothing about the link
+	OutcomeInconclusive
+)
+
+// Result is the outcome of checking one URL
+type Result struct {
+	StatusCode int
+	FinalURL   string
+	Latency    time.Duration
+	Err        error
+}
+
+// Outcome classifies the result
+func (r Result) Outcome() Outcome {
+	switch {
+	case r.Err != nil:
+		return OutcomeFailed
+	case r.StatusCode < 400:
+		return OutcomeHealthy
+	case r.StatusCode == http.StatusUnauthorized, r.StatusCode == http.StatusForbidden, r.StatusCode == http.StatusTooManyRequests:
+		return OutcomeInconclusive
+	default:
+		return OutcomeFailed
+	}
+}
+
+// Checker periodically checks active link URLs and flags broken ones
+type Checker struct {
+	db       *gorm.DB
+	pool     *workers.WorkerPool
+	client   *http.Client
+	notifier Notifier
+	config   Config
+	limiter  *hostLimiter
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// NewChecker creates a link health checker. A nil client uses an SSRF-safe
+// one that refuses to connect to private addresses; tests can inject a client
+// that reaches a local server. The notifier may be nil.
+func NewChecker(db *gorm.DB, pool *workers.WorkerPool, client *http.Client, notifier Notifier, config Config) *Checker {
+	if client == nil {
+		client = safehttp.NewClient(0)
+	}
+
+	// Copy the client so the redirect limit does not leak into the caller's
+	limited := *client
+	maxRedirects := config.MaxRedirects
+	limited.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+
+	return &Checker{
+		db:       db,
+		pool:     pool,
+		client:   &limited,
+		notifier: notifier,
+		config:   config,
+		limiter:  newHostLimiter(config.HostInterval),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start submits a sweep to the worker pool on every interval
+func (c *Checker) Start() {
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(c.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.pool.SubmitFunc("link-health-sweep", c.Sweep)
+			case <-c.quit:
+				return
+			}
+		}
+	}()
+	log.Println("🩺 Link health checker started")
+}
+
+// Stop stops scheduling sweeps
+func (c *Checker) Stop() {
+	close(c.quit)
+	<-c.done
+}
+
+// Sweep notifies owners of newly broken links, then checks the links that are
+// due, one job per host so requests to a host are spaced out
+func (c *Checker) Sweep() error {
+	c.notifyBroken()
+
+	var links []models.Link
+	err := c.db.Preload("Health").
+		Select("links.id, links.url, links.user_id").
+		Joins("LEFT JOIN link_health ON link_health.link_id = links.id").
+		Where("links.is_active = ? AND (links.url ILIKE ? OR links.url ILIKE ?)", true, "http://%", "https://%").
+		Where("link_health.checked_at IS NULL OR link_health.checked_at < ?", time.Now().Add(-c.config.RecheckAfter)).
+		Order("link_health.checked_at ASC NULLS FIRST").
+		Limit(c.config.BatchSize).
+		Find(&links).Error
+	if err != nil {
+		return fmt.Errorf("failed to load links for health check: %w", err)
+	}
+
+	byHost := make(map[string][]models.Link)
+	for _, link := range links {
+		parsed, err := url.Parse(*link.URL)
+		if err != nil || parsed.Hostname() == "" {
+			continue
+		}
+		host := strings.ToLower(parsed.Hostname())
+		if len(byHost[host]) < c.config.PerHostBatch {
+			byHost[host] = append(byHost[host], link)
+		}
+	}
+
+	for host, hostLinks := range byHost {
+		hostLinks := hostLinks
+		c.pool.Submit(workers.Job{
+			ID: "link-health-" + host,
+			Handler: func() error {
+				return c.checkAll(hostLinks)
+			},
+			// Requests to the host are sequential and rate limited
+			Timeout: time.Duration(len(hostLinks)) * (c.config.Timeout + c.config.HostInterval),
+		})
+	}
+	return nil
+}
+
+// checkAll checks links that share a host, one at a time
+func (c *Checker) checkAll(links []models.Link) error {
+	for i := range links {
+		select {
+		case <-c.quit:
+			return nil
+		default:
+		}
+
+		result := c.Check(context.Background(), *links[i].URL)
+		if err := c.record(&links[i], result); err != nil {
+			log.Printf("Failed to record health of link %d: %v", links[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// Check requests a URL with HEAD, falling back to GET for servers that reject HEAD
+func (c *Checker) Check(ctx context.Context, rawURL string) Result {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return Result{Err: err}
+	}
+	if err := c.limiter.wait(ctx, strings.ToLower(parsed.Hostname())); err != nil {
+		return Result{Err: err}
+	}
+
+	start := time.Now()
+	resp, err := c.do(ctx, http.MethodHead, rawURL)
+	if err == nil && resp.StatusCode >= 400 {
+		resp.Body.Close()
+		resp, err = c.do(ctx, http.MethodGet, rawURL)
+	}
+	latency := time.Since(start)
+	if err != nil {
+		return Result{Latency: latency, Err: err}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+
+	return Result{
+		StatusCode: resp.StatusCode,
+		FinalURL:   resp.Request.URL.String(),
+		Latency:    latency,
+	}
+}
+
+// do sends a single request with the checker's timeout and user agent
+func (c *Checker) do(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.config.UserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// record stores a check result and updates the link's broken state
+func (c *Checker) record(link *models.Link, result Result) error {
+	health := models.LinkHealth{LinkID: link.ID}
+	if link.Health != nil {
+		health = *link.Health
+	}
+	c.apply(&health, result, time.Now())
+
+	return c.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&health).Error
+}
+
+// apply folds a check result made at now into a link's health
+func (c *Checker) apply(health *models.LinkHealth, result Result, now time.Time) {
+	switch result.Outcome() {
+	case OutcomeHealthy:
+		health.ConsecutiveFailures = 0
+		health.IsBroken = false
+		health.BrokenSince = nil
+		health.NotifiedAt = nil
+	case OutcomeFailed:
+		health.ConsecutiveFailures++
+		if health.ConsecutiveFailures >= c.config.FailureThreshold && !health.IsBroken {
+			health.IsBroken = true
+			health.BrokenSince = &now
+		}
+	}
+
+	health.StatusCode = nil
+	if result.StatusCode != 0 {
+		health.StatusCode = &result.StatusCode
+	}
+	health.FinalURL = nil
+	if result.FinalURL != "" {
+		health.FinalURL = &result.FinalURL
+	}
+	health.Error = nil
+	if result.Err != nil {
+		message := describeError(result.Err)
+		health.Error = &message
+	}
+	health.LatencyMs = int(result.Latency.Milliseconds())
+	health.CheckedAt = now
+}
+
+// notifyBroken tells owners about links flagged since the last sweep
+func (c *Checker) notifyBroken() {
+	if c.notifier == nil {
+		return
+	}
+
+	var pending []models.LinkHealth
+	if err := c.db.Where("is_broken = ? AND notified_at IS NULL", true).Limit(c.config.BatchSize).Find(&pending).Error; err != nil {
+		log.Printf("Failed to load broken links: %v", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	linkIDs := make([]uint, 0, len(pending))
+	for _, health := range pending {
+		linkIDs = append(linkIDs, health.LinkID)
+	}
+
+	var links []models.Link
+	if err := c.db.Preload("User").Preload("Health").Where("id IN ?", linkIDs).Find(&links).Error; err != nil {
+		log.Printf("Failed to load broken links: %v", err)
+		return
+	}
+
+	byUser := make(map[uint][]models.Link)
+	for _, link := range links {
+		byUser[link.UserID] = append(byUser[link.UserID], link)
+	}
+
+	for _, userLinks := range byUser {
+		user := userLinks[0].User
+		if err := c.notifier.NotifyBrokenLinks(&user, userLinks); err != nil {
+			// Left unmarked so the next sweep retries
+			log.Printf("Failed to notify user %d about broken links: %v", user.ID, err)
+			continue
+		}
+
+		ids := make([]uint, 0, len(userLinks))
+		for _, link := range userLinks {
+			ids = append(ids, link.ID)
+		}
+		c.db.Model(&models.LinkHealth{}).Where("link_id IN ?", ids).Update("notified_at", time.Now())
+	}
+}
+
+// describeError shortens transport errors for storage
+func describeError(err error) string {
+	var dnsErr *net.DNSError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timed out"
+	case errors.As(err, &dnsErr):
+		return "domain not found"
+	}
+
+	message := err.Error()
+	if len(message) > 255 {
+		message = message[:255]
+	}
+	return message
+}
+
+// cancelBody releases the request context once the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
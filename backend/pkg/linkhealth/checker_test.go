@@ -0,0 +1,203 @@
+package linkhealth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotchu-backend/internal/models"
+)
+
+// newTestChecker returns a checker that can reach local test servers
+func newTestChecker(config Config) *Checker {
+	config.HostInterval = 0
+	config.Timeout = 5 * time.Second
+	return NewChecker(nil, nil, &http.Client{}, nil, config)
+}
+
+// methodRecorder remembers the methods of the requests a test server received
+type methodRecorder struct {
+	mu      sync.Mutex
+	methods []string
+}
+
+func (r *methodRecorder) add(method string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.methods = append(r.methods, method)
+}
+
+func (r *methodRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.methods, ",")
+}
+
+func TestCheckFallsBackToGetWhenHeadIsRejected(t *testing.T) {
+	var seen methodRecorder
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen.add(r.Method)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	result := newTestChecker(DefaultConfig()).Check(context.Background(), server.URL)
+	if result.Err != nil {
+		t.Fatalf("Check returned error: %v", result.Err)
+	}
+	if result.StatusCode != http.StatusOK || result.Outcome() != OutcomeHealthy {
+		t.Errorf("got status %d outcome %d, want 200 healthy", result.StatusCode, result.Outcome())
+	}
+	if got := seen.String(); got != "HEAD,GET" {
+		t.Errorf("got requests %s, want HEAD,GET", got)
+	}
+}
+
+func TestCheckSkipsGetWhenHeadSucceeds(t *testing.T) {
+	var seen methodRecorder
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen.add(r.Method)
+	}))
+	defer server.Close()
+
+	result := newTestChecker(DefaultConfig()).Check(context.Background(), server.URL)
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("got status %d, want 200", result.StatusCode)
+	}
+	if got := seen.String(); got != "HEAD" {
+		t.Errorf("got requests %s, want HEAD", got)
+	}
+}
+
+func TestCheckFailsWhenGetAlsoFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	result := newTestChecker(DefaultConfig()).Check(context.Background(), server.URL)
+	if result.StatusCode != http.StatusNotFound || result.Outcome() != OutcomeFailed {
+		t.Errorf("got status %d outcome %d, want 404 failed", result.StatusCode, result.Outcome())
+	}
+}
+
+// redirectServer redirects /hop/n to /hop/n+1 until n reaches last, then answers 200
+func redirectServer(last int, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+
+		var hop int
+		fmt.Sscanf(r.URL.Path, "/hop/%d", &hop)
+		if hop >= last {
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/hop/%d", hop+1), http.StatusFound)
+	}))
+}
+
+func TestCheckFollowsRedirectsUpToTheCap(t *testing.T) {
+	var hits int32
+	server := redirectServer(3, &hits)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.MaxRedirects = 3
+	result := newTestChecker(config).Check(context.Background(), server.URL+"/hop/0")
+	if result.Err != nil {
+		t.Fatalf("Check returned error: %v", result.Err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("got status %d, want 200", result.StatusCode)
+	}
+	if want := server.URL + "/hop/3"; result.FinalURL != want {
+		t.Errorf("got final URL %s, want %s", result.FinalURL, want)
+	}
+}
+
+func TestCheckStopsAfterTooManyRedirects(t *testing.T) {
+	var hits int32
+	server := redirectServer(100, &hits)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.MaxRedirects = 3
+	result := newTestChecker(config).Check(context.Background(), server.URL+"/hop/0")
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "stopped after 3 redirects") {
+		t.Fatalf("got error %v, want the redirect cap", result.Err)
+	}
+	if result.Outcome() != OutcomeFailed {
+		t.Errorf("got outcome %d, want failed", result.Outcome())
+	}
+	// The first request and three redirects; the failure is not retried with GET
+	if hits := atomic.LoadInt32(&hits); hits != 4 {
+		t.Errorf("got %d requests, want 4", hits)
+	}
+}
+
+func TestApplyFlagsBrokenAfterConsecutiveFailures(t *testing.T) {
+	config := DefaultConfig()
+	config.FailureThreshold = 3
+	checker := newTestChecker(config)
+
+	failed := Result{StatusCode: http.StatusInternalServerError}
+	now := time.Now()
+	var health models.LinkHealth
+	for i := 1; i < config.FailureThreshold; i++ {
+		checker.apply(&health, failed, now)
+		if health.IsBroken || health.ConsecutiveFailures != i {
+			t.Fatalf("after %d failures got broken=%v failures=%d", i, health.IsBroken, health.ConsecutiveFailures)
+		}
+	}
+
+	checker.apply(&health, failed, now)
+	if !health.IsBroken || health.BrokenSince == nil || !health.BrokenSince.Equal(now) {
+		t.Fatalf("after %d failures got broken=%v since=%v", config.FailureThreshold, health.IsBroken, health.BrokenSince)
+	}
+
+	// Further failures keep the time the link first broke
+	checker.apply(&health, failed, now.Add(time.Hour))
+	if !health.BrokenSince.Equal(now) {
+		t.Errorf("broken since moved to %v", health.BrokenSince)
+	}
+}
+
+func TestApplyIgnoresInconclusiveResults(t *testing.T) {
+	config := DefaultConfig()
+	config.FailureThreshold = 2
+	checker := newTestChecker(config)
+
+	var health models.LinkHealth
+	checker.apply(&health, Result{Err: context.DeadlineExceeded}, time.Now())
+	for i := 0; i < 3; i++ {
+		checker.apply(&health, Result{StatusCode: http.StatusForbidden}, time.Now())
+	}
+	if health.IsBroken || health.ConsecutiveFailures != 1 {
+		t.Errorf("got broken=%v failures=%d, want a single failure", health.IsBroken, health.ConsecutiveFailures)
+	}
+}
+
+func TestApplyResetsOnSuccess(t *testing.T) {
+	config := DefaultConfig()
+	config.FailureThreshold = 1
+	checker := newTestChecker(config)
+
+	var health models.LinkHealth
+	checker.apply(&health, Result{StatusCode: http.StatusBadGateway}, time.Now())
+	if !health.IsBroken {
+		t.Fatal("link not flagged after reaching the threshold")
+	}
+	checker.apply(&health, Result{StatusCode: http.StatusOK}, time.Now())
+	if health.IsBroken || health.ConsecutiveFailures != 0 || health.BrokenSince != nil {
+		t.Errorf("got broken=%v failures=%d since=%v, want a healthy link", health.IsBroken, health.ConsecutiveFailures, health.BrokenSince)
+	}
+}
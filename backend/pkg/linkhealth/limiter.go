@@ -0,0 +1,55 @@
+package linkhealth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hostLimiter spaces out requests to the same host
+type hostLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{
+		interval: interval,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// wait reserves the host's next request slot and sleeps until it arrives
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(l.interval)
+
+	// Forget hosts whose slots have passed so the map stays small
+	for key, at := range l.next {
+		if at.Before(now) {
+			delete(l.next, key)
+		}
+	}
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
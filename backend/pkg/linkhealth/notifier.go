@@ -0,0 +1,87 @@
+package linkhealth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/email"
+
+	"gorm.io/gorm"
+)
+
+// ActivityLinkBroken is the activity type recorded when a link is flagged
+const ActivityLinkBroken = "link_broken"
+
+// OwnerNotifier records an activity for each broken link and emails the owner
+// when an email service is configured
+type OwnerNotifier struct {
+	db           *gorm.DB
+	emailService *email.Service
+	dashboardURL string
+}
+
+// NewOwnerNotifier creates a notifier; emailService may be nil
+func NewOwnerNotifier(db *gorm.DB, emailService *email.Service, frontendURL string) *OwnerNotifier {
+	return &OwnerNotifier{
+		db:           db,
+		emailService: emailService,
+		dashboardURL: strings.TrimRight(frontendURL, "/") + "/dashboard",
+	}
+}
+
+// NotifyBrokenLinks implements Notifier
+func (n *OwnerNotifier) NotifyBrokenLinks(user *models.User, links []models.Link) error {
+	brokenLinks := make([]email.BrokenLink, 0, len(links))
+	activities := make([]models.Activity, 0, len(links))
+	for _, link := range links {
+		reason := brokenReason(link.Health)
+		target := ""
+		if link.URL != nil {
+			target = *link.URL
+		}
+		brokenLinks = append(brokenLinks, email.BrokenLink{Title: link.Title, URL: target, Reason: reason})
+
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"link_id": link.ID,
+			"url":     target,
+			"reason":  reason,
+		})
+		metadataStr := string(metadata)
+		activities = append(activities, models.Activity{
+			UserID:      user.ID,
+			Type:        ActivityLinkBroken,
+			Description: fmt.Sprintf("Your link \"%s\" appears to be broken: %s", link.Title, reason),
+			Metadata:    &metadataStr,
+		})
+	}
+
+	if err := n.db.Create(&activities).Error; err != nil {
+		return fmt.Errorf("failed to record broken link activity: %w", err)
+	}
+
+	// The activity is the record of the notification; a failed email is not retried
+	if n.emailService != nil && user.Email != nil && *user.Email != "" {
+		if err := n.emailService.SendBrokenLinksEmail(*user.Email, user.Username, brokenLinks, n.dashboardURL); err != nil {
+			log.Printf("Failed to send broken links email to user %d: %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// brokenReason describes the last failed check in words
+func brokenReason(health *models.LinkHealth) string {
+	switch {
+	case health == nil:
+		return "not responding"
+	case health.Error != nil:
+		return *health.Error
+	case health.StatusCode != nil:
+		return fmt.Sprintf("returned %d %s", *health.StatusCode, http.StatusText(*health.StatusCode))
+	default:
+		return "not responding"
+	}
+}
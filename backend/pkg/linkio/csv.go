@@ -0,0 +1,137 @@
+package linkio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvColumns is the column order written on export
+var csvColumns = []string{"title", "url", "description", "type", "icon", "image_url", "color", "is_active"}
+
+// csvAliases maps header names used by other tools to our column names
+var csvAliases = map[string]string{
+	"name":      "title",
+	"label":     "title",
+	"link":      "url",
+	"href":      "url",
+	"address":   "url",
+	"thumbnail": "image_url",
+	"image":     "image_url",
+	"active":    "is_active",
+	"enabled":   "is_active",
+}
+
+func readCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		name = strings.ReplaceAll(name, " ", "_")
+		if alias, ok := csvAliases[name]; ok {
+			name = alias
+		}
+		if _, seen := columns[name]; !seen {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["url"]; !ok {
+		if _, ok := columns["title"]; !ok {
+			return nil, errors.New("CSV header must include a url or title column")
+		}
+	}
+
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return unescapeFormula(strings.TrimSpace(row[i]))
+			}
+			return ""
+		}
+
+		record := Record{
+			Row:         line,
+			Title:       field("title"),
+			URL:         field("url"),
+			Description: field("description"),
+			Type:        strings.ToUpper(field("type")),
+			Icon:        field("icon"),
+			ImageURL:    field("image_url"),
+			Color:       field("color"),
+		}
+		switch strings.ToLower(field("is_active")) {
+		case "true", "1", "yes":
+			active := true
+			record.IsActive = &active
+		case "false", "0", "no":
+			active := false
+			record.IsActive = &active
+		}
+		if record.Title == "" && record.URL == "" {
+			continue
+		}
+		records = append(records, record)
+	}
+}
+
+func writeCSV(w io.Writer, records []Record) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, record := range records {
+		active := ""
+		if record.IsActive != nil {
+			active = strconv.FormatBool(*record.IsActive)
+		}
+		row := []string{
+			escapeFormula(record.Title), record.URL, escapeFormula(record.Description), record.Type,
+			escapeFormula(record.Icon), record.ImageURL, record.Color, active,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeFormula stops spreadsheets from evaluating user text as a formula
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeFormula reverses escapeFormula
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
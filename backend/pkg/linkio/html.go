@@ -0,0 +1,119 @@
+package linkio
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// readHTML parses a Netscape bookmarks file, the format every browser exports.
+// Folders become header records followed by the links they contain, in file order.
+func readHTML(r io.Reader) ([]Record, error) {
+	tokenizer := nethtml.NewTokenizer(r)
+
+	var records []Record
+	var text strings.Builder
+	var current *Record // the anchor or heading whose text is being read
+	inDescription := false
+
+	finish := func() {
+		if current == nil {
+			return
+		}
+		current.Title = strings.Join(strings.Fields(text.String()), " ")
+		if current.Title != "" || current.URL != "" {
+			current.Row = len(records) + 1
+			records = append(records, *current)
+		}
+		current = nil
+	}
+
+	for {
+		switch tokenizer.Next() {
+		case nethtml.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, fmt.Errorf("invalid bookmarks file: %w", err)
+			}
+			finish()
+			return records, nil
+		case nethtml.TextToken:
+			if current != nil {
+				text.Write(tokenizer.Text())
+			} else if inDescription && len(records) > 0 {
+				last := &records[len(records)-1]
+				last.Description = strings.TrimSpace(last.Description + " " + strings.Join(strings.Fields(string(tokenizer.Text())), " "))
+			}
+		case nethtml.StartTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch atom.Lookup(name) {
+			case atom.A:
+				finish()
+				inDescription = false
+				text.Reset()
+				current = &Record{}
+				for hasAttr {
+					var key, value []byte
+					key, value, hasAttr = tokenizer.TagAttr()
+					if strings.EqualFold(string(key), "href") {
+						current.URL = strings.TrimSpace(string(value))
+					}
+				}
+			case atom.H3:
+				finish()
+				inDescription = false
+				text.Reset()
+				current = &Record{Type: TypeHeader}
+			case atom.Dd:
+				inDescription = true
+			case atom.Dt, atom.Dl:
+				inDescription = false
+			}
+		case nethtml.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch atom.Lookup(name) {
+			case atom.A, atom.H3:
+				finish()
+			case atom.Dl:
+				inDescription = false
+			}
+		}
+	}
+}
+
+// writeHTML writes a Netscape bookmarks file; header records open a folder
+// holding the links after them, up to the next header
+func writeHTML(w io.Writer, records []Record) error {
+	out := bufio.NewWriter(w)
+	out.WriteString("<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
+	out.WriteString("<META HTTP-EQUIV=\"Content-Type\" CONTENT=\"text/html; charset=UTF-8\">\n")
+	out.WriteString("<TITLE>Bookmarks</TITLE>\n<H1>Bookmarks</H1>\n<DL><p>\n")
+
+	indent := "    "
+	inFolder := false
+	for _, record := range records {
+		if record.Type == TypeHeader {
+			if inFolder {
+				out.WriteString("    </DL><p>\n")
+			}
+			fmt.Fprintf(out, "    <DT><H3>%s</H3>\n    <DL><p>\n", html.EscapeString(record.Title))
+			indent = "        "
+			inFolder = true
+			continue
+		}
+
+		fmt.Fprintf(out, "%s<DT><A HREF=\"%s\">%s</A>\n", indent, html.EscapeString(record.URL), html.EscapeString(record.Title))
+		if record.Description != "" {
+			fmt.Fprintf(out, "%s<DD>%s\n", indent, html.EscapeString(record.Description))
+		}
+	}
+	if inFolder {
+		out.WriteString("    </DL><p>\n")
+	}
+	out.WriteString("</DL><p>\n")
+	return out.Flush()
+}
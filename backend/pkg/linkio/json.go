@@ -0,0 +1,82 @@
+package linkio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonRecord accepts our own field names plus the ones other tools use
+type jsonRecord struct {
+	Record
+	Name  string `json:"name"`
+	Link  string `json:"link"`
+	Href  string `json:"href"`
+	Image string `json:"image"`
+}
+
+// jsonDocument is an export wrapped in an object, like our profile bundles
+type jsonDocument struct {
+	Links []jsonRecord `json:"links"`
+}
+
+func readJSON(r io.Reader) ([]Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	var items []jsonRecord
+	if data[0] == '[' {
+		err = json.Unmarshal(data, &items)
+	} else {
+		var document jsonDocument
+		err = json.Unmarshal(data, &document)
+		items = document.Links
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	records := make([]Record, 0, len(items))
+	for i, item := range items {
+		record := item.Record
+		record.Row = i + 1
+		record.Title = strings.TrimSpace(firstNonEmpty(record.Title, item.Name))
+		record.URL = strings.TrimSpace(firstNonEmpty(record.URL, item.Link, item.Href))
+		record.ImageURL = firstNonEmpty(record.ImageURL, item.Image)
+		record.Type = strings.ToUpper(record.Type)
+		if record.Title == "" && record.URL == "" {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func writeJSON(w io.Writer, records []Record) error {
+	if records == nil {
+		records = []Record{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Links []Record `json:"links"`
+	}{Links: records})
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+// Package linkio reads and writes link lists in the formats other link-in-bio
+// tools and browsers export: CSV, JSON and Netscape HTML bookmarks.
+package linkio
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Format is a link list file format
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+	FormatHTML Format = "html"
+)
+
+// TypeHeader is the record type of a section heading; bookmark folders map to it
+const TypeHeader = "HEADER"
+
+// Record is one link in an imported or exported list. Row is the 1-based
+// position in the source file (the line for CSV) and is only set on import.
+type Record struct {
+	Row         int    `json:"-"`
+	Title       string `json:"title"`
+	URL         string `json:"url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	Color       string `json:"color,omitempty"`
+	IsActive    *bool  `json:"is_active,omitempty"`
+}
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(name))) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatHTML, "htm", "bookmarks":
+		return FormatHTML, nil
+	}
+	return "", fmt.Errorf("unsupported format %q; use csv, json or html", name)
+}
+
+// DetectFormat guesses the format from a Content-Type header or file name
+func DetectFormat(contentType, fileName string) (Format, bool) {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "text/csv", "application/csv":
+			return FormatCSV, true
+		case "application/json":
+			return FormatJSON, true
+		case "text/html":
+			return FormatHTML, true
+		}
+	}
+	if ext := strings.TrimPrefix(path.Ext(fileName), "."); ext != "" {
+		if format, err := ParseFormat(ext); err == nil {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// ContentType returns the MIME type written for a format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	default:
+		return "text/html; charset=utf-8"
+	}
+}
+
+// Read parses a link list in the given format
+func Read(format Format, r io.Reader) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatJSON:
+		return readJSON(r)
+	case FormatHTML:
+		return readHTML(r)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// Write serializes records in the given format
+func Write(format Format, w io.Writer, records []Record) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, records)
+	case FormatJSON:
+		return writeJSON(w, records)
+	case FormatHTML:
+		return writeHTML(w, records)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// NormalizeURL returns the key used to detect duplicate links: scheme and
+// host are lower-cased, default ports, fragments and trailing slashes dropped
+func NormalizeURL(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" {
+		return strings.TrimSpace(rawURL)
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	host := strings.ToLower(parsed.Host)
+	host = strings.TrimSuffix(host, ":80")
+	host = strings.TrimSuffix(host, ":443")
+	parsed.Host = strings.TrimPrefix(host, "www.")
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	parsed.RawPath = ""
+	return parsed.String()
+}
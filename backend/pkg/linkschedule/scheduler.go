@@ -0,0 +1,206 @@
+package linkschedule
+
+import (
+	"container/heap"
+	"log"
+	"sync"
+	"time"
+
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/redis"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// horizon is how far ahead transitions are loaded from the database
+	horizon = time.Hour
+	// reloadInterval re-reads upcoming transitions; it must be shorter than horizon
+	reloadInterval = 15 * time.Minute
+)
+
+// transition is a moment at which one of a user's links goes live or expires
+type transition struct {
+	at     time.Time
+	userID uint
+}
+
+// transitionQueue is a min-heap of transitions ordered by time
+type transitionQueue []transition
+
+func (q transitionQueue) Len() int            { return len(q) }
+func (q transitionQueue) Less(i, j int) bool  { return q[i].at.Before(q[j].at) }
+func (q transitionQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *transitionQueue) Push(x interface{}) { *q = append(*q, x.(transition)) }
+func (q *transitionQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// Scheduler invalidates public link caches at the exact moment a scheduled
+// link goes live or expires
+type Scheduler struct {
+	db          *gorm.DB
+	redisClient *redis.Client
+
+	mu    sync.Mutex
+	queue transitionQueue
+	wake  chan struct{}
+	quit  chan struct{}
+	done  chan struct{}
+}
+
+// NewScheduler creates a new link schedule watcher
+func NewScheduler(db *gorm.DB, redisClient *redis.Client) *Scheduler {
+	return &Scheduler{
+		db:          db,
+		redisClient: redisClient,
+		wake:        make(chan struct{}, 1),
+		quit:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start loads upcoming transitions and begins watching for them
+func (s *Scheduler) Start() {
+	s.reload()
+	go s.run()
+	log.Println("⏱️ Link schedule watcher started")
+}
+
+// Stop stops the watcher and waits for it to exit
+func (s *Scheduler) Stop() {
+	close(s.quit)
+	<-s.done
+}
+
+// ScheduleLink registers the link's upcoming transitions, if any fall inside the horizon
+func (s *Scheduler) ScheduleLink(link *models.Link) {
+	for _, at := range []*time.Time{link.VisibleFrom, link.VisibleUntil} {
+		if at != nil {
+			s.schedule(link.UserID, *at)
+		}
+	}
+}
+
+// schedule queues a single transition and wakes the watcher if it is now the earliest
+func (s *Scheduler) schedule(userID uint, at time.Time) {
+	now := time.Now()
+	if !at.After(now) || at.After(now.Add(horizon)) {
+		return
+	}
+
+	s.mu.Lock()
+	heap.Push(&s.queue, transition{at: at, userID: userID})
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run waits for the next transition, a wake-up or the periodic reload
+func (s *Scheduler) run() {
+	defer close(s.done)
+
+	reload := time.NewTicker(reloadInterval)
+	defer reload.Stop()
+
+	for {
+		var timer *time.Timer
+		var fire <-chan time.Time
+
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			timer = time.NewTimer(time.Until(s.queue[0].at))
+			fire = timer.C
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-fire:
+			s.fireDue()
+		case <-s.wake:
+		case <-reload.C:
+			s.reload()
+		case <-s.quit:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// fireDue invalidates caches for every transition that has been reached
+func (s *Scheduler) fireDue() {
+	now := time.Now()
+	users := make(map[uint]bool)
+
+	s.mu.Lock()
+	for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+		item := heap.Pop(&s.queue).(transition)
+		users[item.userID] = true
+	}
+	s.mu.Unlock()
+
+	for userID := range users {
+		if s.redisClient != nil {
+			if err := s.redisClient.InvalidateUserLinksCache(userID); err != nil {
+				log.Printf("Failed to invalidate links cache for user %d: %v", userID, err)
+			}
+		}
+	}
+}
+
+// reload replaces the queue with transitions inside the horizon from the database
+func (s *Scheduler) reload() {
+	now := time.Now()
+	until := now.Add(horizon)
+
+	var links []models.Link
+	err := s.db.Select("id, user_id, visible_from, visible_until").
+		Where("(visible_from > ? AND visible_from <= ?) OR (visible_until > ? AND visible_until <= ?)", now, until, now, until).
+		Find(&links).Error
+	if err != nil {
+		log.Printf("Failed to load scheduled links: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Merge with the current queue so transitions scheduled during the query are kept
+	type key struct {
+		at     int64
+		userID uint
+	}
+	seen := make(map[key]bool, len(s.queue)+len(links))
+	queue := make(transitionQueue, 0, len(s.queue)+len(links))
+	add := func(item transition) {
+		k := key{at: item.at.UnixNano(), userID: item.userID}
+		if !seen[k] {
+			seen[k] = true
+			queue = append(queue, item)
+		}
+	}
+	for _, item := range s.queue {
+		add(item)
+	}
+	for _, link := range links {
+		for _, at := range []*time.Time{link.VisibleFrom, link.VisibleUntil} {
+			if at != nil && at.After(now) && !at.After(until) {
+				add(transition{at: *at, userID: link.UserID})
+			}
+		}
+	}
+	heap.Init(&queue)
+	s.queue = queue
+}
@@ -0,0 +1,284 @@
+// Package live fans profile views and link clicks out to dashboards watching
+// them in real time. Events go through Redis pub/sub, so a dashboard connected
+// to any backend instance sees the events written by all of them.
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gotchu-backend/pkg/redis"
+)
+
+const (
+	// channelPrefix is followed by the ID of the user whose events the channel carries
+	channelPrefix = "analytics:live:"
+	// watchedKey is a sorted set of the users being watched on any instance,
+	// scored by when that expires unless it is refreshed
+	watchedKey = "analytics:watched"
+)
+
+// ErrTooManySubscriptions is returned when a user already has the most live
+// streams allowed open on this instance
+var ErrTooManySubscriptions = errors.New("too many live analytics streams")
+
+// Event is a profile view or link click as shown in the live view
+type Event struct {
+	Type     string    `json:"type"` // "view" or "click"
+	LinkID   uint      `json:"link_id,omitempty"`
+	Country  string    `json:"country,omitempty"`
+	Device   string    `json:"device,omitempty"`
+	Browser  string    `json:"browser,omitempty"`
+	Referrer string    `json:"referrer,omitempty"` // referring domain, or the traffic source tag
+	At       time.Time `json:"at"`
+}
+
+// Config controls buffering and how watched users are tracked
+type Config struct {
+	BufferSize      int           // events held per stream before new ones are dropped
+	MaxPerUser      int           // streams a user can have open on one instance
+	WatchTTL        time.Duration // how long a user counts as watched after the last refresh
+	RefreshInterval time.Duration // how often watched users are marked and read back
+}
+
+// DefaultConfig returns the settings used in production
+func DefaultConfig() Config {
+	return Config{
+		BufferSize:      64,
+		MaxPerUser:      5,
+		WatchTTL:        30 * time.Second,
+		RefreshInterval: 5 * time.Second,
+	}
+}
+
+// Subscription receives one user's events. A subscriber that falls behind
+// does not hold up anyone else: events that do not fit in its buffer are
+// dropped and counted.
+type Subscription struct {
+	userID  uint
+	events  chan Event
+	dropped atomic.Uint64
+}
+
+// Events returns the channel events are delivered on
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// TakeDropped returns the number of events dropped since it was last called
+func (s *Subscription) TakeDropped() uint64 {
+	return s.dropped.Swap(0)
+}
+
+// Hub delivers published events to the subscriptions on this instance. Events
+// are only published for users watched on some instance, so traffic to
+// profiles nobody is watching costs no Redis commands.
+type Hub struct {
+	redisClient *redis.Client
+	config      Config
+
+	mu            sync.RWMutex
+	subscriptions map[uint]map[*Subscription]struct{}
+	watched       atomic.Pointer[map[uint]struct{}] // users watched on any instance, as last read from Redis
+
+	quit chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewHub creates a hub; without Redis, events are only delivered on this instance
+func NewHub(redisClient *redis.Client, config Config) *Hub {
+	defaults := DefaultConfig()
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaults.BufferSize
+	}
+	if config.MaxPerUser <= 0 {
+		config.MaxPerUser = defaults.MaxPerUser
+	}
+	if config.WatchTTL <= 0 {
+		config.WatchTTL = defaults.WatchTTL
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = defaults.RefreshInterval
+	}
+
+	h := &Hub{
+		redisClient:   redisClient,
+		config:        config,
+		subscriptions: make(map[uint]map[*Subscription]struct{}),
+		quit:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	h.watched.Store(&map[uint]struct{}{})
+	return h
+}
+
+// Start subscribes to the events published by every instance
+func (h *Hub) Start() {
+	if h.redisClient == nil {
+		close(h.done)
+		log.Println("📡 Live analytics started (single instance, no Redis)")
+		return
+	}
+
+	pubsub := h.redisClient.PSubscribe(context.Background(), channelPrefix+"*")
+	go func() {
+		defer close(h.done)
+		defer pubsub.Close()
+
+		ticker := time.NewTicker(h.config.RefreshInterval)
+		defer ticker.Stop()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				userID, err := strconv.ParseUint(strings.TrimPrefix(message.Channel, channelPrefix), 10, 64)
+				if err != nil {
+					continue
+				}
+				var event Event
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					continue
+				}
+				h.deliver(uint(userID), event)
+			case <-ticker.C:
+				h.refreshWatched()
+			case <-h.quit:
+				return
+			}
+		}
+	}()
+	log.Println("📡 Live analytics started")
+}
+
+// Stop ends every stream and unsubscribes from Redis
+func (h *Hub) Stop() {
+	h.once.Do(func() { close(h.quit) })
+	<-h.done
+}
+
+// Done is closed when the hub stops; streams should end then
+func (h *Hub) Done() <-chan struct{} {
+	return h.quit
+}
+
+// Subscribe opens a stream of a user's events
+func (h *Hub) Subscribe(userID uint) (*Subscription, error) {
+	h.mu.Lock()
+	if len(h.subscriptions[userID]) >= h.config.MaxPerUser {
+		h.mu.Unlock()
+		return nil, ErrTooManySubscriptions
+	}
+	sub := &Subscription{userID: userID, events: make(chan Event, h.config.BufferSize)}
+	if h.subscriptions[userID] == nil {
+		h.subscriptions[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscriptions[userID][sub] = struct{}{}
+	h.mu.Unlock()
+
+	// Let the other instances know right away rather than on the next refresh
+	if h.redisClient != nil {
+		expires := time.Now().Add(h.config.WatchTTL)
+		if err := h.redisClient.AddToSortedSet(watchedKey, float64(expires.Unix()), userID); err != nil {
+			log.Printf("⚠️ Warning: Failed to mark user %d as watched: %v", userID, err)
+		}
+	}
+	return sub, nil
+}
+
+// Unsubscribe closes a stream; no events are delivered to it afterwards
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscriptions[sub.userID], sub)
+	if len(h.subscriptions[sub.userID]) == 0 {
+		delete(h.subscriptions, sub.userID)
+	}
+}
+
+// Publish sends a user's events to every stream watching them, on any instance
+func (h *Hub) Publish(userID uint, events ...Event) {
+	if len(events) == 0 {
+		return
+	}
+	if h.redisClient == nil {
+		for _, event := range events {
+			h.deliver(userID, event)
+		}
+		return
+	}
+
+	if _, watched := (*h.watched.Load())[userID]; !watched && !h.hasSubscriptions(userID) {
+		return
+	}
+	messages := make([]interface{}, len(events))
+	for i, event := range events {
+		messages[i] = event
+	}
+	if err := h.redisClient.Publish(channelPrefix+strconv.FormatUint(uint64(userID), 10), messages...); err != nil {
+		log.Printf("⚠️ Warning: Failed to publish live analytics for user %d: %v", userID, err)
+	}
+}
+
+// deliver hands an event to this instance's streams of a user without blocking
+func (h *Hub) deliver(userID uint, event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscriptions[userID] {
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+func (h *Hub) hasSubscriptions(userID uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscriptions[userID]) > 0
+}
+
+// refreshWatched keeps this instance's watched users marked in Redis and
+// reads back the users watched on every instance
+func (h *Hub) refreshWatched() {
+	h.mu.RLock()
+	local := make([]uint, 0, len(h.subscriptions))
+	for userID := range h.subscriptions {
+		local = append(local, userID)
+	}
+	h.mu.RUnlock()
+
+	now := time.Now()
+	expires := float64(now.Add(h.config.WatchTTL).Unix())
+	for _, userID := range local {
+		h.redisClient.AddToSortedSet(watchedKey, expires, userID)
+	}
+	h.redisClient.RemoveSortedSetByScore(watchedKey, float64(now.Unix()))
+
+	members, err := h.redisClient.GetSortedSetByScore(watchedKey, float64(now.Unix()), expires)
+	if err != nil {
+		log.Printf("⚠️ Warning: Failed to read watched users: %v", err)
+		return
+	}
+	watched := make(map[uint]struct{}, len(members))
+	for _, member := range members {
+		if userID, err := strconv.ParseUint(member, 10, 64); err == nil {
+			watched[uint(userID)] = struct{}{}
+		}
+	}
+	h.watched.Store(&watched)
+}
@@ -0,0 +1,285 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode/qr"
+)
+
+const (
+	// MinSize is the smallest rendered image edge in pixels
+	MinSize = 128
+	// MaxSize is the largest rendered image edge in pixels
+	MaxSize = 2048
+	// DefaultSize is used when no size is requested
+	DefaultSize = 512
+	// DefaultMargin is the quiet zone width in modules required by the QR spec
+	DefaultMargin = 4
+	// logoScale is the fraction of the code width covered by a center logo
+	logoScale = 0.22
+	// MinContrast is the lowest foreground/background contrast ratio that scans reliably
+	MinContrast = 2.0
+)
+
+// Level is a QR error correction level
+type Level string
+
+const (
+	LevelLow      Level = "L"
+	LevelMedium   Level = "M"
+	LevelQuartile Level = "Q"
+	LevelHigh     Level = "H"
+)
+
+// Options controls how a QR code is rendered
+type Options struct {
+	Level      Level
+	Size       int
+	Margin     int
+	Foreground color.RGBA
+	Background color.RGBA
+	Logo       image.Image
+}
+
+// Code is an encoded QR matrix ready to be rendered
+type Code struct {
+	modules [][]bool
+	options Options
+}
+
+// Encode encodes content into a QR matrix. A center logo raises the error
+// correction level to at least Q so the covered modules can be recovered.
+func Encode(content string, opts Options) (*Code, error) {
+	if opts.Size == 0 {
+		opts.Size = DefaultSize
+	}
+	if opts.Size < MinSize || opts.Size > MaxSize {
+		return nil, fmt.Errorf("size must be between %d and %d", MinSize, MaxSize)
+	}
+	if opts.Margin < 0 {
+		opts.Margin = DefaultMargin
+	}
+	if opts.Level == "" {
+		opts.Level = LevelMedium
+	}
+	if opts.Logo != nil && (opts.Level == LevelLow || opts.Level == LevelMedium) {
+		opts.Level = LevelQuartile
+	}
+
+	level, err := parseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	barcode, err := qr.Encode(content, level, qr.Auto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	bounds := barcode.Bounds()
+	modules := make([][]bool, bounds.Dy())
+	for y := range modules {
+		modules[y] = make([]bool, bounds.Dx())
+		for x := range modules[y] {
+			gray := color.GrayModel.Convert(barcode.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			modules[y][x] = gray.Y < 128
+		}
+	}
+
+	return &Code{modules: modules, options: opts}, nil
+}
+
+// parseLevel maps a Level to the encoder's error correction level
+func parseLevel(level Level) (qr.ErrorCorrectionLevel, error) {
+	switch Level(strings.ToUpper(string(level))) {
+	case LevelLow:
+		return qr.L, nil
+	case LevelMedium:
+		return qr.M, nil
+	case LevelQuartile:
+		return qr.Q, nil
+	case LevelHigh:
+		return qr.H, nil
+	default:
+		return qr.M, fmt.Errorf("invalid error correction level: %s", level)
+	}
+}
+
+// layout returns the module pixel size and the offset that centers the code
+func (c *Code) layout() (scale, offset int) {
+	total := len(c.modules) + 2*c.options.Margin
+	scale = c.options.Size / total
+	if scale < 1 {
+		scale = 1
+	}
+	offset = (c.options.Size-total*scale)/2 + c.options.Margin*scale
+	return scale, offset
+}
+
+// logoRect returns the centered square reserved for the logo
+func (c *Code) logoRect() image.Rectangle {
+	scale, offset := c.layout()
+	codeWidth := len(c.modules) * scale
+	logoWidth := int(float64(codeWidth) * logoScale)
+	start := offset + (codeWidth-logoWidth)/2
+	return image.Rect(start, start, start+logoWidth, start+logoWidth)
+}
+
+// PNG renders the code as a PNG image
+func (c *Code) PNG() ([]byte, error) {
+	size := c.options.Size
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: c.options.Background}, image.Point{}, draw.Src)
+
+	scale, offset := c.layout()
+	fg := &image.Uniform{C: c.options.Foreground}
+	for y, row := range c.modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			rect := image.Rect(offset+x*scale, offset+y*scale, offset+(x+1)*scale, offset+(y+1)*scale)
+			draw.Draw(img, rect, fg, image.Point{}, draw.Src)
+		}
+	}
+
+	if c.options.Logo != nil {
+		rect := c.logoRect()
+		pad := rect.Dx() / 10
+		draw.Draw(img, rect.Inset(-pad), &image.Uniform{C: c.options.Background}, image.Point{}, draw.Src)
+		draw.Draw(img, rect, scaleImage(c.options.Logo, rect.Dx()), image.Point{}, draw.Over)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the code as an SVG document. Dark modules are merged into a
+// single path and the logo, if any, is embedded as a data URI.
+func (c *Code) SVG() ([]byte, error) {
+	size := c.options.Size
+	scale, offset := c.layout()
+
+	var path strings.Builder
+	for y, row := range c.modules {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			run := 1
+			for x+run < len(row) && row[x+run] {
+				run++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv%dh-%dz", offset+x*scale, offset+y*scale, run*scale, scale, run*scale)
+			x += run - 1
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, size, size)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`, Hex(c.options.Background))
+	fmt.Fprintf(&buf, `<path fill="%s" d="%s"/>`, Hex(c.options.Foreground), path.String())
+
+	if c.options.Logo != nil {
+		rect := c.logoRect()
+		var logo bytes.Buffer
+		if err := png.Encode(&logo, scaleImage(c.options.Logo, rect.Dx())); err != nil {
+			return nil, fmt.Errorf("failed to encode logo: %w", err)
+		}
+		pad := rect.Dx() / 10
+		padded := rect.Inset(-pad)
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, padded.Min.X, padded.Min.Y, padded.Dx(), padded.Dy(), Hex(c.options.Background))
+		fmt.Fprintf(&buf, `<image x="%d" y="%d" width="%d" height="%d" href="data:image/png;base64,%s"/>`, rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), base64.StdEncoding.EncodeToString(logo.Bytes()))
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes(), nil
+}
+
+// scaleImage resizes src to a width x width square using box sampling
+func scaleImage(src image.Image, width int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, width))
+	bounds := src.Bounds()
+	if width <= 0 || bounds.Empty() {
+		return dst
+	}
+
+	for y := 0; y < width; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/width
+		y1 := bounds.Min.Y + (y+1)*bounds.Dy()/width
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := bounds.Min.X + (x+1)*bounds.Dx()/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// ParseColor parses a #RGB or #RRGGBB hex color, with or without the leading #
+func ParseColor(value string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(value), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color: %s", value)
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color: %s", value)
+	}
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, nil
+}
+
+// Hex formats a color as #rrggbb
+func Hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Contrast returns the WCAG contrast ratio between two colors
+func Contrast(a, b color.RGBA) float64 {
+	la, lb := luminance(a), luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// luminance returns the relative luminance of a color
+func luminance(c color.RGBA) float64 {
+	channel := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.R) + 0.7152*channel(c.G) + 0.0722*channel(c.B)
+}
@@ -0,0 +1,263 @@
+package rollup
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/workers"
+
+	"gorm.io/gorm"
+)
+
+// minRetention keeps raw views around for the discover trending score, which
+// reads the last 14 days of them
+const minRetention = 14 * 24 * time.Hour
+
+// Config controls how often rollups run and how long raw events are kept
+type Config struct {
+	Interval   time.Duration // time between runs; today's rollups lag by up to this much
+	Retention  time.Duration // age after which raw views and clicks are deleted; 0 keeps them forever
+	PurgeBatch int           // rows deleted per statement, to keep locks short
+}
+
+// DefaultConfig returns the settings used in production
+func DefaultConfig() Config {
+	return Config{
+		Interval:   5 * time.Minute,
+		Retention:  90 * 24 * time.Hour,
+		PurgeBatch: 10000,
+	}
+}
+
+// Roller maintains the daily profile and link rollups that analytics are read
+// from, and deletes raw events once they are past the retention window
+type Roller struct {
+	db     *gorm.DB
+	pool   *workers.WorkerPool
+	config Config
+	mu     sync.Mutex // held while a run is in progress
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// NewRoller creates a rollup job
+func NewRoller(db *gorm.DB, pool *workers.WorkerPool, config Config) *Roller {
+	if config.Retention > 0 && config.Retention < minRetention {
+		config.Retention = minRetention
+	}
+	if config.PurgeBatch <= 0 {
+		config.PurgeBatch = DefaultConfig().PurgeBatch
+	}
+	return &Roller{
+		db:     db,
+		pool:   pool,
+		config: config,
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start runs the job right away, to backfill after downtime, and then on every interval
+func (r *Roller) Start() {
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		r.submit()
+		for {
+			select {
+			case <-ticker.C:
+				r.submit()
+			case <-r.quit:
+				return
+			}
+		}
+	}()
+	log.Println("📊 Analytics rollups started")
+}
+
+// Stop stops scheduling runs
+func (r *Roller) Stop() {
+	close(r.quit)
+	<-r.done
+}
+
+func (r *Roller) submit() {
+	// A backfill can take far longer than the pool's default job timeout
+	r.pool.Submit(workers.Job{ID: "analytics-rollup", Handler: r.Run, Timeout: time.Hour})
+}
+
+// Run rolls up every day from the latest one already rolled up through
+// today, then purges expired raw events. Runs do not overlap.
+func (r *Roller) Run() error {
+	if !r.mu.TryLock() {
+		return nil
+	}
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	today := truncateDay(now)
+
+	from, err := r.firstDay(today)
+	if err != nil {
+		return err
+	}
+	days := 0
+	for day := from; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if err := r.rollDay(day); err != nil {
+			return fmt.Errorf("failed to roll up analytics for %s: %w", day.Format("2006-01-02"), err)
+		}
+		days++
+	}
+
+	var views, clicks int64
+	if r.config.Retention > 0 {
+		// Everything before today has just been rolled up, so only whole
+		// days that are already in the rollups are deleted
+		cutoff := truncateDay(now.Add(-r.config.Retention))
+		if views, err = r.purge("profile_views", cutoff); err != nil {
+			return err
+		}
+		if clicks, err = r.purge("link_clicks", cutoff); err != nil {
+			return err
+		}
+	}
+
+	if days > 2 || views > 0 || clicks > 0 {
+		log.Printf("📊 Rolled up %d days of analytics, purged %d raw views and %d raw clicks", days, views, clicks)
+	}
+	return nil
+}
+
+// firstDay returns the day to start rolling up from: the latest day already
+// in the rollups, which may have been partial, but no later than yesterday
+// so events that landed around midnight are counted. With no rollups yet it
+// is the day of the oldest raw event.
+func (r *Roller) firstDay(today time.Time) (time.Time, error) {
+	yesterday := today.AddDate(0, 0, -1)
+
+	var latest sql.NullTime
+	err := r.db.Raw("SELECT GREATEST((SELECT MAX(day) FROM daily_profile_stats), (SELECT MAX(day) FROM daily_link_stats))").
+		Scan(&latest).Error
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to find the latest rollup: %w", err)
+	}
+	if latest.Valid {
+		day := truncateDay(latest.Time)
+		if day.After(yesterday) {
+			day = yesterday
+		}
+		return day, nil
+	}
+
+	var oldest sql.NullTime
+	err = r.db.Raw("SELECT LEAST((SELECT MIN(created_at) FROM profile_views), (SELECT MIN(created_at) FROM link_clicks))").
+		Scan(&oldest).Error
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to find the oldest raw event: %w", err)
+	}
+	if oldest.Valid {
+		return truncateDay(oldest.Time.UTC()), nil
+	}
+	return today, nil
+}
+
+// rollDay recomputes one day's rollups from the raw events
+func (r *Roller) rollDay(day time.Time) error {
+	date := day.Format("2006-01-02")
+	next := day.AddDate(0, 0, 1)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("day = ?", date).Delete(&models.DailyProfileStat{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("day = ?", date).Delete(&models.DailyLinkStat{}).Error; err != nil {
+			return err
+		}
+
+		err := tx.Exec(`
+			INSERT INTO daily_profile_stats (user_id, day, dimension, value, views)
+			SELECT e.user_id, ?::date, d.dimension, d.value, COUNT(*)
+			FROM (
+				SELECT user_id, `+eventDimensions("profile_views")+`
+				FROM profile_views
+				WHERE created_at >= ? AND created_at < ?
+			) e
+			CROSS JOIN LATERAL (VALUES `+dimensionValues+`) AS d(dimension, value)
+			GROUP BY e.user_id, d.dimension, d.value
+		`, date, referrerHostPattern, day, next).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			INSERT INTO daily_link_stats (link_id, day, dimension, value, user_id, clicks)
+			SELECT e.link_id, ?::date, d.dimension, d.value, e.user_id, COUNT(*)
+			FROM (
+				SELECT link_clicks.link_id, links.user_id, `+eventDimensions("link_clicks")+`
+				FROM link_clicks
+				JOIN links ON links.id = link_clicks.link_id
+				WHERE link_clicks.created_at >= ? AND link_clicks.created_at < ?
+			) e
+			CROSS JOIN LATERAL (VALUES `+dimensionValues+`) AS d(dimension, value)
+			GROUP BY e.link_id, e.user_id, d.dimension, d.value
+		`, date, referrerHostPattern, day, next).Error
+	})
+}
+
+// eventDimensions selects the dimension values of a profile_views or
+// link_clicks row. The referrer is the referring host without "www.", unless
+// the visit was tagged with a traffic source. The host pattern is bound as
+// referrerHostPattern since its question marks would be taken for placeholders.
+func eventDimensions(table string) string {
+	return fmt.Sprintf(`
+		LEFT(COALESCE(%[1]s.country, ''), 255) AS country,
+		LEFT(COALESCE(%[1]s.device, ''), 255) AS device,
+		LEFT(COALESCE(%[1]s.browser, ''), 255) AS browser,
+		CASE
+			WHEN %[1]s.source IS NOT NULL AND %[1]s.source <> '' THEN %[1]s.source
+			ELSE LEFT(COALESCE(regexp_replace(LOWER(substring(%[1]s.referer FROM ?)), '^www\.', ''), ''), 255)
+		END AS referrer`, table)
+}
+
+// referrerHostPattern captures the host of a URL
+const referrerHostPattern = `^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^/?#]*@)?([^/:?#]+)`
+
+// dimensionValues pairs each rollup dimension with its column in eventDimensions
+const dimensionValues = `('` + models.RollupTotal + `', ''), ('` + models.RollupCountry + `', e.country), ('` +
+	models.RollupDevice + `', e.device), ('` + models.RollupBrowser + `', e.browser), ('` + models.RollupReferrer + `', e.referrer)`
+
+// purge deletes raw events created before cutoff, a batch at a time
+func (r *Roller) purge(table string, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		result := r.db.Exec(
+			fmt.Sprintf("DELETE FROM %s WHERE id IN (SELECT id FROM %s WHERE created_at < ? LIMIT ?)", table, table),
+			cutoff, r.config.PurgeBatch,
+		)
+		if result.Error != nil {
+			return total, fmt.Errorf("failed to purge %s: %w", table, result.Error)
+		}
+		total += result.RowsAffected
+		if result.RowsAffected < int64(r.config.PurgeBatch) {
+			return total, nil
+		}
+
+		select {
+		case <-r.quit:
+			return total, nil
+		default:
+		}
+	}
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,132 @@
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds a whole request including redirects and the body read
+	DefaultTimeout = 10 * time.Second
+	// DefaultMaxRedirects is the redirect limit used by NewClient
+	DefaultMaxRedirects = 5
+)
+
+// ErrTooLarge is returned when a response body exceeds the read limit
+var ErrTooLarge = errors.New("response body too large")
+
+// NewClient returns an HTTP client for fetching user-supplied URLs. It only
+// connects to public addresses (checked after DNS resolution, so rebinding
+// does not help), ignores proxy settings, and only follows http and https
+// redirects.
+func NewClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+				return fmt.Errorf("refusing to connect to non-public address %s", host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			MaxIdleConns:          50,
+			IdleConnTimeout:       30 * time.Second,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > DefaultMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", DefaultMaxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("refusing to follow redirect to %s URL", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// IsPublicIP reports whether ip is a globally routable unicast address
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		// Carrier-grade NAT and the broadcast address
+		if (ip4[0] == 100 && ip4[1]&0xc0 == 64) || ip4.Equal(net.IPv4bcast) {
+			return false
+		}
+	}
+	return true
+}
+
+// Response is a fully read response body
+type Response struct {
+	StatusCode  int
+	ContentType string
+	FinalURL    string
+	Body        []byte
+}
+
+// Get fetches rawURL and reads at most maxBytes of the body. Bodies over the
+// limit fail with ErrTooLarge unless truncate is set, in which case the first
+// maxBytes are returned.
+func Get(ctx context.Context, client *http.Client, rawURL, accept string, maxBytes int64, truncate bool) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "GotchuBot/1.0 (+https://gotchu.lol)")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.ContentLength > maxBytes && !truncate {
+		return nil, ErrTooLarge
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBytes {
+		if !truncate {
+			return nil, ErrTooLarge
+		}
+		body = body[:maxBytes]
+	}
+
+	return &Response{
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		FinalURL:    resp.Request.URL.String(),
+		Body:        body,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []Column
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer, columns: columns, record: make([]string, len(columns))}, nil
+}
+
+func (w *csvWriter) Write(row []interface{}) error {
+	if err := checkRow(w.columns, row); err != nil {
+		return err
+	}
+	for i, column := range w.columns {
+		value, err := normalize(column, row[i])
+		if err != nil {
+			return err
+		}
+		switch v := value.(type) {
+		case nil:
+			w.record[i] = ""
+		case string:
+			w.record[i] = escapeFormula(v)
+		case int64:
+			w.record[i] = strconv.FormatInt(v, 10)
+		case time.Time:
+			if column.Type == Date {
+				w.record[i] = v.Format("2006-01-02")
+			} else {
+				w.record[i] = v.Format(time.RFC3339)
+			}
+		}
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// escapeFormula stops spreadsheets from evaluating visitor supplied text,
+// such as a referrer, as a formula
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,62 @@
+package tabular
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// ndjsonWriter writes one JSON object per row, with keys in column order
+type ndjsonWriter struct {
+	writer  *bufio.Writer
+	columns []Column
+	keys    [][]byte
+}
+
+func newNDJSONWriter(w io.Writer, columns []Column) *ndjsonWriter {
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		key, _ := json.Marshal(column.Name)
+		keys[i] = append(key, ':')
+	}
+	return &ndjsonWriter{writer: bufio.NewWriter(w), columns: columns, keys: keys}
+}
+
+func (w *ndjsonWriter) Write(row []interface{}) error {
+	if err := checkRow(w.columns, row); err != nil {
+		return err
+	}
+
+	w.writer.WriteByte('{')
+	for i, column := range w.columns {
+		value, err := normalize(column, row[i])
+		if err != nil {
+			return err
+		}
+		if t, ok := value.(time.Time); ok {
+			if column.Type == Date {
+				value = t.Format("2006-01-02")
+			} else {
+				value = t.Format(time.RFC3339)
+			}
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			w.writer.WriteByte(',')
+		}
+		w.writer.Write(w.keys[i])
+		w.writer.Write(encoded)
+	}
+	w.writer.WriteByte('}')
+	_, err := w.writer.WriteString("\n")
+	return err
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.writer.Flush()
+}
@@ -0,0 +1,306 @@
+package tabular
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+// Parquet files are written without compression or dictionaries, one data
+// page per column chunk: all columns are optional and PLAIN encoded. That is
+// all notebooks and spreadsheet tools need to read them, and is little enough
+// to write here rather than take on a Parquet library. parquet_test.go reads
+// the files back with a decoder written from the format spec.
+const (
+	parquetMagic        = "PAR1"
+	parquetRowGroupRows = 50000
+	parquetRowGroupSize = 64 << 20 // bytes buffered before a row group is written early
+	parquetCreatedBy    = "gotchu-backend"
+)
+
+// Parquet enum values, from the format's parquet.thrift
+const (
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetOptional = 1
+
+	parquetUTF8            = 0
+	parquetDate            = 6
+	parquetTimestampMillis = 9
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+)
+
+type parquetWriter struct {
+	out     io.Writer
+	offset  int64
+	columns []Column
+	started bool
+	closed  bool
+
+	// The row group being built
+	levels   [][]byte // definition level of each row, per column: 0 is null
+	values   [][]byte // PLAIN encoded non-null values, per column
+	rows     int
+	buffered int
+
+	numRows   int64
+	rowGroups []parquetRowGroup
+}
+
+type parquetRowGroup struct {
+	chunks    []parquetChunk
+	numRows   int64
+	totalSize int64
+}
+
+type parquetChunk struct {
+	offset int64
+	size   int64
+}
+
+func newParquetWriter(w io.Writer, columns []Column) *parquetWriter {
+	return &parquetWriter{
+		out:     w,
+		columns: columns,
+		levels:  make([][]byte, len(columns)),
+		values:  make([][]byte, len(columns)),
+	}
+}
+
+func (w *parquetWriter) Write(row []interface{}) error {
+	if w.closed {
+		return errors.New("parquet writer is closed")
+	}
+	if err := checkRow(w.columns, row); err != nil {
+		return err
+	}
+
+	// Normalize the whole row first so a bad value leaves no partial row behind
+	normalized := make([]interface{}, len(row))
+	for i, column := range w.columns {
+		value, err := normalize(column, row[i])
+		if err != nil {
+			return err
+		}
+		normalized[i] = value
+	}
+
+	for i, value := range normalized {
+		if value == nil {
+			w.levels[i] = append(w.levels[i], 0)
+			continue
+		}
+		w.levels[i] = append(w.levels[i], 1)
+
+		before := len(w.values[i])
+		switch v := value.(type) {
+		case string:
+			w.values[i] = binary.LittleEndian.AppendUint32(w.values[i], uint32(len(v)))
+			w.values[i] = append(w.values[i], v...)
+		case int64:
+			w.values[i] = binary.LittleEndian.AppendUint64(w.values[i], uint64(v))
+		case time.Time:
+			if w.columns[i].Type == Date {
+				days := math.Floor(float64(v.Unix()) / 86400)
+				w.values[i] = binary.LittleEndian.AppendUint32(w.values[i], uint32(int32(days)))
+			} else {
+				w.values[i] = binary.LittleEndian.AppendUint64(w.values[i], uint64(v.UnixMilli()))
+			}
+		}
+		w.buffered += len(w.values[i]) - before
+	}
+	w.rows++
+
+	if w.rows >= parquetRowGroupRows || w.buffered >= parquetRowGroupSize {
+		return w.flushRowGroup()
+	}
+	return nil
+}
+
+func (w *parquetWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if err := w.flushRowGroup(); err != nil {
+		return err
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	footer := w.fileMetaData()
+	if err := w.write(footer); err != nil {
+		return err
+	}
+	var trailer [8]byte
+	binary.LittleEndian.PutUint32(trailer[:4], uint32(len(footer)))
+	copy(trailer[4:], parquetMagic)
+	return w.write(trailer[:])
+}
+
+func (w *parquetWriter) write(p []byte) error {
+	n, err := w.out.Write(p)
+	w.offset += int64(n)
+	return err
+}
+
+// start writes the leading magic number
+func (w *parquetWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	return w.write([]byte(parquetMagic))
+}
+
+// flushRowGroup writes the buffered rows as a row group with one column
+// chunk, made of a single data page, per column
+func (w *parquetWriter) flushRowGroup() error {
+	if w.rows == 0 {
+		return nil
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	group := parquetRowGroup{numRows: int64(w.rows)}
+	for i := range w.columns {
+		levels := encodeLevels(w.levels[i])
+		page := make([]byte, 0, 4+len(levels)+len(w.values[i]))
+		page = binary.LittleEndian.AppendUint32(page, uint32(len(levels)))
+		page = append(page, levels...)
+		page = append(page, w.values[i]...)
+
+		var header thriftWriter
+		header.begin()
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(page)))
+		header.structField(5)
+		header.i32(1, int32(w.rows))
+		header.i32(2, parquetEncodingPlain)
+		header.i32(3, parquetEncodingRLE)
+		header.i32(4, parquetEncodingRLE)
+		header.end()
+		header.end()
+
+		chunk := parquetChunk{offset: w.offset, size: int64(len(header.buf) + len(page))}
+		if err := w.write(header.buf); err != nil {
+			return err
+		}
+		if err := w.write(page); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		group.totalSize += chunk.size
+
+		w.levels[i] = w.levels[i][:0]
+		w.values[i] = w.values[i][:0]
+	}
+
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += int64(w.rows)
+	w.rows = 0
+	w.buffered = 0
+	return nil
+}
+
+// fileMetaData encodes the footer: the schema and where each column chunk is
+func (w *parquetWriter) fileMetaData() []byte {
+	var t thriftWriter
+	t.begin()
+	t.i32(1, 1) // format version
+
+	t.list(2, thriftStruct, len(w.columns)+1)
+	t.begin()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(w.columns)))
+	t.end()
+	for _, column := range w.columns {
+		physical, converted := parquetTypes(column.Type)
+		t.begin()
+		t.i32(1, physical)
+		t.i32(3, parquetOptional)
+		t.binary(4, column.Name)
+		if converted >= 0 {
+			t.i32(6, converted)
+		}
+		t.end()
+	}
+
+	t.i64(3, w.numRows)
+
+	t.list(4, thriftStruct, len(w.rowGroups))
+	for _, group := range w.rowGroups {
+		t.begin()
+		t.list(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			physical, _ := parquetTypes(w.columns[i].Type)
+			t.begin()
+			t.i64(2, chunk.offset)
+			t.structField(3)
+			t.i32(1, physical)
+			t.list(2, thriftI32, 2)
+			t.listI32(parquetEncodingPlain)
+			t.listI32(parquetEncodingRLE)
+			t.list(3, thriftBinary, 1)
+			t.listString(w.columns[i].Name)
+			t.i32(4, 0) // UNCOMPRESSED
+			t.i64(5, group.numRows)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.end()
+			t.end()
+		}
+		t.i64(2, group.totalSize)
+		t.i64(3, group.numRows)
+		t.end()
+	}
+
+	t.binary(6, parquetCreatedBy)
+	t.end()
+	return t.buf
+}
+
+// parquetTypes returns the physical and converted type of a column; the
+// converted type is -1 when there is none
+func parquetTypes(typ Type) (int32, int32) {
+	switch typ {
+	case Int64:
+		return parquetInt64, -1
+	case Timestamp:
+		return parquetInt64, parquetTimestampMillis
+	case Date:
+		return parquetInt32, parquetDate
+	default:
+		return parquetByteArray, parquetUTF8
+	}
+}
+
+// encodeLevels encodes definition levels with the RLE/bit-packing hybrid
+// encoding, as RLE runs only; with a bit width of 1 each run's value takes
+// one byte
+func encodeLevels(levels []byte) []byte {
+	var out []byte
+	var scratch [binary.MaxVarintLen64]byte
+	for start := 0; start < len(levels); {
+		end := start + 1
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+		n := binary.PutUvarint(scratch[:], uint64(end-start)<<1)
+		out = append(out, scratch[:n]...)
+		out = append(out, levels[start])
+		start = end
+	}
+	return out
+}
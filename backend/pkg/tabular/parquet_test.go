@@ -0,0 +1,393 @@
+package tabular
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+// The files are read back with a decoder written from the Parquet and Thrift
+// compact protocol specs rather than with thriftWriter, so a mistake in the
+// writer cannot cancel itself out.
+
+// readThriftStruct decodes a compact protocol struct into its fields by id:
+// integers as int64, binary as string, lists as []interface{} and structs as
+// map[int16]interface{}
+func readThriftStruct(r *bytes.Reader) (map[int16]interface{}, error) {
+	fields := make(map[int16]interface{})
+	var lastID int16
+	for {
+		header, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if header == 0 {
+			return fields, nil
+		}
+		id := lastID + int16(header>>4)
+		if header>>4 == 0 {
+			v, err := readZigzag(r)
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		value, err := readThriftValue(r, header&0x0f)
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %w", id, err)
+		}
+		fields[id] = value
+		lastID = id
+	}
+}
+
+func readThriftValue(r *bytes.Reader, typ byte) (interface{}, error) {
+	switch typ {
+	case 1, 2: // boolean true and false, in the field header
+		return typ == 1, nil
+	case 3:
+		b, err := r.ReadByte()
+		return int64(int8(b)), err
+	case 4, 5, 6: // i16, i32, i64
+		return readZigzag(r)
+	case 7:
+		var bits uint64
+		err := binary.Read(r, binary.LittleEndian, &bits)
+		return math.Float64frombits(bits), err
+	case 8:
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size)
+		_, err = io.ReadFull(r, data)
+		return string(data), err
+	case 9, 10: // list, set
+		header, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 15 {
+			if size, err = binary.ReadUvarint(r); err != nil {
+				return nil, err
+			}
+		}
+		elements := make([]interface{}, 0, size)
+		for i := uint64(0); i < size; i++ {
+			element, err := readThriftValue(r, header&0x0f)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+		}
+		return elements, nil
+	case 12:
+		return readThriftStruct(r)
+	}
+	return nil, fmt.Errorf("unsupported thrift type %d", typ)
+}
+
+func readZigzag(r *bytes.Reader) (int64, error) {
+	v, err := binary.ReadUvarint(r)
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+// parquetFile is what the test decoder reads out of a file
+type parquetFile struct {
+	schema    []map[int16]interface{} // schema elements, the root first
+	numRows   int64
+	rowGroups int
+	columns   [][]interface{} // values by column then row; nil is null
+}
+
+// readParquetFile decodes a file of flat optional columns made of
+// uncompressed PLAIN data pages, checking the layout along the way
+func readParquetFile(data []byte) (*parquetFile, error) {
+	if len(data) < 12 || string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		return nil, fmt.Errorf("missing PAR1 magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerLen
+	if footerStart < 4 {
+		return nil, fmt.Errorf("footer length %d out of range", footerLen)
+	}
+	footer := bytes.NewReader(data[footerStart : len(data)-8])
+	meta, err := readThriftStruct(footer)
+	if err != nil {
+		return nil, fmt.Errorf("file metadata: %w", err)
+	}
+	if footer.Len() != 0 {
+		return nil, fmt.Errorf("%d bytes left after the file metadata", footer.Len())
+	}
+
+	file := &parquetFile{numRows: meta[3].(int64)}
+	for _, element := range meta[2].([]interface{}) {
+		file.schema = append(file.schema, element.(map[int16]interface{}))
+	}
+	types := make([]int64, 0, len(file.schema))
+	for _, element := range file.schema[1:] {
+		types = append(types, element[1].(int64))
+	}
+	file.columns = make([][]interface{}, len(types))
+
+	groups, _ := meta[4].([]interface{})
+	file.rowGroups = len(groups)
+	for g, group := range groups {
+		group := group.(map[int16]interface{})
+		groupRows := group[3].(int64)
+		chunks := group[1].([]interface{})
+		if len(chunks) != len(types) {
+			return nil, fmt.Errorf("row group %d has %d column chunks for %d columns", g, len(chunks), len(types))
+		}
+		var groupSize int64
+		for c, chunk := range chunks {
+			columnMeta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+			if columnMeta[1].(int64) != types[c] || columnMeta[4].(int64) != 0 || columnMeta[5].(int64) != groupRows {
+				return nil, fmt.Errorf("row group %d column %d: unexpected metadata %v", g, c, columnMeta)
+			}
+			offset, size := columnMeta[9].(int64), columnMeta[7].(int64)
+			groupSize += size
+			if offset < 4 || offset+size > int64(footerStart) {
+				return nil, fmt.Errorf("row group %d column %d: chunk out of range", g, c)
+			}
+			values, err := readDataPage(data[offset:offset+size], types[c], groupRows)
+			if err != nil {
+				return nil, fmt.Errorf("row group %d column %d: %w", g, c, err)
+			}
+			file.columns[c] = append(file.columns[c], values...)
+		}
+		if group[2].(int64) != groupSize {
+			return nil, fmt.Errorf("row group %d: total size %d, chunks add up to %d", g, group[2], groupSize)
+		}
+	}
+	return file, nil
+}
+
+// readDataPage decodes a column chunk holding a single data page
+func readDataPage(chunk []byte, physical, rows int64) ([]interface{}, error) {
+	r := bytes.NewReader(chunk)
+	header, err := readThriftStruct(r)
+	if err != nil {
+		return nil, fmt.Errorf("page header: %w", err)
+	}
+	dataHeader := header[5].(map[int16]interface{})
+	if header[1].(int64) != 0 || dataHeader[1].(int64) != rows || dataHeader[2].(int64) != 0 || dataHeader[3].(int64) != 3 {
+		return nil, fmt.Errorf("unexpected page header %v", header)
+	}
+	if int64(r.Len()) != header[2].(int64) || header[2] != header[3] {
+		return nil, fmt.Errorf("page size %d does not match the %d bytes after the header", header[2], r.Len())
+	}
+
+	var levelsLen uint32
+	if err := binary.Read(r, binary.LittleEndian, &levelsLen); err != nil {
+		return nil, err
+	}
+	levelData := make([]byte, levelsLen)
+	if _, err := io.ReadFull(r, levelData); err != nil {
+		return nil, err
+	}
+	levels, err := readLevels(levelData, int(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 0, rows)
+	for _, level := range levels {
+		if level == 0 {
+			values = append(values, nil)
+			continue
+		}
+		switch physical {
+		case 1: // INT32
+			var v int32
+			err = binary.Read(r, binary.LittleEndian, &v)
+			values = append(values, v)
+		case 2: // INT64
+			var v int64
+			err = binary.Read(r, binary.LittleEndian, &v)
+			values = append(values, v)
+		case 6: // BYTE_ARRAY
+			var size uint32
+			if err = binary.Read(r, binary.LittleEndian, &size); err == nil {
+				v := make([]byte, size)
+				_, err = io.ReadFull(r, v)
+				values = append(values, string(v))
+			}
+		default:
+			return nil, fmt.Errorf("unsupported physical type %d", physical)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d bytes left after the values", r.Len())
+	}
+	return values, nil
+}
+
+// readLevels decodes definition levels in the RLE/bit-packing hybrid
+// encoding with a bit width of 1
+func readLevels(data []byte, count int) ([]byte, error) {
+	r := bytes.NewReader(data)
+	levels := make([]byte, 0, count)
+	for r.Len() > 0 {
+		header, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if header&1 == 0 {
+			value, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			for i := uint64(0); i < header>>1; i++ {
+				levels = append(levels, value)
+			}
+			continue
+		}
+		for i := uint64(0); i < header>>1; i++ {
+			packed, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			for bit := 0; bit < 8; bit++ {
+				levels = append(levels, packed>>bit&1)
+			}
+		}
+	}
+	if len(levels) < count {
+		return nil, fmt.Errorf("got %d definition levels for %d rows", len(levels), count)
+	}
+	return levels[:count], nil
+}
+
+var testColumns = []Column{
+	{Name: "path", Type: String},
+	{Name: "clicks", Type: Int64},
+	{Name: "at", Type: Timestamp},
+	{Name: "day", Type: Date},
+}
+
+// writeParquet writes rows to an in-memory Parquet file and reads it back
+func writeParquet(t *testing.T, columns []Column, rows [][]interface{}) *parquetFile {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatParquet, &buf, columns)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	file, err := readParquetFile(buf.Bytes())
+	if err != nil {
+		t.Fatalf("failed to read the file back: %v", err)
+	}
+	return file
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	at := time.Date(2024, 3, 9, 14, 30, 15, 250e6, time.UTC)
+	beforeEpoch := time.Date(1969, 12, 31, 23, 0, 0, 0, time.UTC)
+	path := "/profile"
+	var missing *string
+	rows := [][]interface{}{
+		{"/links/1", int64(3), at, at},
+		{nil, 42, nil, at.AddDate(0, 0, -400)},
+		{&path, uint(7), at.Add(-time.Hour), nil},
+		{missing, nil, beforeEpoch, beforeEpoch},
+		{"héllo, \"world\"", int64(-1), at, at},
+	}
+	file := writeParquet(t, testColumns, rows)
+
+	if file.numRows != int64(len(rows)) || file.rowGroups != 1 {
+		t.Fatalf("got %d rows in %d row groups, want %d in 1", file.numRows, file.rowGroups, len(rows))
+	}
+
+	// The root element followed by one optional leaf per column
+	if len(file.schema) != len(testColumns)+1 || file.schema[0][5] != int64(len(testColumns)) {
+		t.Fatalf("got schema %v, want the root and %d columns", file.schema, len(testColumns))
+	}
+	wantTypes := []struct {
+		physical  int64
+		converted interface{} // nil when the column has no converted type
+	}{
+		{6, int64(0)}, // BYTE_ARRAY, UTF8
+		{2, nil},      // INT64
+		{2, int64(9)}, // INT64, TIMESTAMP_MILLIS
+		{1, int64(6)}, // INT32, DATE
+	}
+	for i, column := range testColumns {
+		element := file.schema[i+1]
+		if element[4] != column.Name {
+			t.Errorf("column %d: got name %v, want %q", i, element[4], column.Name)
+		}
+		if element[1] != wantTypes[i].physical {
+			t.Errorf("column %s: got type %v, want %d", column.Name, element[1], wantTypes[i].physical)
+		}
+		if element[3] != int64(1) {
+			t.Errorf("column %s: got repetition %v, want OPTIONAL", column.Name, element[3])
+		}
+		if element[6] != wantTypes[i].converted {
+			t.Errorf("column %s: got converted type %v, want %v", column.Name, element[6], wantTypes[i].converted)
+		}
+	}
+
+	days := func(t time.Time) int32 { return int32(t.Unix() / 86400) }
+	want := [][]interface{}{
+		{"/links/1", nil, "/profile", nil, "héllo, \"world\""},
+		{int64(3), int64(42), int64(7), nil, int64(-1)},
+		{at.UnixMilli(), nil, at.Add(-time.Hour).UnixMilli(), int64(-3600000), at.UnixMilli()},
+		{days(at), days(at.AddDate(0, 0, -400)), nil, int32(-1), days(at)},
+	}
+	for i, column := range testColumns {
+		for row, value := range file.columns[i] {
+			if value != want[i][row] {
+				t.Errorf("column %s row %d: got %#v, want %#v", column.Name, row, value, want[i][row])
+			}
+		}
+	}
+}
+
+func TestParquetSplitsRowGroups(t *testing.T) {
+	columns := []Column{{Name: "n", Type: Int64}}
+	rows := make([][]interface{}, parquetRowGroupRows+10)
+	for i := range rows {
+		rows[i] = []interface{}{i}
+	}
+	file := writeParquet(t, columns, rows)
+
+	if file.rowGroups != 2 || file.numRows != int64(len(rows)) {
+		t.Fatalf("got %d rows in %d row groups, want %d in 2", file.numRows, file.rowGroups, len(rows))
+	}
+	if len(file.columns[0]) != len(rows) {
+		t.Fatalf("got %d values, want %d", len(file.columns[0]), len(rows))
+	}
+	for i, value := range file.columns[0] {
+		if value != int64(i) {
+			t.Fatalf("row %d: got %#v", i, value)
+		}
+	}
+}
+
+func TestParquetEmptyTable(t *testing.T) {
+	file := writeParquet(t, testColumns, nil)
+	if file.numRows != 0 || file.rowGroups != 0 {
+		t.Errorf("got %d rows in %d row groups, want none", file.numRows, file.rowGroups)
+	}
+	if len(file.schema) != len(testColumns)+1 {
+		t.Errorf("got %d schema elements, want %d", len(file.schema), len(testColumns)+1)
+	}
+}
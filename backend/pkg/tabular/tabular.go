@@ -0,0 +1,141 @@
+// Package tabular writes flat, typed rows as CSV, newline-delimited JSON or
+// Parquet, for data exports that end up in spreadsheets and notebooks. Rows
+// are written as they come, so exports of any size stream in constant memory
+// (Parquet holds one row group at a time).
+package tabular
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format is a table file format
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(name))) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatNDJSON, "jsonl", "json":
+		return FormatNDJSON, nil
+	case FormatParquet:
+		return FormatParquet, nil
+	}
+	return "", fmt.Errorf("unsupported format %q; use csv, ndjson or parquet", name)
+}
+
+// ContentType returns the MIME type written for a format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// Type is the type of a column's values
+type Type int
+
+const (
+	String    Type = iota // string or *string
+	Int64                 // int64, int or uint
+	Timestamp             // time.Time, written in UTC with millisecond precision
+	Date                  // time.Time, of which only the date is kept
+)
+
+// Column describes one column of a table
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Writer writes the rows of a table. Each row holds one value per column, in
+// column order; nil and nil pointers are written as null (an empty CSV field).
+type Writer interface {
+	Write(row []interface{}) error
+	// Close writes anything still buffered, and the footer for Parquet. It
+	// does not close the underlying io.Writer.
+	Close() error
+}
+
+// NewWriter starts writing a table in the given format to w
+func NewWriter(format Format, w io.Writer, columns []Column) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns), nil
+	case FormatParquet:
+		return newParquetWriter(w, columns), nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// normalize dereferences pointers and converts a value to the Go type used
+// for its column, or returns nil for null
+func normalize(column Column, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		value = *v
+	case *int64:
+		if v == nil {
+			return nil, nil
+		}
+		value = *v
+	case *time.Time:
+		if v == nil {
+			return nil, nil
+		}
+		value = *v
+	case *uint:
+		if v == nil {
+			return nil, nil
+		}
+		value = *v
+	}
+
+	switch column.Type {
+	case String:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case Int64:
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case int:
+			return int64(v), nil
+		case uint:
+			return int64(v), nil
+		}
+	case Timestamp, Date:
+		if t, ok := value.(time.Time); ok {
+			return t.UTC(), nil
+		}
+	}
+	return nil, fmt.Errorf("column %s: unexpected value of type %T", column.Name, value)
+}
+
+func checkRow(columns []Column, row []interface{}) error {
+	if len(row) != len(columns) {
+		return fmt.Errorf("row has %d values for %d columns", len(row), len(columns))
+	}
+	return nil
+}
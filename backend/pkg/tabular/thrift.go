@@ -0,0 +1,97 @@
+package tabular
+
+import "encoding/binary"
+
+// Thrift compact protocol type ids
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs in the Thrift compact protocol, which is what
+// Parquet page headers and file metadata are written in. Fields must be
+// written in increasing id order within a struct.
+type thriftWriter struct {
+	buf     []byte
+	lastID  int16
+	stack   []int16 // last field id of each enclosing struct
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (w *thriftWriter) varint(v uint64) {
+	n := binary.PutUvarint(w.scratch[:], v)
+	w.buf = append(w.buf, w.scratch[:n]...)
+}
+
+func (w *thriftWriter) zigzag(v int64) {
+	w.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	if delta := id - w.lastID; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.zigzag(int64(id))
+	}
+	w.lastID = id
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.zigzag(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.zigzag(v)
+}
+
+func (w *thriftWriter) binary(id int16, v string) {
+	w.field(id, thriftBinary)
+	w.varint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// list writes a list field header; its elements follow
+func (w *thriftWriter) list(id int16, elemType byte, size int) {
+	w.field(id, thriftList)
+	if size < 15 {
+		w.buf = append(w.buf, byte(size)<<4|elemType)
+	} else {
+		w.buf = append(w.buf, 0xf0|elemType)
+		w.varint(uint64(size))
+	}
+}
+
+// listI32 and listString write list elements
+func (w *thriftWriter) listI32(v int32) {
+	w.zigzag(int64(v))
+}
+
+func (w *thriftWriter) listString(v string) {
+	w.varint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// structField starts a struct valued field; end closes it
+func (w *thriftWriter) structField(id int16) {
+	w.field(id, thriftStruct)
+	w.begin()
+}
+
+// begin starts a struct, either a list element or the top level one
+func (w *thriftWriter) begin() {
+	w.stack = append(w.stack, w.lastID)
+	w.lastID = 0
+}
+
+// end writes the stop byte of the current struct
+func (w *thriftWriter) end() {
+	w.buf = append(w.buf, 0)
+	w.lastID = w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+}
@@ -0,0 +1,206 @@
+package unfurl
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxTitleLength       = 255
+	maxDescriptionLength = 1000
+)
+
+// pageMeta holds the raw values found in a page head
+type pageMeta struct {
+	htmlTitle    string
+	ogTitle      string
+	twitterTitle string
+	ogDesc       string
+	metaDesc     string
+	siteName     string
+	images       []string
+	icons        map[string]string // rel -> first href seen
+	base         *url.URL
+}
+
+// parseHead tokenizes the page until the body starts, collecting the title,
+// Open Graph and Twitter card tags, icon links and the base URL
+func parseHead(body []byte, pageURL *url.URL) *pageMeta {
+	meta := &pageMeta{icons: make(map[string]string), base: pageURL}
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	inTitle := false
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return meta
+		case html.TextToken:
+			if inTitle && meta.htmlTitle == "" {
+				meta.htmlTitle = string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				return meta
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			tag := atom.Lookup(name)
+			if tag == atom.Body {
+				return meta
+			}
+			if tag == atom.Title {
+				inTitle = true
+				continue
+			}
+			if !hasAttr {
+				continue
+			}
+			attrs := readAttrs(tokenizer)
+			switch tag {
+			case atom.Meta:
+				meta.addMeta(attrs)
+			case atom.Link:
+				meta.addLink(attrs)
+			case atom.Base:
+				if base, err := pageURL.Parse(attrs["href"]); err == nil && attrs["href"] != "" {
+					meta.base = base
+				}
+			}
+		}
+	}
+}
+
+// readAttrs returns the current tag's attributes with lower-cased names
+func readAttrs(tokenizer *html.Tokenizer) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		key, value, more := tokenizer.TagAttr()
+		attrs[strings.ToLower(string(key))] = string(value)
+		if !more {
+			return attrs
+		}
+	}
+}
+
+func (m *pageMeta) addMeta(attrs map[string]string) {
+	property := strings.ToLower(attrs["property"])
+	if property == "" {
+		property = strings.ToLower(attrs["name"])
+	}
+	content := strings.TrimSpace(attrs["content"])
+	if content == "" {
+		return
+	}
+
+	switch property {
+	case "og:title":
+		setOnce(&m.ogTitle, content)
+	case "twitter:title":
+		setOnce(&m.twitterTitle, content)
+	case "og:description":
+		setOnce(&m.ogDesc, content)
+	case "description":
+		setOnce(&m.metaDesc, content)
+	case "og:site_name":
+		setOnce(&m.siteName, clean(content, maxTitleLength))
+	case "og:image", "og:image:secure_url", "og:image:url", "twitter:image", "twitter:image:src":
+		m.images = append(m.images, content)
+	}
+}
+
+func (m *pageMeta) addLink(attrs map[string]string) {
+	href := strings.TrimSpace(attrs["href"])
+	if href == "" {
+		return
+	}
+	rel := strings.Join(strings.Fields(strings.ToLower(attrs["rel"])), " ")
+	switch rel {
+	case "apple-touch-icon", "apple-touch-icon-precomposed", "icon", "shortcut icon":
+		if _, seen := m.icons[rel]; !seen {
+			m.icons[rel] = href
+		}
+	}
+}
+
+func (m *pageMeta) title() string {
+	for _, title := range []string{m.ogTitle, m.twitterTitle, m.htmlTitle} {
+		if cleaned := clean(title, maxTitleLength); cleaned != "" {
+			return cleaned
+		}
+	}
+	return ""
+}
+
+func (m *pageMeta) description() string {
+	if m.ogDesc != "" {
+		return clean(m.ogDesc, maxDescriptionLength)
+	}
+	return clean(m.metaDesc, maxDescriptionLength)
+}
+
+// imageCandidates returns the absolute preview image URLs in page order
+func (m *pageMeta) imageCandidates() []string {
+	return m.resolveAll(m.images)
+}
+
+// iconCandidates prefers the larger touch icons and falls back to /favicon.ico
+func (m *pageMeta) iconCandidates(pageURL *url.URL) []string {
+	var hrefs []string
+	for _, rel := range []string{"apple-touch-icon", "apple-touch-icon-precomposed", "icon", "shortcut icon"} {
+		if href, ok := m.icons[rel]; ok {
+			hrefs = append(hrefs, href)
+		}
+	}
+	candidates := m.resolveAll(hrefs)
+
+	fallback := (&url.URL{Scheme: pageURL.Scheme, Host: pageURL.Host, Path: "/favicon.ico"}).String()
+	for _, candidate := range candidates {
+		if candidate == fallback {
+			return candidates
+		}
+	}
+	return append(candidates, fallback)
+}
+
+// resolveAll resolves hrefs against the base URL, dropping duplicates and
+// anything that is not http or https (data: URIs included)
+func (m *pageMeta) resolveAll(hrefs []string) []string {
+	seen := make(map[string]bool)
+	resolved := make([]string, 0, len(hrefs))
+	for _, href := range hrefs {
+		target, err := m.base.Parse(href)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+			continue
+		}
+		absolute := target.String()
+		if !seen[absolute] {
+			seen[absolute] = true
+			resolved = append(resolved, absolute)
+		}
+	}
+	return resolved
+}
+
+func setOnce(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// clean collapses whitespace, drops invalid UTF-8 and truncates to max runes
+func clean(value string, max int) string {
+	value = strings.Join(strings.Fields(strings.ToValidUTF8(value, "")), " ")
+	if utf8.RuneCountInString(value) <= max {
+		return value
+	}
+	return string([]rune(value)[:max])
+}
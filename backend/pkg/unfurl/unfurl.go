@@ -0,0 +1,199 @@
+package unfurl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gotchu-backend/pkg/redis"
+	"gotchu-backend/pkg/safehttp"
+	"gotchu-backend/pkg/storage"
+)
+
+const (
+	// maxPageBytes is how much of a page is read; metadata lives in the head
+	maxPageBytes  = 1 << 20
+	maxIconBytes  = 256 << 10
+	maxImageBytes = 5 << 20
+
+	cacheTTL = 24 * time.Hour
+	// failureCacheTTL keeps a dead or blocked URL from being fetched on every create
+	failureCacheTTL = 10 * time.Minute
+
+	// Bucket stores favicons and preview images
+	Bucket = "link-previews"
+)
+
+// ErrNotHTML is returned when the target is not an HTML page
+var ErrNotHTML = errors.New("target is not an HTML page")
+
+// Preview is the metadata extracted from a page. IconURL and ImageURL point
+// at copies in our storage, never at the original site.
+type Preview struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+	IconURL     string `json:"icon_url,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	FinalURL    string `json:"final_url,omitempty"`
+}
+
+// cachedPreview is the cache entry for a URL; failures are cached too
+type cachedPreview struct {
+	Preview *Preview `json:"preview,omitempty"`
+	Failed  bool     `json:"failed,omitempty"`
+}
+
+// Service fetches link previews and copies their assets into storage
+type Service struct {
+	client      *http.Client
+	storage     *storage.SupabaseStorage
+	redisClient *redis.Client
+}
+
+// NewService creates an unfurl service. A nil client uses an SSRF-safe one;
+// storage and redisClient may be nil, in which case assets are dropped and
+// results are not cached.
+func NewService(client *http.Client, storage *storage.SupabaseStorage, redisClient *redis.Client) *Service {
+	if client == nil {
+		client = safehttp.NewClient(8 * time.Second)
+	}
+	return &Service{
+		client:      client,
+		storage:     storage,
+		redisClient: redisClient,
+	}
+}
+
+// Preview returns the preview for rawURL, from cache when possible
+func (s *Service) Preview(ctx context.Context, rawURL string) (*Preview, error) {
+	key := cacheKey(rawURL)
+	if s.redisClient != nil {
+		var cached cachedPreview
+		if err := s.redisClient.Get(key, &cached); err == nil {
+			if cached.Failed {
+				return nil, fmt.Errorf("preview for %s failed recently", rawURL)
+			}
+			if cached.Preview != nil {
+				return cached.Preview, nil
+			}
+		}
+	}
+
+	preview, err := s.fetch(ctx, rawURL)
+	if s.redisClient != nil {
+		if err != nil {
+			s.redisClient.Set(key, cachedPreview{Failed: true}, failureCacheTTL)
+		} else {
+			s.redisClient.Set(key, cachedPreview{Preview: preview}, cacheTTL)
+		}
+	}
+	return preview, err
+}
+
+// fetch downloads and parses the page, then stores its icon and image
+func (s *Service) fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q", rawURL)
+	}
+
+	resp, err := safehttp.Get(ctx, s.client, rawURL, "text/html,application/xhtml+xml", maxPageBytes, true)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("page returned status %d", resp.StatusCode)
+	}
+	if !isHTML(resp.ContentType) {
+		return nil, ErrNotHTML
+	}
+
+	finalURL, err := url.Parse(resp.FinalURL)
+	if err != nil {
+		return nil, err
+	}
+	meta := parseHead(resp.Body, finalURL)
+
+	preview := &Preview{
+		Title:       meta.title(),
+		Description: meta.description(),
+		SiteName:    meta.siteName,
+		FinalURL:    resp.FinalURL,
+	}
+
+	if s.storage == nil {
+		return preview, nil
+	}
+
+	// Candidates are in order of preference; the first that downloads wins
+	for _, iconURL := range meta.iconCandidates(finalURL) {
+		if stored, err := s.storeAsset(ctx, iconURL, "favicons", maxIconBytes); err == nil {
+			preview.IconURL = stored
+			break
+		}
+	}
+	for _, imageURL := range meta.imageCandidates() {
+		stored, err := s.storeAsset(ctx, imageURL, "images", maxImageBytes)
+		if err == nil {
+			preview.ImageURL = stored
+			break
+		}
+		log.Printf("Failed to store preview image %s: %v", imageURL, err)
+	}
+
+	return preview, nil
+}
+
+// storeAsset downloads an image and uploads it under a content-addressed
+// name, so the same favicon shared by many links is stored once
+func (s *Service) storeAsset(ctx context.Context, assetURL, folder string, maxBytes int64) (string, error) {
+	resp, err := safehttp.Get(ctx, s.client, assetURL, "image/*", maxBytes, false)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("asset returned status %d", resp.StatusCode)
+	}
+
+	// Sniff rather than trust the header; SVG is rejected because it can carry script
+	contentType := http.DetectContentType(resp.Body)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return "", fmt.Errorf("unsupported asset type %s", contentType)
+	}
+
+	sum := sha256.Sum256(resp.Body)
+	name := fmt.Sprintf("%s/%s.%s", folder, hex.EncodeToString(sum[:]), ext)
+	return s.storage.UploadBytes(Bucket, name, resp.Body, contentType)
+}
+
+// imageExtensions maps the sniffed image types we accept to file extensions
+var imageExtensions = map[string]string{
+	"image/png":                "png",
+	"image/jpeg":               "jpg",
+	"image/gif":                "gif",
+	"image/webp":               "webp",
+	"image/bmp":                "bmp",
+	"image/x-icon":             "ico",
+	"image/vnd.microsoft.icon": "ico",
+}
+
+// isHTML reports whether a Content-Type header describes an HTML document
+func isHTML(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// cacheKey hashes the URL so long URLs make short keys
+func cacheKey(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return "unfurl:" + hex.EncodeToString(sum[:])
+}
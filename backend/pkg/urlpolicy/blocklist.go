@@ -0,0 +1,108 @@
+package urlpolicy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gotchu-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ParseBlocklist reads a blocklist file: one domain per line, optionally
+// followed by a reason, with # starting a comment. Hosts-file lines like
+// "0.0.0.0 evil.com" are accepted so public lists can be used as-is.
+func ParseBlocklist(r io.Reader) (map[string]string, error) {
+	domains := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		reason := ""
+		if hash := strings.IndexByte(text, '#'); hash >= 0 {
+			reason = strings.TrimSpace(text[hash+1:])
+			text = strings.TrimSpace(text[:hash])
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "0.0.0.0" || fields[0] == "127.0.0.1" {
+			fields = fields[1:]
+			if len(fields) == 0 {
+				continue
+			}
+		}
+
+		domain, err := NormalizeDomain(strings.TrimPrefix(fields[0], "*."))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid domain %q", line, fields[0])
+		}
+		if len(fields) > 1 && reason == "" {
+			reason = strings.Join(fields[1:], " ")
+		}
+		if len(reason) > 255 {
+			reason = reason[:255]
+		}
+		domains[domain] = reason
+	}
+	return domains, scanner.Err()
+}
+
+// Import adds the domains in a blocklist file to the database with the given
+// source. Domains already present, including ones an admin added, are left
+// untouched; it returns the number of new domains.
+func Import(db *gorm.DB, r io.Reader, source string) (int, error) {
+	domains, err := ParseBlocklist(r)
+	if err != nil {
+		return 0, err
+	}
+	if len(domains) == 0 {
+		return 0, nil
+	}
+
+	entries := make([]models.BlockedDomain, 0, len(domains))
+	for domain, reason := range domains {
+		entries = append(entries, models.BlockedDomain{
+			Domain: domain,
+			Reason: reason,
+			Source: source,
+		})
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&entries, 500)
+	return int(result.RowsAffected), result.Error
+}
+
+// ImportFile imports a blocklist file from disk
+func ImportFile(db *gorm.DB, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	added, err := Import(db, file, models.BlockedDomainSourceFile)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return added, nil
+}
+
+// Reload replaces the policy's blocklist with the domains in the database
+func (p *Policy) Reload(db *gorm.DB) error {
+	var entries []models.BlockedDomain
+	if err := db.Select("domain, reason").Find(&entries).Error; err != nil {
+		return fmt.Errorf("failed to load blocked domains: %w", err)
+	}
+
+	domains := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		domains[entry.Domain] = entry.Reason
+	}
+	p.SetBlocklist(domains)
+	return nil
+}
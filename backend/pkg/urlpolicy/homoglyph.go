@@ -0,0 +1,164 @@
+package urlpolicy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// domainProfile maps user input to the ASCII (punycode) form browsers resolve,
+// applying the same width and case folding
+var domainProfile = idna.New(idna.MapForLookup(), idna.Transitional(false), idna.StrictDomainName(false))
+
+// NormalizeDomain returns the lower-case ASCII form of a domain, so that
+// Unicode and punycode spellings of the same host compare equal
+func NormalizeDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.TrimSpace(domain), ".")
+	if domain == "" {
+		return "", errors.New("empty domain")
+	}
+	ascii, err := domainProfile.ToASCII(domain)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(ascii), nil
+}
+
+// confusables maps characters that render like ASCII letters or digits to
+// the character they imitate. It covers the Cyrillic, Greek and Latin
+// lookalikes seen in phishing domains rather than the full Unicode table.
+var confusables = map[rune]string{
+	// Cyrillic
+	'а': "a", 'в': "b", 'е': "e", 'ё': "e", 'к': "k", 'м': "m", 'н': "h", 'о': "o", 'р': "p",
+	'с': "c", 'т': "t", 'у': "y", 'х': "x", 'ѕ': "s", 'і': "i", 'ї': "i", 'ј': "j", 'һ': "h",
+	'ԁ': "d", 'ԛ': "q", 'ԝ': "w", 'ӏ': "l", 'ү': "y", 'ɡ': "g", 'ь': "b", 'п': "n",
+	// Greek
+	'α': "a", 'β': "b", 'ε': "e", 'η': "n", 'ι': "i", 'κ': "k", 'ν': "v", 'ο': "o", 'ρ': "p",
+	'τ': "t", 'υ': "u", 'χ': "x", 'ω': "w", 'ϲ': "c", 'ϳ': "j",
+	// Latin with diacritics and other Latin lookalikes
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ģ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ı': "i", 'ī': "i",
+	'ķ': "k", 'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'ŕ': "r", 'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ť': "t", 'ţ': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+	// Digits that pass for letters
+	'0': "o", '1': "l",
+}
+
+// multiCharConfusables are ASCII sequences that render like a single letter
+var multiCharConfusables = strings.NewReplacer("rn", "m", "vv", "w", "cl", "d")
+
+// skeleton reduces a Unicode domain to the ASCII string it looks like
+func skeleton(domain string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(domain) {
+		if replacement, ok := confusables[r]; ok {
+			b.WriteString(replacement)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return multiCharConfusables.Replace(b.String())
+}
+
+// lookalike reports why an ASCII host looks like a homograph attack, or ""
+func (p *Policy) lookalike(host string) string {
+	display, err := idna.ToUnicode(host)
+	if err != nil {
+		return "URL host has invalid punycode"
+	}
+
+	if display != host {
+		labels := strings.Split(display, ".")
+		tld := labels[len(labels)-1]
+		for _, label := range labels {
+			if mixesScripts(label) {
+				return fmt.Sprintf("Domain %s mixes characters from different alphabets", display)
+			}
+			if label != tld && isASCIIString(tld) && imitatesLatin(label) {
+				return fmt.Sprintf("Domain %s uses characters that imitate Latin letters", display)
+			}
+		}
+	}
+
+	// Compare what the host looks like against popular and blocked domains;
+	// an exact match is the real site and is judged by the blocklist alone
+	shape := skeleton(display)
+	for domain := shape; domain != ""; {
+		if real, ok := p.protected[domain]; ok && !hasDomainSuffix(host, real) {
+			return fmt.Sprintf("Domain %s imitates %s", display, real)
+		}
+		p.mu.RLock()
+		real, ok := p.blockedShapes[domain]
+		p.mu.RUnlock()
+		if ok && !hasDomainSuffix(host, real) {
+			return fmt.Sprintf("Domain %s imitates the blocked domain %s", display, real)
+		}
+
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			break
+		}
+		domain = domain[dot+1:]
+	}
+	return ""
+}
+
+// lookalikeScripts are the scripts whose letters are easily mistaken for Latin
+var lookalikeScripts = []*unicode.RangeTable{unicode.Latin, unicode.Cyrillic, unicode.Greek, unicode.Armenian, unicode.Cherokee}
+
+// mixesScripts reports whether a label combines letters from more than one
+// of the scripts that resemble each other, like Latin "paypal" with a Cyrillic "а"
+func mixesScripts(label string) bool {
+	var seen *unicode.RangeTable
+	for _, r := range label {
+		for _, script := range lookalikeScripts {
+			if !unicode.Is(script, r) {
+				continue
+			}
+			if seen != nil && seen != script {
+				return true
+			}
+			seen = script
+		}
+	}
+	return false
+}
+
+// imitatesLatin reports whether a non-ASCII label is written entirely in
+// characters that look like Latin letters, like Cyrillic "аррӏе"
+func imitatesLatin(label string) bool {
+	if isASCIIString(label) {
+		return false
+	}
+	for _, r := range label {
+		if r < unicode.MaxASCII {
+			continue
+		}
+		if _, ok := confusables[r]; !ok || unicode.Is(unicode.Latin, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCIIString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// hasDomainSuffix reports whether host is domain or one of its subdomains
+func hasDomainSuffix(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
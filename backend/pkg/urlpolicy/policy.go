@@ -0,0 +1,249 @@
+// Package urlpolicy decides whether a URL may be used as a link target. It
+// checks the scheme, looks for lookalike (homoglyph) domains, unwraps known
+// URL shorteners and matches every hop against a domain blocklist.
+package urlpolicy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gotchu-backend/pkg/safehttp"
+
+	"golang.org/x/net/idna"
+)
+
+// maxUnwrapHops bounds how many shortener redirects are followed
+const maxUnwrapHops = 5
+
+// Violation codes returned to clients
+const (
+	CodeScheme    = "BLOCKED_SCHEME"
+	CodeInvalid   = "INVALID_URL"
+	CodeHomoglyph = "LOOKALIKE_DOMAIN"
+	CodeBlocked   = "BLOCKED_DOMAIN"
+	CodeShortener = "UNRESOLVED_SHORTENER"
+)
+
+// DefaultSchemes are the schemes links may use
+var DefaultSchemes = []string{"http", "https", "mailto", "tel"}
+
+// DefaultShorteners are hosts whose links are unwrapped before checking
+var DefaultShorteners = []string{
+	"bit.ly", "bitly.com", "t.co", "tinyurl.com", "goo.gl", "ow.ly", "is.gd", "buff.ly",
+	"rebrand.ly", "cutt.ly", "shorturl.at", "tiny.cc", "rb.gy", "s.id", "lnkd.in", "t.ly",
+	"v.gd", "bl.ink", "short.io", "shorte.st", "adf.ly",
+}
+
+// DefaultProtectedDomains are popular domains that lookalike hosts imitate
+var DefaultProtectedDomains = []string{
+	"google.com", "youtube.com", "apple.com", "icloud.com", "microsoft.com", "paypal.com",
+	"amazon.com", "facebook.com", "instagram.com", "twitter.com", "x.com", "tiktok.com",
+	"discord.com", "discord.gg", "steamcommunity.com", "steampowered.com", "github.com",
+	"twitch.tv", "spotify.com", "netflix.com", "coinbase.com", "binance.com", "roblox.com",
+}
+
+// Violation explains why a URL was rejected
+type Violation struct {
+	Code   string
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return v.Reason
+}
+
+// Policy holds the scheme allowlist, shortener list and domain blocklist.
+// It is safe for concurrent use; the blocklist can be swapped at any time.
+type Policy struct {
+	client     *http.Client
+	schemes    map[string]bool
+	shorteners map[string]bool
+	protected  map[string]string // skeleton -> protected domain
+
+	mu            sync.RWMutex
+	blocklist     map[string]string // domain -> reason
+	blockedShapes map[string]string // skeleton -> blocked domain
+}
+
+// New creates a policy with the default lists. A nil client uses an SSRF-safe
+// one that does not follow redirects; pass one to reach a test server.
+func New(client *http.Client) *Policy {
+	if client == nil {
+		client = safehttp.NewClient(5 * time.Second)
+	}
+	// Shorteners are unwrapped hop by hop so each hop can be checked
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	p := &Policy{
+		client:     &noRedirects,
+		schemes:    make(map[string]bool),
+		shorteners: make(map[string]bool),
+		protected:  make(map[string]string),
+		blocklist:  make(map[string]string),
+
+		blockedShapes: make(map[string]string),
+	}
+	for _, scheme := range DefaultSchemes {
+		p.schemes[scheme] = true
+	}
+	for _, host := range DefaultShorteners {
+		p.shorteners[host] = true
+	}
+	for _, domain := range DefaultProtectedDomains {
+		p.protected[skeleton(domain)] = domain
+	}
+	return p
+}
+
+// SetBlocklist replaces the blocked domains; the map is domain -> reason
+func (p *Policy) SetBlocklist(domains map[string]string) {
+	blocklist := make(map[string]string, len(domains))
+	shapes := make(map[string]string, len(domains))
+	for domain, reason := range domains {
+		normalized, err := NormalizeDomain(domain)
+		if err != nil {
+			continue
+		}
+		blocklist[normalized] = reason
+		if display, err := idna.ToUnicode(normalized); err == nil {
+			shapes[skeleton(display)] = normalized
+		}
+	}
+
+	p.mu.Lock()
+	p.blocklist = blocklist
+	p.blockedShapes = shapes
+	p.mu.Unlock()
+}
+
+// Check validates rawURL, following shortener redirects over the network
+func (p *Policy) Check(ctx context.Context, rawURL string) error {
+	parsed, err := p.checkStatic(rawURL)
+	if err != nil || parsed.Host == "" {
+		return err
+	}
+
+	for hop := 0; p.isShortener(parsed.Hostname()); hop++ {
+		if hop == maxUnwrapHops {
+			return &Violation{Code: CodeShortener, Reason: "Shortened link redirects too many times"}
+		}
+		next, err := p.unwrap(ctx, parsed)
+		if err != nil {
+			return &Violation{Code: CodeShortener, Reason: "Could not resolve shortened link: " + err.Error()}
+		}
+		if next == nil {
+			break
+		}
+		if parsed, err = p.checkStatic(next.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckStatic validates rawURL without any network access
+func (p *Policy) CheckStatic(rawURL string) error {
+	_, err := p.checkStatic(rawURL)
+	return err
+}
+
+func (p *Policy) checkStatic(rawURL string) (*url.URL, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Scheme == "" {
+		return nil, &Violation{Code: CodeInvalid, Reason: "URL is not valid"}
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	if !p.schemes[scheme] {
+		return nil, &Violation{Code: CodeScheme, Reason: fmt.Sprintf("%s: links are not allowed", scheme)}
+	}
+	if scheme != "http" && scheme != "https" {
+		return parsed, nil
+	}
+	if parsed.Hostname() == "" {
+		return nil, &Violation{Code: CodeInvalid, Reason: "URL has no host"}
+	}
+	if parsed.User != nil {
+		// user@host URLs are a classic way to disguise the real host
+		return nil, &Violation{Code: CodeInvalid, Reason: "URLs with embedded credentials are not allowed"}
+	}
+
+	host, err := NormalizeDomain(parsed.Hostname())
+	if err != nil {
+		return nil, &Violation{Code: CodeInvalid, Reason: "URL host is not a valid domain"}
+	}
+	if reason, blocked := p.blocked(host); blocked {
+		display, err := idna.ToUnicode(host)
+		if err != nil {
+			display = host
+		}
+		return nil, &Violation{Code: CodeBlocked, Reason: blockedReason(display, reason)}
+	}
+	if reason := p.lookalike(host); reason != "" {
+		return nil, &Violation{Code: CodeHomoglyph, Reason: reason}
+	}
+	return parsed, nil
+}
+
+// blocked matches host and each of its parent domains against the blocklist
+func (p *Policy) blocked(host string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for domain := host; domain != ""; {
+		if reason, ok := p.blocklist[domain]; ok {
+			return reason, true
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			break
+		}
+		domain = domain[dot+1:]
+	}
+	return "", false
+}
+
+func (p *Policy) isShortener(host string) bool {
+	host, err := NormalizeDomain(host)
+	return err == nil && p.shorteners[host]
+}
+
+// unwrap requests a shortened URL and returns where it redirects, or nil
+// when it does not redirect
+func (p *Policy) unwrap(ctx context.Context, shortURL *url.URL) (*url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, shortURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "GotchuBot/1.0 (+https://gotchu.lol)")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return nil, nil
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return nil, fmt.Errorf("redirect without a location")
+	}
+	return location, nil
+}
+
+func blockedReason(host, reason string) string {
+	if reason == "" {
+		return fmt.Sprintf("Links to %s are not allowed", host)
+	}
+	return fmt.Sprintf("Links to %s are not allowed: %s", host, reason)
+}
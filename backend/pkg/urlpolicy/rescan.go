@@ -0,0 +1,188 @@
+package urlpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"gotchu-backend/internal/models"
+	"gotchu-backend/pkg/redis"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// rescanBatchSize is the number of links loaded per query during a rescan
+	rescanBatchSize = 500
+	// rescanURLTimeout bounds each check, including shortener unwrapping
+	rescanURLTimeout = 10 * time.Second
+
+	// ActivityLinkBlocked is the activity type recorded when a rescan disables a link
+	ActivityLinkBlocked = "link_blocked"
+)
+
+// RescanResult summarizes a rescan of existing links
+type RescanResult struct {
+	Scanned  int `json:"scanned"`
+	Disabled int `json:"disabled"`
+}
+
+// Rescanner re-checks existing links after the blocklist changes and
+// disables the ones that now violate the policy
+type Rescanner struct {
+	policy      *Policy
+	db          *gorm.DB
+	redisClient *redis.Client
+
+	running chan struct{} // holds a token while a rescan runs
+	pending chan struct{} // holds a token when another rescan was requested meanwhile
+}
+
+// NewRescanner creates a rescanner; redisClient may be nil
+func NewRescanner(policy *Policy, db *gorm.DB, redisClient *redis.Client) *Rescanner {
+	return &Rescanner{
+		policy:      policy,
+		db:          db,
+		redisClient: redisClient,
+		running:     make(chan struct{}, 1),
+		pending:     make(chan struct{}, 1),
+	}
+}
+
+// Run rescans all active links. If a rescan is already running, it is
+// asked to go around once more instead, so the latest blocklist always
+// gets a full pass without rescans piling up.
+func (r *Rescanner) Run() error {
+	select {
+	case r.running <- struct{}{}:
+	default:
+		select {
+		case r.pending <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+	defer func() { <-r.running }()
+
+	for {
+		result, err := r.rescan()
+		if err != nil {
+			return err
+		}
+		log.Printf("🛡️ URL policy rescan checked %d links and disabled %d", result.Scanned, result.Disabled)
+
+		select {
+		case <-r.pending:
+		default:
+			return nil
+		}
+	}
+}
+
+// rescan checks every active link in id order, one batch at a time
+func (r *Rescanner) rescan() (RescanResult, error) {
+	var result RescanResult
+	lastID := uint(0)
+	for {
+		var links []models.Link
+		err := r.db.Preload("Variants").
+			Where("id > ? AND is_active = ?", lastID, true).
+			Order("id ASC").
+			Limit(rescanBatchSize).
+			Find(&links).Error
+		if err != nil {
+			return result, fmt.Errorf("failed to load links for rescan: %w", err)
+		}
+		if len(links) == 0 {
+			return result, nil
+		}
+
+		for i := range links {
+			result.Scanned++
+			if violation := r.checkLink(&links[i]); violation != nil {
+				if err := r.disable(&links[i], violation); err != nil {
+					log.Printf("Failed to disable blocked link %d: %v", links[i].ID, err)
+					continue
+				}
+				result.Disabled++
+			}
+		}
+		lastID = links[len(links)-1].ID
+	}
+}
+
+// checkLink checks every URL the link can send visitors to
+func (r *Rescanner) checkLink(link *models.Link) *Violation {
+	for _, target := range LinkTargets(link) {
+		ctx, cancel := context.WithTimeout(context.Background(), rescanURLTimeout)
+		err := r.policy.Check(ctx, target)
+		cancel()
+
+		// Network failures unwrapping a shortener are not grounds for disabling
+		if violation, ok := err.(*Violation); ok && violation.Code != CodeShortener {
+			return violation
+		}
+	}
+	return nil
+}
+
+// disable deactivates a link, records why and tells its owner
+func (r *Rescanner) disable(link *models.Link, violation *Violation) error {
+	reason := violation.Reason
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Link{}).Where("id = ?", link.ID).Updates(map[string]interface{}{
+			"is_active":      false,
+			"blocked_reason": reason,
+			"updated_at":     time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"link_id": link.ID,
+			"code":    violation.Code,
+			"reason":  reason,
+		})
+		metadataStr := string(metadata)
+		return tx.Create(&models.Activity{
+			UserID:      link.UserID,
+			Type:        ActivityLinkBlocked,
+			Description: fmt.Sprintf("Your link \"%s\" was disabled: %s", link.Title, reason),
+			Metadata:    &metadataStr,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.redisClient != nil {
+		r.redisClient.InvalidateUserLinksCache(link.UserID)
+	}
+	return nil
+}
+
+// LinkTargets lists the URLs a link can redirect to: its own URL, its A/B
+// variants' URLs and its per-OS targeting URLs
+func LinkTargets(link *models.Link) []string {
+	var targets []string
+	if link.URL != nil && *link.URL != "" {
+		targets = append(targets, *link.URL)
+	}
+	for _, variant := range link.Variants {
+		if variant.URL != nil && *variant.URL != "" {
+			targets = append(targets, *variant.URL)
+		}
+	}
+	if link.Targeting != nil {
+		for _, target := range link.Targeting.OSURLs {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}